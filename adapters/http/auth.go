@@ -0,0 +1,27 @@
+package http
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+const AuthorizationHeader = "Authorization"
+
+// Restricts fn to requests bearing the admin token. Without a configured
+// token admin routes are unavailable, rather than open to anyone.
+func (h *HTTP) Admin(fn HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if h.adminToken == "" {
+			return ErrAdminDisabled
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get(AuthorizationHeader), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			return ErrUnauthorized
+		}
+
+		return fn(w, r)
+	}
+}
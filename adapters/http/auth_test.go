@@ -0,0 +1,57 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	http_adapter "github.com/mateusfdl/go-api/adapters/http"
+	"github.com/mateusfdl/go-api/adapters/logger"
+)
+
+func TestAdminRequiresToken(t *testing.T) {
+	h := http_adapter.New(logger.New(logger.Config{Level: "error"}), http_adapter.Config{AdminToken: "secret"})
+	handler := h.Handle(h.Admin(func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}))
+
+	cases := map[string]int{
+		"":              http.StatusUnauthorized,
+		"Bearer wrong":  http.StatusUnauthorized,
+		"secret":        http.StatusUnauthorized,
+		"Bearer secret": http.StatusNoContent,
+	}
+
+	for header, want := range cases {
+		r := httptest.NewRequest("POST", "/", nil)
+		if header != "" {
+			r.Header.Set(http_adapter.AuthorizationHeader, header)
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+
+		if w.Code != want {
+			t.Errorf("Authorization %q: expect status %d, but got %d", header, want, w.Code)
+		}
+	}
+}
+
+func TestAdminIsDisabledWithoutToken(t *testing.T) {
+	h := http_adapter.New(logger.New(logger.Config{Level: "error"}), http_adapter.Config{})
+	handler := h.Handle(h.Admin(func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}))
+
+	for _, header := range []string{"", "Bearer ", "Bearer anything"} {
+		r := httptest.NewRequest("POST", "/", nil)
+		r.Header.Set(http_adapter.AuthorizationHeader, header)
+		w := httptest.NewRecorder()
+		handler(w, r)
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("Authorization %q: expect status 503, but got %d", header, w.Code)
+		}
+	}
+}
@@ -3,4 +3,7 @@ package http
 type Config struct {
 	Port    int
 	Timeout int
+	// Required by admin routes as a bearer token. They are disabled when it is
+	// empty, answering ErrAdminDisabled.
+	AdminToken string
 }
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+)
+
+var (
+	ErrMalformedBody = errors.New("malformed request body")
+	ErrInvalidQuery  = errors.New("invalid query parameters")
+
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	ErrUnauthorized         = errors.New("missing or invalid admin token")
+	ErrAdminDisabled        = errors.New("admin routes are disabled, no admin token is configured")
+)
+
+const codeInternal = "INTERNAL_ERROR"
+
+// Implemented by errors carrying field-level problems, such as validation
+// errors, rendered in the errors member of the problem body
+type InvalidParams interface {
+	InvalidParams() interface{}
+}
+
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+// Registers the HTTP status and code a domain error is reported with. Errors
+// are matched with errors.Is in registration order, so wrapped errors are
+// resolved as well.
+func (h *HTTP) RegisterError(err error, status int, code string) {
+	h.errors = append(h.errors, errorMapping{err: err, status: status, code: code})
+}
+
+// Renders err as a problem body, with the status and code registered for it.
+// Unregistered errors are reported as internal errors without leaking details.
+// Client errors are detailed with their message, so errors wrapped in them
+// must not carry driver messages.
+func (h *HTTP) WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	status, code := http.StatusInternalServerError, codeInternal
+	for _, m := range h.errors {
+		if errors.Is(err, m.err) {
+			status, code = m.status, m.code
+			break
+		}
+	}
+
+	requestID := RequestID(r.Context())
+	p := Problem{
+		Status:    status,
+		Code:      code,
+		Detail:    err.Error(),
+		Instance:  r.URL.Path,
+		RequestID: requestID,
+	}
+
+	if status >= http.StatusInternalServerError {
+		h.l.Error("Request failed", "requestId", requestID, "error", err)
+		p.Detail = "internal server error"
+	}
+
+	var params InvalidParams
+	if errors.As(err, &params) {
+		p.Errors = params.InvalidParams()
+	}
+
+	if err := WriteProblem(w, p); err != nil {
+		h.l.Error("Failed to write response", err)
+	}
+}
@@ -0,0 +1,134 @@
+package http_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	http_adapter "github.com/mateusfdl/go-api/adapters/http"
+	"github.com/mateusfdl/go-api/adapters/logger"
+)
+
+var errThingNotFound = errors.New("thing not found")
+
+type fieldErrors []string
+
+func (e fieldErrors) Error() string              { return "invalid fields" }
+func (e fieldErrors) InvalidParams() interface{} { return []string(e) }
+
+func newServer(handler http_adapter.HandlerFunc) *http_adapter.HTTP {
+	h := http_adapter.New(logger.New(logger.Config{Level: "error"}), http_adapter.Config{Port: 0, Timeout: 1})
+	h.RegisterError(errThingNotFound, http.StatusNotFound, "THING_NOT_FOUND")
+	h.Router.HandleFunc("/things", h.Handle(handler))
+	return h
+}
+
+func perform(h *http_adapter.HTTP, header http.Header) (*httptest.ResponseRecorder, http_adapter.Problem) {
+	req := httptest.NewRequest("GET", "/things", nil)
+	for k, v := range header {
+		req.Header.Set(k, v[0])
+	}
+
+	w := httptest.NewRecorder()
+	h.Router.ServeHTTP(w, req)
+
+	var problem http_adapter.Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &problem)
+	return w, problem
+}
+
+func TestRegisteredErrorIsMapped(t *testing.T) {
+	h := newServer(func(w http.ResponseWriter, r *http.Request) error {
+		return fmt.Errorf("loading thing: %w", errThingNotFound)
+	})
+
+	w, problem := perform(h, nil)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expect status code 404, but got %d", w.Code)
+	}
+	if w.Header().Get("Content-Type") != http_adapter.ProblemContentType {
+		t.Errorf("Expect problem content type, but got %s", w.Header().Get("Content-Type"))
+	}
+	if problem.Code != "THING_NOT_FOUND" {
+		t.Errorf("Expect code THING_NOT_FOUND, but got %s", problem.Code)
+	}
+	if problem.RequestID == "" || problem.RequestID != w.Header().Get(http_adapter.RequestIDHeader) {
+		t.Errorf("Expect request id to match the response header, but got '%s'", problem.RequestID)
+	}
+}
+
+func TestUnregisteredErrorIsHidden(t *testing.T) {
+	h := newServer(func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("connection refused")
+	})
+
+	w, problem := perform(h, http.Header{http_adapter.RequestIDHeader: {"abc"}})
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expect status code 500, but got %d", w.Code)
+	}
+	if problem.Detail != "internal server error" {
+		t.Errorf("Expect internal details to be hidden, but got '%s'", problem.Detail)
+	}
+	if problem.RequestID != "abc" {
+		t.Errorf("Expect client request id to be kept, but got '%s'", problem.RequestID)
+	}
+}
+
+func TestInvalidParamsAreListed(t *testing.T) {
+	h := newServer(func(w http.ResponseWriter, r *http.Request) error {
+		return fmt.Errorf("%w: %w", http_adapter.ErrInvalidQuery, fieldErrors{"skip"})
+	})
+
+	w, problem := perform(h, nil)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expect status code 400, but got %d", w.Code)
+	}
+	if errs, ok := problem.Errors.([]interface{}); !ok || len(errs) != 1 {
+		t.Errorf("Expect one invalid param, but got %v", problem.Errors)
+	}
+}
+
+func TestMalformedRequestIDIsReplaced(t *testing.T) {
+	h := newServer(func(w http.ResponseWriter, r *http.Request) error {
+		return errThingNotFound
+	})
+
+	for _, id := range []string{"<script>", "a b", strings.Repeat("a", 65)} {
+		w, problem := perform(h, http.Header{http_adapter.RequestIDHeader: {id}})
+		got := w.Header().Get(http_adapter.RequestIDHeader)
+		if got == id || got == "" || problem.RequestID != got {
+			t.Errorf("Expect request id %q to be replaced, but got %q", id, got)
+		}
+	}
+}
+
+func TestLateErrorAbortsResponse(t *testing.T) {
+	h := newServer(func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "text/csv")
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, "id,name\n1,Farm 1\n")
+		w.(http.Flusher).Flush()
+		return errors.New("cursor died")
+	})
+	server := httptest.NewServer(h.Router)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/things")
+	if err != nil {
+		t.Fatalf("Expect the response to start, but got %v", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expect the body to be cut short, but got status %d, %q and %v", res.StatusCode, body, err)
+	}
+}
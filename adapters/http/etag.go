@@ -0,0 +1,79 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+const (
+	ETagHeader        = "ETag"
+	IfMatchHeader     = "If-Match"
+	IfNoneMatchHeader = "If-None-Match"
+)
+
+// Splits a list of entity tags as sent in If-Match and If-None-Match headers
+func ParseETags(header string) []string {
+	var tags []string
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// Checks the If-None-Match header against the current entity tag. Weak
+// comparison is used, as mandated for this header.
+func NotModified(r *http.Request, etag string) bool {
+	header := r.Header.Get(IfNoneMatchHeader)
+	if header == "" {
+		return false
+	}
+
+	for _, tag := range ParseETags(header) {
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Writes v as JSON tagged with the given entity tag, answering 304 instead
+// when the client already holds it
+func WriteJSONWithETag(w http.ResponseWriter, r *http.Request, status int, etag string, v interface{}) error {
+	w.Header().Set(ETagHeader, etag)
+	if NotModified(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	return WriteJSON(w, status, v)
+}
+
+// Same as WriteJSONWithETag, tagging the response with a hash of its body.
+// Meant for representations that have no version of their own, like listings.
+func WriteJSONWithHash(w http.ResponseWriter, r *http.Request, status int, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set(ETagHeader, etag)
+	if NotModified(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	w.WriteHeader(status)
+	_, err = w.Write(body)
+	return err
+}
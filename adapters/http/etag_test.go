@@ -0,0 +1,63 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	http_adapter "github.com/mateusfdl/go-api/adapters/http"
+)
+
+func TestNotModified(t *testing.T) {
+	cases := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{`"3"`, true},
+		{`W/"3"`, true},
+		{`"1", "3"`, true},
+		{`"1", "2"`, false},
+		{"*", true},
+	}
+
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", "/", nil)
+		if c.header != "" {
+			r.Header.Set(http_adapter.IfNoneMatchHeader, c.header)
+		}
+
+		if got := http_adapter.NotModified(r, `"3"`); got != c.want {
+			t.Errorf("NotModified(%q) = %v, want %v", c.header, got, c.want)
+		}
+	}
+}
+
+func TestWriteJSONWithHashAnswersNotModified(t *testing.T) {
+	body := []string{"a", "b"}
+
+	w := httptest.NewRecorder()
+	err := http_adapter.WriteJSONWithHash(w, httptest.NewRequest("GET", "/", nil), http.StatusOK, body)
+	if err != nil {
+		t.Fatalf("Expect no error, but got %v", err)
+	}
+
+	etag := w.Header().Get(http_adapter.ETagHeader)
+	if w.Code != http.StatusOK || etag == "" {
+		t.Fatalf("Expect 200 with an ETag, but got %d %q", w.Code, etag)
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set(http_adapter.IfNoneMatchHeader, etag)
+	w = httptest.NewRecorder()
+	if err := http_adapter.WriteJSONWithHash(w, r, http.StatusOK, body); err != nil {
+		t.Fatalf("Expect no error, but got %v", err)
+	}
+
+	if w.Code != http.StatusNotModified {
+		t.Errorf("Expect status code 304, but got %d", w.Code)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("Expect empty body, but got %q", w.Body.String())
+	}
+}
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Handler that reports failures by returning them instead of writing them
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// Adapts fn into a http.HandlerFunc, rendering the returned error through the
+// registered error mappings. Errors returned once the response was started
+// abort it, so that clients never take a truncated body for a whole one.
+func (h *HTTP) Handle(fn HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rw := &responseWriter{ResponseWriter: w}
+		err := fn(rw, r)
+		if err == nil {
+			return
+		}
+
+		// Too late to report the error to the client, who is left with a
+		// broken connection instead
+		if rw.wroteHeader {
+			h.l.Error("Failed to write response", "requestId", RequestID(r.Context()), "error", err)
+			panic(http.ErrAbortHandler)
+		}
+
+		h.WriteError(rw, r, err)
+	}
+}
+
+// Writes v as the JSON body of the response
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) error {
+	response, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(status)
+	_, err = w.Write(response)
+	return err
+}
+
+// Keeps track of whether the response has already been started
+type responseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		f.Flush()
+	}
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -16,11 +16,15 @@ type HTTP struct {
 	Router  *mux.Router
 	l       *logger.Logger
 	Server  *http.Server
+	errors  []errorMapping
+	params  map[string]ParamValidator
+	// Bearer token of admin routes
+	adminToken string
 }
 
 func New(l *logger.Logger, cfg Config) *HTTP {
 	router := mux.NewRouter()
-	return &HTTP{
+	h := &HTTP{
 		Port:    cfg.Port,
 		Timeout: cfg.Timeout,
 		Router:  router,
@@ -31,13 +35,23 @@ func New(l *logger.Logger, cfg Config) *HTTP {
 			WriteTimeout: time.Duration(cfg.Timeout) * time.Second,
 			IdleTimeout:  time.Duration(cfg.Timeout) * time.Second,
 		},
-		l: l,
+		l:          l,
+		params:     make(map[string]ParamValidator),
+		adminToken: cfg.AdminToken,
 	}
+
+	h.RegisterError(ErrMalformedBody, http.StatusBadRequest, "MALFORMED_BODY")
+	h.RegisterError(ErrInvalidQuery, http.StatusBadRequest, "INVALID_QUERY")
+	h.RegisterError(ErrUnsupportedMediaType, http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE")
+	h.RegisterError(ErrUnauthorized, http.StatusUnauthorized, "UNAUTHORIZED")
+	h.RegisterError(ErrAdminDisabled, http.StatusServiceUnavailable, "ADMIN_DISABLED")
+	router.Use(h.requestIDMiddleware, h.defaultMiddleware, h.paramsMiddleware)
+
+	return h
 }
 
 // Starts the HTTP server
 func (h *HTTP) Listen() {
-	h.Router.Use(h.defaultMiddleware)
 	h.l.Info("Starting server on port " + strconv.Itoa(h.Port))
 
 	if err := h.Server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -57,10 +71,12 @@ func (h *HTTP) GracefulShutdown(ctx context.Context) {
 // DefaultMiddleware logs all incoming requests
 func (h *HTTP) defaultMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		h.l.Info("Request received", "method", r.Method, "path", r.URL.Path, "query", r.URL.Query())
+		h.l.Info("Request received", "requestId", RequestID(r.Context()), "method", r.Method, "path", r.URL.Path, "query", r.URL.Query())
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE")
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, If-Match, If-None-Match")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag, X-Request-ID")
 
 		next.ServeHTTP(w, r)
 	})
@@ -0,0 +1,34 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Checks the value of a route variable, returning an error when it is invalid
+type ParamValidator func(name, value string) error
+
+// Validates the named route variable on every request before it reaches the
+// handlers, rendering the returned error instead of calling them
+func (h *HTTP) ValidateParam(name string, validate ParamValidator) {
+	h.params[name] = validate
+}
+
+func (h *HTTP) paramsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for name, value := range mux.Vars(r) {
+			validate, ok := h.params[name]
+			if !ok {
+				continue
+			}
+
+			if err := validate(name, value); err != nil {
+				h.WriteError(w, r, err)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,48 @@
+package http_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	http_adapter "github.com/mateusfdl/go-api/adapters/http"
+	"github.com/mateusfdl/go-api/adapters/logger"
+)
+
+var errInvalidID = errors.New("invalid id")
+
+func TestInvalidParamIsRejectedBeforeHandler(t *testing.T) {
+	h := http_adapter.New(logger.New(logger.Config{Level: "error"}), http_adapter.Config{Port: 0, Timeout: 1})
+	h.RegisterError(errInvalidID, http.StatusBadRequest, "INVALID_ID")
+	h.ValidateParam("id", func(name, value string) error {
+		if value != "42" {
+			return errInvalidID
+		}
+		return nil
+	})
+
+	called := false
+	h.Router.HandleFunc("/things/{id}", h.Handle(func(w http.ResponseWriter, r *http.Request) error {
+		called = true
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}))
+
+	w := httptest.NewRecorder()
+	h.Router.ServeHTTP(w, httptest.NewRequest("GET", "/things/abc", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expect status code 400, but got %d", w.Code)
+	}
+	if called {
+		t.Errorf("Expect handler not to be called")
+	}
+
+	w = httptest.NewRecorder()
+	h.Router.ServeHTTP(w, httptest.NewRequest("GET", "/things/42", nil))
+
+	if w.Code != http.StatusOK || !called {
+		t.Errorf("Expect handler to be called with a valid param, but got %d", w.Code)
+	}
+}
@@ -0,0 +1,37 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const ProblemContentType = "application/problem+json"
+
+// Error body following RFC 7807 (Problem Details for HTTP APIs)
+type Problem struct {
+	Type     string      `json:"type"`
+	Title    string      `json:"title"`
+	Status   int         `json:"status"`
+	Detail   string      `json:"detail,omitempty"`
+	Instance string      `json:"instance,omitempty"`
+	Errors   interface{} `json:"errors,omitempty"`
+
+	// Extension members
+	Code      string `json:"code,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// Writes the problem as the response body, defaulting type and title
+func WriteProblem(w http.ResponseWriter, p Problem) error {
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+	return json.NewEncoder(w).Encode(p)
+}
@@ -2,6 +2,7 @@ package mongo
 
 import (
 	"context"
+	"errors"
 
 	"github.com/mateusfdl/go-api/adapters/logger"
 	"go.mongodb.org/mongo-driver/bson"
@@ -52,20 +53,39 @@ func healthCheckConnection(ctx context.Context, c *Mongo, l *logger.Logger) erro
 		return err
 	}
 
-	err = syncIndexes(ctx, c, l)
-	if err != nil {
-		return err
-	}
-
 	l.Info("Mongo is alive")
 	return nil
 }
 
+// Server error codes returned when dropping an index that does not exist
+const (
+	namespaceNotFoundCode = 26
+	indexNotFoundCode     = 27
+)
+
+// Indexes replaced by newer ones. A collection holds a single text index, so
+// the one on the name alone has to go before the one on name and address is
+// created.
+var legacyIndexes = map[string][]string{
+	"farms": {"name_text"},
+}
+
 func syncIndexes(ctx context.Context, c *Mongo, l *logger.Logger) error {
 	l.Info("Syncing indexes")
+	if err := dropLegacyIndexes(ctx, c, l); err != nil {
+		return err
+	}
+
 	_, err := c.DB.Collection("farms").Indexes().CreateMany(ctx, []mongo.IndexModel{
-		{Keys: bson.D{{Key: "name", Value: "text"}}, Options: options.Index()},
+		{
+			Keys: bson.D{{Key: "name", Value: "text"}, {Key: "address", Value: "text"}},
+			Options: options.Index().
+				SetName("farms_text").
+				SetWeights(bson.D{{Key: "name", Value: 10}, {Key: "address", Value: 5}}),
+		},
 		{Keys: bson.D{{Key: "name", Value: 1}}, Options: options.Index()},
+		{Keys: bson.D{{Key: "location", Value: "2dsphere"}}, Options: options.Index()},
+		{Keys: bson.D{{Key: "boundary", Value: "2dsphere"}}, Options: options.Index()},
 	})
 	if err != nil {
 		l.Error("Failed to create farms index", err)
@@ -82,5 +102,35 @@ func syncIndexes(ctx context.Context, c *Mongo, l *logger.Logger) error {
 		return err
 	}
 
+	_, err = c.DB.Collection("yields").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "farmId", Value: 1}, {Key: "cropId", Value: 1}, {Key: "harvestDate", Value: -1}}, Options: options.Index()},
+	})
+	if err != nil {
+		l.Error("Failed to create yield index", err)
+		return err
+	}
+
+	return nil
+}
+
+func dropLegacyIndexes(ctx context.Context, c *Mongo, l *logger.Logger) error {
+	for collection, names := range legacyIndexes {
+		for _, name := range names {
+			_, err := c.DB.Collection(collection).Indexes().DropOne(ctx, name)
+			if err == nil {
+				l.Info("Dropped legacy index", "collection", collection, "index", name)
+				continue
+			}
+
+			var cmdErr mongo.CommandError
+			if errors.As(err, &cmdErr) && (cmdErr.Code == indexNotFoundCode || cmdErr.Code == namespaceNotFoundCode) {
+				continue
+			}
+
+			l.Error("Failed to drop legacy index", "collection", collection, "index", name, err)
+			return err
+		}
+	}
+
 	return nil
 }
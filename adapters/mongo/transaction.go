@@ -0,0 +1,52 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrTransactionsNotSupported = errors.New("transactions are not supported by the mongo deployment")
+
+// Server error code returned when a transaction is started against a standalone server
+const illegalOperationCode = 20
+
+type Transactor struct {
+	db *mongo.Database
+}
+
+func NewTransactor(db *mongo.Database) *Transactor {
+	return &Transactor{db: db}
+}
+
+// Runs fn inside a multi-document transaction. Every operation must use the
+// context handed to fn to take part in the transaction. Returns
+// ErrTransactionsNotSupported when the deployment is a standalone server, in
+// which case nothing has been committed.
+func (t *Transactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := t.db.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	if isTransactionNotSupported(err) {
+		return ErrTransactionsNotSupported
+	}
+
+	return err
+}
+
+func isTransactionNotSupported(err error) bool {
+	var cmdErr mongo.CommandError
+	if !errors.As(err, &cmdErr) {
+		return false
+	}
+
+	return cmdErr.Code == illegalOperationCode && strings.Contains(cmdErr.Message, "Transaction numbers")
+}
@@ -13,8 +13,10 @@ import (
 	"github.com/mateusfdl/go-api/adapters/mongo"
 	"github.com/mateusfdl/go-api/config"
 	"github.com/mateusfdl/go-api/internal/crops"
+	"github.com/mateusfdl/go-api/internal/croptypes"
 	"github.com/mateusfdl/go-api/internal/farms"
 	"github.com/mateusfdl/go-api/internal/health"
+	"github.com/mateusfdl/go-api/internal/yields"
 )
 
 func main() {
@@ -34,27 +36,41 @@ func main() {
 	s := server.New(l, c.HTTP)
 
 	healthModule := health.New(s, l)
-	cropsModule := crops.New(db.DB)
-	farmsModule := farms.New(l, &cropsModule.Repository, s, db.DB)
+	cropTypesModule := croptypes.New(l, s, db.DB, c.CropTypes)
+	cropsModule := crops.New(l, s, db.DB, cropTypesModule.Catalog, c.Crops)
+	farmsModule := farms.New(l, &cropsModule.Repository, cropTypesModule.Catalog, s, db.DB, c.Farms)
+	yieldsModule := yields.New(l, &cropsModule.Repository, cropsModule.Service, farmsModule.Service, s, db.DB)
 
 	// Bootstrapping
 	mongo.HookOnStart(ctx, db, l)
+	if err := cropTypesModule.Service.Bootstrap(ctx); err != nil {
+		panic(err)
+	}
 
 	server.RegisterRoutes(
 		healthModule.Controller,
 		farmsModule.Controller,
+		cropsModule.Controller,
+		cropTypesModule.Controller,
+		yieldsModule.Controller,
 	)
 
 	go s.Listen()
 
+	// Background jobs
+	jobsCtx, stopJobs := context.WithCancel(ctx)
+	go farmsModule.PurgeJob.Start(jobsCtx)
+	go cropTypesModule.Catalog.Start(jobsCtx)
+
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
-	shutdown(signalChan, ctx, s, db, l)
+	shutdown(signalChan, ctx, stopJobs, s, db, l)
 }
 
 func shutdown(
 	signalChan chan os.Signal,
 	ctx context.Context,
+	stopJobs context.CancelFunc,
 	s *server.HTTP,
 	db *mongo.Mongo,
 	l *logger.Logger,
@@ -64,6 +80,7 @@ func shutdown(
 	defer cancel()
 
 	l.Warn("Gracefully shutting down...")
+	stopJobs()
 	s.GracefulShutdown(shutdownCtx)
 	mongo.GracefulShutdown(shutdownCtx, db, l)
 	l.Info("Shutdown complete.")
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/mateusfdl/go-api/adapters/logger"
+	"github.com/mateusfdl/go-api/adapters/mongo"
+	"github.com/mateusfdl/go-api/config"
+	"github.com/mateusfdl/go-api/internal/migrations"
+)
+
+// Runs the given one-off migrations, in order, against the configured database
+func main() {
+	list := flag.Bool("list", false, "list the available migrations")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: migrate [-list] <migration>...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *list {
+		for _, m := range migrations.All() {
+			fmt.Printf("%-20s %s\n", m.Name, m.Description)
+		}
+		return
+	}
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Resolve every name before touching the database
+	var pending []migrations.Migration
+	for _, name := range flag.Args() {
+		m, err := migrations.Get(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+			os.Exit(2)
+		}
+
+		pending = append(pending, m)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		panic(err)
+	}
+
+	ctx := context.Background()
+	c, err := config.NewAppConfig()
+	if err != nil {
+		panic(err)
+	}
+
+	l := logger.New(c.Logger)
+	db := mongo.New(ctx, l, c.Mongo)
+	mongo.HookOnStart(ctx, db, l)
+
+	code := 0
+	for _, m := range pending {
+		l.Info("Running migration", "name", m.Name)
+		if err := m.Run(ctx, db.DB, l); err != nil {
+			l.Error("Migration failed", "name", m.Name, err)
+			code = 1
+			break
+		}
+	}
+
+	mongo.GracefulShutdown(ctx, db, l)
+	os.Exit(code)
+}
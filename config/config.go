@@ -9,13 +9,19 @@ import (
 	"github.com/mateusfdl/go-api/adapters/http"
 	"github.com/mateusfdl/go-api/adapters/logger"
 	"github.com/mateusfdl/go-api/adapters/mongo"
+	"github.com/mateusfdl/go-api/internal/crops"
+	"github.com/mateusfdl/go-api/internal/croptypes"
+	"github.com/mateusfdl/go-api/internal/farms"
 )
 
 type AppConfig struct {
-	Env    string
-	Logger logger.Config
-	HTTP   http.Config
-	Mongo  mongo.Config
+	Env       string
+	Logger    logger.Config
+	HTTP      http.Config
+	Mongo     mongo.Config
+	Farms     farms.Config
+	Crops     crops.Config
+	CropTypes croptypes.Config
 }
 
 func NewAppConfig() (AppConfig, error) {
@@ -36,12 +42,23 @@ func NewAppConfig() (AppConfig, error) {
 	if err != nil {
 		return AppConfig{}, err
 	}
+	farmsConfig, err := getFarmsConfig()
+	if err != nil {
+		return AppConfig{}, err
+	}
+	cropTypesConfig, err := getCropTypesConfig()
+	if err != nil {
+		return AppConfig{}, err
+	}
 
 	return AppConfig{
-		Env:    env,
-		Logger: loggerConfig,
-		HTTP:   httpConfig,
-		Mongo:  mongoConfig,
+		Env:       env,
+		Logger:    loggerConfig,
+		HTTP:      httpConfig,
+		Mongo:     mongoConfig,
+		Farms:     farmsConfig,
+		Crops:     crops.Config{AreaPrecision: farmsConfig.LandAreaPrecision},
+		CropTypes: cropTypesConfig,
 	}, nil
 }
 
@@ -74,8 +91,9 @@ func getHttpConfig() (http.Config, error) {
 	}
 
 	return http.Config{
-		Port:    port,
-		Timeout: timeout,
+		Port:       port,
+		Timeout:    timeout,
+		AdminToken: os.Getenv("ADMIN_TOKEN"),
 	}, nil
 }
 
@@ -99,6 +117,65 @@ func getMongoConfig() (mongo.Config, error) {
 	}, nil
 }
 
+func getFarmsConfig() (farms.Config, error) {
+	retention, err := getEnvAsInt("TRASH_RETENTION_DAYS", 30)
+	if err != nil {
+		return farms.Config{}, err
+	}
+	if retention <= 0 {
+		return farms.Config{}, errors.New("environment variable TRASH_RETENTION_DAYS must be positive")
+	}
+
+	interval, err := getEnvAsInt("TRASH_PURGE_INTERVAL", 60)
+	if err != nil {
+		return farms.Config{}, err
+	}
+	if interval <= 0 {
+		return farms.Config{}, errors.New("environment variable TRASH_PURGE_INTERVAL must be positive")
+	}
+
+	requireIfMatch, err := getEnvAsBool("FARMS_REQUIRE_IF_MATCH", false)
+	if err != nil {
+		return farms.Config{}, err
+	}
+
+	precision, err := getEnvAsInt("FARMS_LAND_AREA_PRECISION", 2)
+	if err != nil {
+		return farms.Config{}, err
+	}
+	if precision < 0 || precision > 6 {
+		return farms.Config{}, errors.New("environment variable FARMS_LAND_AREA_PRECISION must be between 0 and 6")
+	}
+
+	tolerance, err := getEnvAsInt("FARMS_BOUNDARY_TOLERANCE", 10)
+	if err != nil {
+		return farms.Config{}, err
+	}
+	if tolerance < 0 {
+		return farms.Config{}, errors.New("environment variable FARMS_BOUNDARY_TOLERANCE must not be negative")
+	}
+
+	return farms.Config{
+		TrashRetentionDays:   retention,
+		PurgeIntervalMinutes: interval,
+		RequireIfMatch:       requireIfMatch,
+		LandAreaPrecision:    precision,
+		BoundaryTolerance:    tolerance,
+	}, nil
+}
+
+func getCropTypesConfig() (croptypes.Config, error) {
+	interval, err := getEnvAsInt("CROP_TYPES_REFRESH_INTERVAL", 60)
+	if err != nil {
+		return croptypes.Config{}, err
+	}
+	if interval <= 0 {
+		return croptypes.Config{}, errors.New("environment variable CROP_TYPES_REFRESH_INTERVAL must be positive")
+	}
+
+	return croptypes.Config{RefreshIntervalSeconds: interval}, nil
+}
+
 func getAndValidateEnv(envName string, expected []string) (string, error) {
 	value := os.Getenv(envName)
 	if value == "" {
@@ -15,6 +15,8 @@ func TestNewAppConfig(t *testing.T) {
 	os.Setenv("HTTP_PORT", "8080")
 	os.Setenv("HTTP_TIMEOUT", "10")
 	os.Setenv("MONGO_DB_NAME", "farms")
+	os.Setenv("TRASH_RETENTION_DAYS", "15")
+	os.Setenv("TRASH_PURGE_INTERVAL", "5")
 
 	c, err := config.NewAppConfig()
 
@@ -49,6 +51,22 @@ func TestNewAppConfig(t *testing.T) {
 	if c.HTTP.Timeout != 10 {
 		t.Errorf("Expect http timeout to be 10, but got '%d'", c.HTTP.Timeout)
 	}
+
+	if c.Farms.TrashRetentionDays != 15 {
+		t.Errorf("Expect trash retention to be 15, but got '%d'", c.Farms.TrashRetentionDays)
+	}
+
+	if c.Farms.PurgeIntervalMinutes != 5 {
+		t.Errorf("Expect purge interval to be 5, but got '%d'", c.Farms.PurgeIntervalMinutes)
+	}
+
+	if c.Farms.LandAreaPrecision != 2 {
+		t.Errorf("Expect land area precision to default to 2, but got '%d'", c.Farms.LandAreaPrecision)
+	}
+
+	if c.Farms.BoundaryTolerance != 10 {
+		t.Errorf("Expect boundary tolerance to default to 10, but got '%d'", c.Farms.BoundaryTolerance)
+	}
 }
 
 func TestEnvNotSet(t *testing.T) {
@@ -148,3 +166,19 @@ func TestInvalidMongoDBName(t *testing.T) {
 		t.Fatalf("Expect mongo db name error, but got nil")
 	}
 }
+
+func TestInvalidTrashRetention(t *testing.T) {
+	os.Setenv("ENV", "test")
+	os.Setenv("LOG_LEVEL", "debug")
+	os.Setenv("LOG_SUGARED", "true")
+	os.Setenv("HTTP_PORT", "8080")
+	os.Setenv("HTTP_TIMEOUT", "10")
+	os.Setenv("MONGO_URI", "mongodb://localhost:27017")
+	os.Setenv("MONGO_DB_NAME", "farms")
+	os.Setenv("TRASH_RETENTION_DAYS", "0")
+
+	_, err := config.NewAppConfig()
+	if err == nil {
+		t.Fatalf("Expect invalid trash retention error, but got nil")
+	}
+}
@@ -0,0 +1,170 @@
+package address
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// An address broken into its parts, every one of them optional
+type Address struct {
+	Street     string `json:"street,omitempty" bson:"street,omitempty"`
+	Number     string `json:"number,omitempty" bson:"number,omitempty"`
+	District   string `json:"district,omitempty" bson:"district,omitempty"`
+	City       string `json:"city,omitempty" bson:"city,omitempty"`
+	State      string `json:"state,omitempty" bson:"state,omitempty"`
+	Country    string `json:"country,omitempty" bson:"country,omitempty"`
+	PostalCode string `json:"postalCode,omitempty" bson:"postalCode,omitempty"`
+}
+
+func (a Address) IsZero() bool {
+	return a == Address{}
+}
+
+// Writes the address on a single line, the way it is usually given, e.g.
+// "Rua 1, 123, Bairro 2, Porto Alegre - RS, 90000-000, Brasil"
+func (a Address) Format() string {
+	var parts []string
+	add := func(s string) {
+		if s = strings.TrimSpace(s); s != "" {
+			parts = append(parts, s)
+		}
+	}
+
+	add(a.Street)
+	add(a.Number)
+	add(a.District)
+	if a.City != "" && a.State != "" {
+		add(a.City + " - " + a.State)
+	} else {
+		add(a.City)
+		add(a.State)
+	}
+	add(a.PostalCode)
+	add(a.Country)
+
+	return strings.Join(parts, ", ")
+}
+
+var (
+	// Brazilian CEP, with or without its hyphen
+	postalCodePattern = regexp.MustCompile(`\b\d{5}-?\d{3}\b`)
+	// "Porto Alegre - RS" or "Porto Alegre/RS"
+	cityStatePattern = regexp.MustCompile(`^(.+?)\s*[-/]\s*([A-Za-z]{2})$`)
+	numberPattern    = regexp.MustCompile(`^(\d+[A-Za-z]?|[Ss]/?[Nn]|[Kk]m\s*\d+)$`)
+)
+
+// Breaks a single line address into its parts, in the order of Format. The
+// city and state are told apart by the "City - ST" part, parts before it
+// being the street, number and district and the one after it the country.
+// Reports whether the city was found, other parts being a guess without it.
+func Parse(line string) (Address, bool) {
+	var a Address
+	if cep := postalCodePattern.FindString(line); cep != "" {
+		a.PostalCode = cep
+		line = strings.Replace(line, cep, "", 1)
+	}
+
+	var parts []string
+	for _, p := range strings.Split(line, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+
+	city := -1
+	for i, p := range parts {
+		if m := cityStatePattern.FindStringSubmatch(p); m != nil {
+			a.City, a.State = m[1], strings.ToUpper(m[2])
+			city = i
+			break
+		}
+	}
+
+	street := parts
+	if city >= 0 {
+		street = parts[:city]
+		if rest := parts[city+1:]; len(rest) > 0 {
+			a.Country = strings.Join(rest, ", ")
+		}
+	}
+
+	if len(street) > 0 {
+		a.Street = street[0]
+		street = street[1:]
+	}
+	if len(street) > 0 && numberPattern.MatchString(street[0]) {
+		a.Number = street[0]
+		street = street[1:]
+	}
+	if city >= 0 && len(street) > 0 {
+		a.District = strings.Join(street, ", ")
+	}
+
+	return a, city >= 0
+}
+
+// An address as given by clients: either a single line or its parts
+type Input struct {
+	Line  string
+	Parts *Address
+}
+
+func FromLine(line string) Input {
+	return Input{Line: line}
+}
+
+func FromParts(parts Address) Input {
+	return Input{Parts: &parts}
+}
+
+func (in Input) IsStructured() bool {
+	return in.Parts != nil
+}
+
+// The line and the parts of the address, whichever way it was given. Parts
+// are parsed out of lines, and only kept when the city was found.
+func (in Input) Resolve() (string, *Address) {
+	if in.Parts != nil {
+		return in.Parts.Format(), in.Parts
+	}
+
+	if parts, ok := Parse(in.Line); ok {
+		return in.Line, &parts
+	}
+
+	return in.Line, nil
+}
+
+func (in Input) MarshalJSON() ([]byte, error) {
+	if in.Parts != nil {
+		return json.Marshal(in.Parts)
+	}
+
+	return json.Marshal(in.Line)
+}
+
+func (in *Input) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, []byte("null")) {
+		*in = Input{}
+		return nil
+	}
+
+	if len(b) > 0 && b[0] == '{' {
+		var parts Address
+		if err := json.Unmarshal(b, &parts); err != nil {
+			return err
+		}
+		*in = FromParts(parts)
+		return nil
+	}
+
+	var line string
+	if err := json.Unmarshal(b, &line); err != nil {
+		return err
+	}
+	*in = FromLine(line)
+	return nil
+}
@@ -0,0 +1,88 @@
+package address_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mateusfdl/go-api/internal/address"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		line string
+		want address.Address
+		ok   bool
+	}{
+		{
+			"Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil",
+			address.Address{Street: "Rua 1", Number: "123", District: "Bairro 2", City: "Porto Alegre", State: "RS", Country: "Brasil"},
+			true,
+		},
+		{
+			"Estrada Velha, s/n, Zona Rural, Chapecó/sc, 89800-000",
+			address.Address{Street: "Estrada Velha", Number: "s/n", District: "Zona Rural", City: "Chapecó", State: "SC", PostalCode: "89800-000"},
+			true,
+		},
+		{
+			"Fazenda Boa Vista, Sorriso - MT",
+			address.Address{Street: "Fazenda Boa Vista", City: "Sorriso", State: "MT"},
+			true,
+		},
+		{
+			"Rua 1, 123",
+			address.Address{Street: "Rua 1", Number: "123"},
+			false,
+		},
+		{"", address.Address{}, false},
+	}
+
+	for _, c := range cases {
+		got, ok := address.Parse(c.line)
+		if got != c.want || ok != c.ok {
+			t.Errorf("Parse(%q) = %+v, %v, want %+v, %v", c.line, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestFormatRoundTrips(t *testing.T) {
+	a := address.Address{Street: "Rua 1", Number: "123", District: "Bairro 2", City: "Porto Alegre", State: "RS", PostalCode: "90000-000", Country: "Brasil"}
+
+	line := a.Format()
+	if line != "Rua 1, 123, Bairro 2, Porto Alegre - RS, 90000-000, Brasil" {
+		t.Errorf("Unexpected line %q", line)
+	}
+	if parsed, ok := address.Parse(line); !ok || parsed != a {
+		t.Errorf("Parse(Format()) = %+v, want %+v", parsed, a)
+	}
+}
+
+func TestInputAcceptsLinesAndParts(t *testing.T) {
+	var dto struct {
+		Address address.Input `json:"address"`
+	}
+
+	if err := json.Unmarshal([]byte(`{"address": "Fazenda Boa Vista, Sorriso - MT"}`), &dto); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	line, parts := dto.Address.Resolve()
+	if dto.Address.IsStructured() || line != "Fazenda Boa Vista, Sorriso - MT" || parts == nil || parts.City != "Sorriso" {
+		t.Errorf("Unexpected resolution %q, %+v", line, parts)
+	}
+
+	if err := json.Unmarshal([]byte(`{"address": {"street": "Rua 1", "city": "Sorriso", "state": "MT"}}`), &dto); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	line, parts = dto.Address.Resolve()
+	if !dto.Address.IsStructured() || line != "Rua 1, Sorriso - MT" || parts.Street != "Rua 1" {
+		t.Errorf("Unexpected resolution %q, %+v", line, parts)
+	}
+
+	b, err := json.Marshal(dto.Address)
+	if err != nil || string(b) != `{"street":"Rua 1","city":"Sorriso","state":"MT"}` {
+		t.Errorf("Marshal = %s, %v", b, err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"address": 12}`), &dto); err == nil {
+		t.Errorf("Expect numbers to be rejected")
+	}
+}
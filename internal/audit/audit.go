@@ -0,0 +1,49 @@
+package audit
+
+import "time"
+
+const (
+	CreatedAtField = "createdAt"
+	UpdatedAtField = "updatedAt"
+)
+
+// Source of the current time, replaceable in tests
+type Clock interface {
+	Now() time.Time
+}
+
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+var SystemClock Clock = ClockFunc(time.Now)
+
+// Stamps creation and modification times on the documents written by the
+// repositories
+type Timestamps struct {
+	clock Clock
+}
+
+func NewTimestamps(clock Clock) *Timestamps {
+	return &Timestamps{clock: clock}
+}
+
+// Current time in UTC, truncated to the millisecond precision Mongo stores so
+// that stamped values compare equal once read back
+func (t *Timestamps) Now() time.Time {
+	return t.clock.Now().UTC().Truncate(time.Millisecond)
+}
+
+// Stamps both times on a document about to be inserted
+func (t *Timestamps) OnCreate(doc map[string]interface{}) {
+	now := t.Now()
+	doc[CreatedAtField] = now
+	doc[UpdatedAtField] = now
+}
+
+// Stamps the modification time on the fields of a $set
+func (t *Timestamps) OnUpdate(set map[string]interface{}) {
+	set[UpdatedAtField] = t.Now()
+}
@@ -0,0 +1,43 @@
+package audit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mateusfdl/go-api/internal/audit"
+)
+
+func fixedClock(t time.Time) audit.Clock {
+	return audit.ClockFunc(func() time.Time { return t })
+}
+
+func TestOnCreateStampsBothTimes(t *testing.T) {
+	now := time.Date(2024, 11, 22, 10, 30, 0, 123456789, time.FixedZone("BRT", -3*60*60))
+	ts := audit.NewTimestamps(fixedClock(now))
+
+	doc := map[string]interface{}{"name": "Farm 1"}
+	ts.OnCreate(doc)
+
+	want := time.Date(2024, 11, 22, 13, 30, 0, 123000000, time.UTC)
+	if doc["createdAt"] != want {
+		t.Errorf("Expect createdAt %v, but got %v", want, doc["createdAt"])
+	}
+	if doc["updatedAt"] != want {
+		t.Errorf("Expect updatedAt %v, but got %v", want, doc["updatedAt"])
+	}
+}
+
+func TestOnUpdateOnlyStampsModification(t *testing.T) {
+	now := time.Date(2024, 11, 22, 10, 30, 0, 0, time.UTC)
+	ts := audit.NewTimestamps(fixedClock(now))
+
+	set := map[string]interface{}{"name": "Farm 1"}
+	ts.OnUpdate(set)
+
+	if set["updatedAt"] != now {
+		t.Errorf("Expect updatedAt %v, but got %v", now, set["updatedAt"])
+	}
+	if _, ok := set["createdAt"]; ok {
+		t.Errorf("Expect createdAt not to be set")
+	}
+}
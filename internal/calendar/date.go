@@ -0,0 +1,102 @@
+package calendar
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+var ErrInvalidDate = errors.New("invalid date")
+
+// A day of the calendar, without time of day nor time zone. It is written in
+// JSON as 2006-01-02 and stored as midnight UTC.
+type Date struct {
+	t time.Time
+}
+
+func New(year int, month time.Month, day int) Date {
+	return Date{t: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// Day of the given time, in its own location
+func Of(t time.Time) Date {
+	return New(t.Date())
+}
+
+// Parses a date, or the day of an RFC 3339 date-time as written
+func Parse(s string) (Date, error) {
+	for _, layout := range []string{time.DateOnly, time.RFC3339} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return Of(t), nil
+		}
+	}
+
+	return Date{}, fmt.Errorf("%w: %q", ErrInvalidDate, s)
+}
+
+func (d Date) IsZero() bool {
+	return d.t.IsZero()
+}
+
+func (d Date) Before(other Date) bool {
+	return d.t.Before(other.t)
+}
+
+func (d Date) After(other Date) bool {
+	return d.t.After(other.t)
+}
+
+// Midnight UTC of the day
+func (d Date) Time() time.Time {
+	return d.t
+}
+
+func (d Date) String() string {
+	return d.t.Format(time.DateOnly)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidDate, b)
+	}
+
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+	return nil
+}
+
+func (d Date) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bson.MarshalValue(d.t)
+}
+
+func (d *Date) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	raw := bson.RawValue{Type: t, Value: data}
+	switch t {
+	case bson.TypeDateTime:
+		*d = Of(raw.Time().UTC())
+	case bson.TypeNull, bson.TypeUndefined:
+		*d = Date{}
+	default:
+		return fmt.Errorf("%w: cannot decode %s", ErrInvalidDate, t)
+	}
+
+	return nil
+}
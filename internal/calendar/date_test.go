@@ -0,0 +1,86 @@
+package calendar_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mateusfdl/go-api/internal/calendar"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParse(t *testing.T) {
+	cases := map[string]string{
+		"2025-10-01":                "2025-10-01",
+		"2025-10-01T23:30:00-03:00": "2025-10-01",
+		"2025-10-01T01:00:00Z":      "2025-10-01",
+	}
+
+	for input, want := range cases {
+		d, err := calendar.Parse(input)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", input, err)
+		}
+		if d.String() != want {
+			t.Errorf("Parse(%q) = %s, want %s", input, d, want)
+		}
+	}
+
+	for _, input := range []string{"", "01/10/2025", "2025-13-01"} {
+		if _, err := calendar.Parse(input); !errors.Is(err, calendar.ErrInvalidDate) {
+			t.Errorf("Parse(%q): expect ErrInvalidDate, but got %v", input, err)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var v struct {
+		Date *calendar.Date `json:"date"`
+	}
+	if err := json.Unmarshal([]byte(`{"date":"2025-10-01"}`), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(b) != `{"date":"2025-10-01"}` {
+		t.Errorf("Expect date written back as is, but got %s", b)
+	}
+
+	if err := json.Unmarshal([]byte(`{"date":20251001}`), &v); !errors.Is(err, calendar.ErrInvalidDate) {
+		t.Errorf("Expect ErrInvalidDate, but got %v", err)
+	}
+}
+
+func TestBSONRoundTrip(t *testing.T) {
+	in := struct {
+		Date calendar.Date `bson:"date"`
+	}{Date: calendar.New(2026, time.March, 15)}
+
+	b, err := bson.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var raw bson.M
+	if err := bson.Unmarshal(b, &raw); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if _, ok := raw["date"].(primitive.DateTime); !ok {
+		t.Errorf("Expect date stored as a BSON date, but got %T", raw["date"])
+	}
+
+	var out struct {
+		Date calendar.Date `bson:"date"`
+	}
+	if err := bson.Unmarshal(b, &out); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if out.Date != in.Date {
+		t.Errorf("Expect %s, but got %s", in.Date, out.Date)
+	}
+}
@@ -0,0 +1,7 @@
+package crops
+
+type Config struct {
+	// Decimal places planted areas can be given with, the same as the land
+	// areas of farms
+	AreaPrecision int
+}
@@ -0,0 +1,125 @@
+package crops
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	http_adapter "github.com/mateusfdl/go-api/adapters/http"
+	"github.com/mateusfdl/go-api/adapters/logger"
+	"github.com/mateusfdl/go-api/internal/ids"
+)
+
+type Controller struct {
+	cropService *Service
+	l           *logger.Logger
+	h           *http_adapter.HTTP
+}
+
+func NewController(h *http_adapter.HTTP, cropService *Service, logger *logger.Logger) *Controller {
+	return &Controller{cropService: cropService, l: logger, h: h}
+}
+
+// Register all Crop routes
+func (c *Controller) RegisterRoutes() {
+	c.l.Info("Registering crop routes")
+	c.registerErrors()
+	c.h.ValidateParam("id", ids.ValidateParam)
+	c.h.ValidateParam("cropId", ids.ValidateParam)
+	c.h.Router.HandleFunc("/farms/{id}/crops", c.h.Handle(c.CreateCrop)).Methods("POST").Name("CreateCrop")
+	c.h.Router.HandleFunc("/farms/{id}/crops", c.h.Handle(c.ListCrops)).Methods("GET").Name("ListCrops")
+	c.h.Router.HandleFunc("/farms/{id}/crops/{cropId}", c.h.Handle(c.GetCropByID)).Methods("GET").Name("GetCropByID")
+	c.h.Router.HandleFunc("/farms/{id}/crops/{cropId}", c.h.Handle(c.UpdateCrop)).Methods("PUT").Name("UpdateCrop")
+	c.h.Router.HandleFunc("/farms/{id}/crops/{cropId}", c.h.Handle(c.DeleteCrop)).Methods("DELETE").Name("DeleteCrop")
+	c.h.Router.HandleFunc("/farms/{id}/crops/{cropId}/transitions", c.h.Handle(c.TransitionCrop)).Methods("POST").Name("TransitionCrop")
+}
+
+// Register the HTTP status of every Crop error. Malformed ids are reported
+// through the mapping of ids.ErrInvalidID the farms controller registers.
+func (c *Controller) registerErrors() {
+	c.h.RegisterError(ErrCropNotFound, http.StatusNotFound, "CROP_NOT_FOUND")
+	c.h.RegisterError(ErrFarmNotFound, http.StatusNotFound, "FARM_NOT_FOUND")
+	c.h.RegisterError(ErrInvalidCropFields, http.StatusBadRequest, "INVALID_CROP_FIELDS")
+	c.h.RegisterError(ErrPlantedAreaExceedsLandArea, http.StatusConflict, "PLANTED_AREA_EXCEEDS_LAND_AREA")
+	c.h.RegisterError(ErrInvalidStatusTransition, http.StatusConflict, "INVALID_STATUS_TRANSITION")
+}
+
+func (c *Controller) CreateCrop(w http.ResponseWriter, r *http.Request) error {
+	var dto CreateCropDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		return fmt.Errorf("%w: %v", http_adapter.ErrMalformedBody, err)
+	}
+
+	id, err := c.cropService.CreateCrop(r.Context(), mux.Vars(r)["id"], &dto)
+	if err != nil {
+		return err
+	}
+
+	return http_adapter.WriteJSON(w, http.StatusCreated, map[string]string{"id": id})
+}
+
+func (c *Controller) ListCrops(w http.ResponseWriter, r *http.Request) error {
+	crops, err := c.cropService.ListCrops(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		return err
+	}
+
+	// Default value for empty crops
+	if len(crops) == 0 {
+		crops = []Crop{}
+	}
+
+	return http_adapter.WriteJSON(w, http.StatusOK, crops)
+}
+
+func (c *Controller) GetCropByID(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	crop, err := c.cropService.GetByID(r.Context(), vars["id"], vars["cropId"])
+	if err != nil {
+		return err
+	}
+
+	return http_adapter.WriteJSON(w, http.StatusOK, crop)
+}
+
+func (c *Controller) UpdateCrop(w http.ResponseWriter, r *http.Request) error {
+	var dto UpdateCropDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		return fmt.Errorf("%w: %v", http_adapter.ErrMalformedBody, err)
+	}
+
+	vars := mux.Vars(r)
+	crop, err := c.cropService.UpdateCrop(r.Context(), vars["id"], vars["cropId"], &dto)
+	if err != nil {
+		return err
+	}
+
+	return http_adapter.WriteJSON(w, http.StatusOK, crop)
+}
+
+func (c *Controller) TransitionCrop(w http.ResponseWriter, r *http.Request) error {
+	var dto TransitionCropDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		return fmt.Errorf("%w: %v", http_adapter.ErrMalformedBody, err)
+	}
+
+	vars := mux.Vars(r)
+	crop, err := c.cropService.TransitionCrop(r.Context(), vars["id"], vars["cropId"], &dto)
+	if err != nil {
+		return err
+	}
+
+	return http_adapter.WriteJSON(w, http.StatusOK, crop)
+}
+
+func (c *Controller) DeleteCrop(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	err := c.cropService.DeleteCrop(r.Context(), vars["id"], vars["cropId"])
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
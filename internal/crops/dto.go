@@ -1,19 +1,172 @@
 package crops
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"github.com/mateusfdl/go-api/internal/calendar"
+	"github.com/mateusfdl/go-api/internal/decimal"
+	"github.com/mateusfdl/go-api/internal/units"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type CreateCropDTO struct {
 	Type        CropType `json:"type"`
 	IsIrrigated bool     `json:"isIrrigated"`
 	IsInsured   bool     `json:"isInsured"`
-	FarmID      primitive.ObjectID
+	// Expressed in UnitOfMeasurement, the unit of the farm when empty
+	PlantedArea       *decimal.Decimal `json:"plantedArea"`
+	UnitOfMeasurement string           `json:"unitOfMeasurement"`
+	Season            string           `json:"season"`
+	// Planned when empty
+	Status              CropStatus     `json:"status"`
+	PlantingDate        *calendar.Date `json:"plantingDate"`
+	ExpectedHarvestDate *calendar.Date `json:"expectedHarvestDate"`
+	HarvestDate         *calendar.Date `json:"harvestDate"`
+	FarmID              primitive.ObjectID
+}
+
+type UpdateCropDTO struct {
+	Type        CropType `json:"type"`
+	IsIrrigated *bool    `json:"isIrrigated"`
+	IsInsured   *bool    `json:"isInsured"`
+	// Expressed in UnitOfMeasurement, the unit of the farm when empty
+	PlantedArea       *decimal.Decimal `json:"plantedArea"`
+	UnitOfMeasurement string           `json:"unitOfMeasurement"`
+	Season            string           `json:"season"`
+	// Only accepted to be rejected, status changes go through transitions
+	Status              CropStatus     `json:"status"`
+	PlantingDate        *calendar.Date `json:"plantingDate"`
+	ExpectedHarvestDate *calendar.Date `json:"expectedHarvestDate"`
+	HarvestDate         *calendar.Date `json:"harvestDate"`
+}
+
+// Moves a crop to the next status of its lifecycle
+type TransitionCropDTO struct {
+	Status CropStatus `json:"status"`
+	// Day of the change, today when empty. It becomes the planting date or
+	// the harvest date of the crop.
+	Date *calendar.Date `json:"date"`
 }
 
 func (d *CreateCropDTO) ToMap() map[string]interface{} {
-	return map[string]interface{}{
+	m := map[string]interface{}{
 		"type":        d.Type,
 		"isIrrigated": d.IsIrrigated,
 		"isInsured":   d.IsInsured,
 		"farmId":      d.FarmID,
+		"status":      d.Status,
+	}
+
+	if d.Status == "" {
+		m["status"] = StatusPlanned
+	}
+
+	if d.PlantedArea != nil {
+		m["plantedArea"] = *d.PlantedArea
+		m["unitOfMeasurement"] = d.UnitOfMeasurement
+		m["plantedAreaHectares"] = d.PlantedAreaHectares()
+	}
+
+	if d.Season != "" {
+		m["season"] = d.Season
+	}
+
+	setDates(m, d.PlantingDate, d.ExpectedHarvestDate, d.HarvestDate)
+	return m
+}
+
+// Falls back to the unit of the farm when the crop does not name one
+func (d *CreateCropDTO) InheritUnit(unit string) {
+	if d.UnitOfMeasurement == "" {
+		d.UnitOfMeasurement = unit
+	}
+}
+
+// Planted area normalized to hectares, nil when missing or in an unknown unit
+func (d *CreateCropDTO) PlantedAreaHectares() *decimal.Decimal {
+	return plantedAreaHectares(d.PlantedArea, d.UnitOfMeasurement)
+}
+
+func (d *UpdateCropDTO) ToMap() map[string]interface{} {
+	m := make(map[string]interface{})
+
+	if d.Type != "" {
+		m["type"] = d.Type
+	}
+
+	if d.IsIrrigated != nil {
+		m["isIrrigated"] = *d.IsIrrigated
+	}
+
+	if d.IsInsured != nil {
+		m["isInsured"] = *d.IsInsured
+	}
+
+	if d.PlantedArea != nil {
+		m["plantedArea"] = *d.PlantedArea
+		m["unitOfMeasurement"] = d.UnitOfMeasurement
+		m["plantedAreaHectares"] = d.PlantedAreaHectares()
+	}
+
+	if d.Season != "" {
+		m["season"] = d.Season
+	}
+
+	setDates(m, d.PlantingDate, d.ExpectedHarvestDate, d.HarvestDate)
+	return m
+}
+
+// Reports whether the update changes any date of the crop
+func (d *UpdateCropDTO) HasDates() bool {
+	return d.PlantingDate != nil || d.ExpectedHarvestDate != nil || d.HarvestDate != nil
+}
+
+// Fields set by the transition, the date going to the matching crop date
+func (d *TransitionCropDTO) ToMap() map[string]interface{} {
+	m := map[string]interface{}{"status": d.Status}
+
+	switch d.Status {
+	case StatusPlanted:
+		m["plantingDate"] = *d.Date
+	case StatusHarvested:
+		m["harvestDate"] = *d.Date
+	}
+
+	return m
+}
+
+func (d *UpdateCropDTO) InheritUnit(unit string) {
+	if d.UnitOfMeasurement == "" {
+		d.UnitOfMeasurement = unit
+	}
+}
+
+func (d *UpdateCropDTO) PlantedAreaHectares() *decimal.Decimal {
+	return plantedAreaHectares(d.PlantedArea, d.UnitOfMeasurement)
+}
+
+func plantedAreaHectares(area *decimal.Decimal, unit string) *decimal.Decimal {
+	if area == nil {
+		return nil
+	}
+
+	u, err := units.Default.Lookup(unit)
+	if err != nil {
+		return nil
+	}
+
+	hectares := u.HectaresOf(*area)
+	return &hectares
+}
+
+func setDates(m map[string]interface{}, planting, expectedHarvest, harvest *calendar.Date) {
+	if planting != nil {
+		m["plantingDate"] = *planting
+	}
+
+	if expectedHarvest != nil {
+		m["expectedHarvestDate"] = *expectedHarvest
+	}
+
+	if harvest != nil {
+		m["harvestDate"] = *harvest
 	}
 }
@@ -1,6 +1,11 @@
 package crops
 
-import "time"
+import (
+	"time"
+
+	"github.com/mateusfdl/go-api/internal/calendar"
+	"github.com/mateusfdl/go-api/internal/decimal"
+)
 
 const (
 	CropTypeCorn    = "CORN"
@@ -12,6 +17,7 @@ const (
 
 type CropType string
 
+// Crop types known before they were kept in a catalog, seeding it
 var CropTypes = []CropType{
 	CropTypeCorn,
 	CropTypeSoybean,
@@ -20,12 +26,79 @@ var CropTypes = []CropType{
 	CropTypeBeans,
 }
 
+// Tells which crop types crops can be given
+type TypeCatalog interface {
+	// Reports whether new crops can be given the type
+	IsActive(t CropType) bool
+	// Reports whether the type is in the catalog, deprecated or not
+	IsKnown(t CropType) bool
+}
+
+// Catalog of the built-in CropTypes, none of them deprecated
+type BuiltinTypes struct{}
+
+func (BuiltinTypes) IsActive(t CropType) bool {
+	return BuiltinTypes{}.IsKnown(t)
+}
+
+func (BuiltinTypes) IsKnown(t CropType) bool {
+	for _, cType := range CropTypes {
+		if t == cType {
+			return true
+		}
+	}
+
+	return false
+}
+
 type Crop struct {
-	ID          string    `bson:"_id"`
-	FarmID      string    `bson:"farmId"`
-	Type        CropType  `bson:"type"`
-	IsIrrigated bool      `bson:"isIrrigated"`
-	IsInsured   bool      `bson:"isInsured"`
-	CreatedAt   time.Time `bson:"createdAt"`
-	UpdatedAt   time.Time `bson:"updatedAt"`
+	ID          string   `bson:"_id"`
+	FarmID      string   `bson:"farmId"`
+	Type        CropType `bson:"type"`
+	IsIrrigated bool     `bson:"isIrrigated"`
+	IsInsured   bool     `bson:"isInsured"`
+	// Area of the farm the crop takes, in UnitOfMeasurement
+	PlantedArea       *decimal.Decimal `bson:"plantedArea,omitempty"`
+	UnitOfMeasurement string           `bson:"unitOfMeasurement,omitempty"`
+	// Planted area normalized to hectares, summed to check allocations
+	PlantedAreaHectares *decimal.Decimal `bson:"plantedAreaHectares,omitempty"`
+	// Agricultural year of the crop, like 2025/26
+	Season              string         `bson:"season,omitempty"`
+	Status              CropStatus     `bson:"status"`
+	PlantingDate        *calendar.Date `bson:"plantingDate,omitempty"`
+	ExpectedHarvestDate *calendar.Date `bson:"expectedHarvestDate,omitempty"`
+	HarvestDate         *calendar.Date `bson:"harvestDate,omitempty"`
+	// Transitions the crop went through, oldest first
+	StatusHistory []StatusChange `bson:"statusHistory,omitempty"`
+	CreatedAt     time.Time      `bson:"createdAt"`
+	UpdatedAt     time.Time      `bson:"updatedAt"`
+}
+
+// Crops written before they had a lifecycle are planned
+func (c *Crop) CurrentStatus() CropStatus {
+	if c.Status == "" {
+		return StatusPlanned
+	}
+
+	return c.Status
+}
+
+type StatusChange struct {
+	Status CropStatus `bson:"status"`
+	// Day the change happened in the field
+	Date      calendar.Date `bson:"date"`
+	ChangedAt time.Time     `bson:"changedAt"`
+}
+
+// Land area of a farm, as seen by its crops
+type FarmArea struct {
+	LandAreaHectares  decimal.Decimal `bson:"landAreaHectares"`
+	UnitOfMeasurement string          `bson:"unitOfMeasurement"`
+}
+
+// Area planted with a crop type on a farm
+type PlantedArea struct {
+	Type     CropType        `bson:"_id"`
+	Crops    int             `bson:"crops"`
+	Hectares decimal.Decimal `bson:"hectares"`
 }
@@ -0,0 +1,13 @@
+package crops
+
+import "errors"
+
+var (
+	ErrCropNotFound      = errors.New("Crop not found")
+	ErrFarmNotFound      = errors.New("Farm not found")
+	ErrOnConvertObjectID = errors.New("failed to convert to ObjectID")
+	ErrInvalidCropFields = errors.New("invalid crop fields")
+
+	ErrPlantedAreaExceedsLandArea = errors.New("planted area exceeds the land area of the farm")
+	ErrInvalidStatusTransition    = errors.New("invalid crop status transition")
+)
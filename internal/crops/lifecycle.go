@@ -0,0 +1,65 @@
+package crops
+
+import (
+	"regexp"
+	"strconv"
+)
+
+type CropStatus string
+
+const (
+	StatusPlanned   CropStatus = "planned"
+	StatusPlanted   CropStatus = "planted"
+	StatusGrowing   CropStatus = "growing"
+	StatusHarvested CropStatus = "harvested"
+	StatusFailed    CropStatus = "failed"
+)
+
+var CropStatuses = []CropStatus{StatusPlanned, StatusPlanted, StatusGrowing, StatusHarvested, StatusFailed}
+
+// Statuses a crop can move to from each status. Harvested and failed crops
+// are done with.
+var transitions = map[CropStatus][]CropStatus{
+	StatusPlanned: {StatusPlanted},
+	StatusPlanted: {StatusGrowing, StatusFailed},
+	StatusGrowing: {StatusHarvested, StatusFailed},
+}
+
+func (s CropStatus) IsValid() bool {
+	for _, status := range CropStatuses {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reports whether a crop in status s can move to next
+func (s CropStatus) CanTransitionTo(next CropStatus) bool {
+	for _, status := range transitions[s] {
+		if status == next {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Agricultural year, either a single year like 2025 or one spanning two like
+// 2025/26 for the summer safra
+var seasonPattern = regexp.MustCompile(`^(\d{4})(?:/(\d{2}))?$`)
+
+func ValidSeason(season string) bool {
+	match := seasonPattern.FindStringSubmatch(season)
+	if match == nil {
+		return false
+	}
+	if match[2] == "" {
+		return true
+	}
+
+	start, _ := strconv.Atoi(match[1])
+	end, _ := strconv.Atoi(match[2])
+	return (start+1)%100 == end
+}
@@ -0,0 +1,46 @@
+package crops_test
+
+import (
+	"testing"
+
+	"github.com/mateusfdl/go-api/internal/crops"
+)
+
+func TestCanTransitionTo(t *testing.T) {
+	allowed := map[crops.CropStatus][]crops.CropStatus{
+		crops.StatusPlanned: {crops.StatusPlanted},
+		crops.StatusPlanted: {crops.StatusGrowing, crops.StatusFailed},
+		crops.StatusGrowing: {crops.StatusHarvested, crops.StatusFailed},
+	}
+
+	for _, from := range crops.CropStatuses {
+		for _, to := range crops.CropStatuses {
+			want := false
+			for _, s := range allowed[from] {
+				want = want || s == to
+			}
+
+			if got := from.CanTransitionTo(to); got != want {
+				t.Errorf("%s -> %s: got %v, want %v", from, to, got, want)
+			}
+		}
+	}
+}
+
+func TestValidSeason(t *testing.T) {
+	cases := map[string]bool{
+		"2025/26": true,
+		"2099/00": true,
+		"2025":    true,
+		"2025/27": false,
+		"2025-26": false,
+		"25/26":   false,
+		"":        false,
+	}
+
+	for season, want := range cases {
+		if got := crops.ValidSeason(season); got != want {
+			t.Errorf("ValidSeason(%q) = %v, want %v", season, got, want)
+		}
+	}
+}
@@ -1,13 +1,27 @@
 package crops
 
 import (
+	"github.com/mateusfdl/go-api/adapters/http"
+	"github.com/mateusfdl/go-api/adapters/logger"
+	"github.com/mateusfdl/go-api/internal/audit"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type CropsModule struct {
 	Repository Repository
+	Service    *Service
+	Controller *Controller
 }
 
-func New(db *mongo.Database) *CropsModule {
-	return &CropsModule{Repository: NewMongoRepository(db)}
+func New(
+	l *logger.Logger,
+	h *http.HTTP,
+	db *mongo.Database,
+	types TypeCatalog,
+	cfg Config,
+) *CropsModule {
+	r := NewMongoRepository(db, l, audit.SystemClock)
+	s := NewService(l, r, types, audit.SystemClock, cfg)
+	c := NewController(h, s, l)
+	return &CropsModule{Repository: r, Service: s, Controller: c}
 }
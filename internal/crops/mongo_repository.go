@@ -2,17 +2,27 @@ package crops
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"github.com/mateusfdl/go-api/adapters/logger"
+	"github.com/mateusfdl/go-api/internal/audit"
+	"github.com/mateusfdl/go-api/internal/decimal"
+	"github.com/mateusfdl/go-api/internal/ids"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type MongoRepository struct {
-	db *mongo.Database
+	db         *mongo.Database
+	l          *logger.Logger
+	timestamps *audit.Timestamps
 }
 
-func NewMongoRepository(db *mongo.Database) *MongoRepository {
-	return &MongoRepository{db: db}
+func NewMongoRepository(db *mongo.Database, l *logger.Logger, clock audit.Clock) *MongoRepository {
+	return &MongoRepository{db: db, l: l, timestamps: audit.NewTimestamps(clock)}
 }
 
 // Bulk insert crops
@@ -21,14 +31,16 @@ func (r *MongoRepository) CreateMany(
 	farmId string,
 	dto *[]CreateCropDTO,
 ) error {
-	oid, err := primitive.ObjectIDFromHex(farmId)
+	oid, err := ids.ToObjectID(farmId)
 	if err != nil {
 		return err
 	}
 	docs := make([]interface{}, len(*dto))
 	for i, d := range *dto {
 		d.FarmID = oid
-		docs[i] = d.ToMap()
+		doc := d.ToMap()
+		r.timestamps.OnCreate(doc)
+		docs[i] = doc
 	}
 
 	_, err = r.db.Collection("crops").InsertMany(ctx, docs)
@@ -38,3 +50,397 @@ func (r *MongoRepository) CreateMany(
 
 	return nil
 }
+
+func (r *MongoRepository) CreateForFarms(
+	ctx context.Context,
+	dtos map[string]*[]CreateCropDTO,
+) error {
+	var docs []interface{}
+	for farmId, farmCrops := range dtos {
+		oid, err := ids.ToObjectID(farmId)
+		if err != nil {
+			return err
+		}
+
+		for _, d := range *farmCrops {
+			d.FarmID = oid
+			doc := d.ToMap()
+			r.timestamps.OnCreate(doc)
+			docs = append(docs, doc)
+		}
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+
+	_, err := r.db.Collection("crops").InsertMany(ctx, docs)
+	return err
+}
+
+func (r *MongoRepository) Create(
+	ctx context.Context,
+	farmId string,
+	dto *CreateCropDTO,
+) (string, error) {
+	oid, err := ids.ToObjectID(farmId)
+	if err != nil {
+		return "", err
+	}
+
+	dto.FarmID = oid
+	fields := dto.ToMap()
+	r.timestamps.OnCreate(fields)
+
+	doc, err := r.db.Collection("crops").InsertOne(ctx, fields)
+	if err != nil {
+		return "", err
+	}
+
+	cropOid, ok := doc.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return "", ErrOnConvertObjectID
+	}
+
+	return cropOid.Hex(), nil
+}
+
+func (r *MongoRepository) List(
+	ctx context.Context,
+	farmId string,
+) ([]Crop, error) {
+	oid, err := ids.ToObjectID(farmId)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := r.db.Collection("crops").Find(ctx, bson.M{"farmId": oid})
+	if err != nil {
+		return nil, err
+	}
+
+	var crops []Crop
+	if err := cursor.All(ctx, &crops); err != nil {
+		r.l.Error("error on listing crops", err)
+		return nil, err
+	}
+
+	return crops, nil
+}
+
+func (r *MongoRepository) GetByID(
+	ctx context.Context,
+	farmId string,
+	cropId string,
+) (*Crop, error) {
+	filter, err := cropFilter(farmId, cropId)
+	if err != nil {
+		return nil, err
+	}
+
+	var crop Crop
+	err = r.db.Collection("crops").FindOne(ctx, filter).Decode(&crop)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrCropNotFound
+		}
+
+		r.l.Error("error on get crop by id", err)
+		return nil, err
+	}
+
+	return &crop, nil
+}
+
+func (r *MongoRepository) Update(
+	ctx context.Context,
+	farmId string,
+	cropId string,
+	dto *UpdateCropDTO,
+) (string, error) {
+	filter, err := cropFilter(farmId, cropId)
+	if err != nil {
+		return "", err
+	}
+
+	fields := dto.ToMap()
+	r.timestamps.OnUpdate(fields)
+
+	result, err := r.db.Collection("crops").UpdateOne(ctx, filter, bson.M{"$set": fields})
+	if err != nil {
+		r.l.Error("error on update crop", err)
+		return "", err
+	}
+
+	if result.MatchedCount == 0 {
+		return "", ErrCropNotFound
+	}
+
+	return cropId, nil
+}
+
+// Moves the crop to the status of the transition, provided it is still in
+// status from, and records the change in its history
+func (r *MongoRepository) Transition(
+	ctx context.Context,
+	farmId string,
+	cropId string,
+	from CropStatus,
+	dto *TransitionCropDTO,
+) error {
+	filter, err := cropFilter(farmId, cropId)
+	if err != nil {
+		return err
+	}
+
+	filter["status"] = from
+	if from == StatusPlanned {
+		filter["status"] = bson.M{"$in": bson.A{StatusPlanned, nil}}
+	}
+
+	set := dto.ToMap()
+	r.timestamps.OnUpdate(set)
+	change := StatusChange{Status: dto.Status, Date: *dto.Date, ChangedAt: r.timestamps.Now()}
+
+	result, err := r.db.Collection("crops").UpdateOne(ctx, filter, bson.M{
+		"$set":  set,
+		"$push": bson.M{"statusHistory": change},
+	})
+	if err != nil {
+		r.l.Error("error on transition crop", err)
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%w: crop is not %s anymore", ErrInvalidStatusTransition, from)
+	}
+
+	return nil
+}
+
+func (r *MongoRepository) Delete(
+	ctx context.Context,
+	farmId string,
+	cropId string,
+) error {
+	filter, err := cropFilter(farmId, cropId)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Collection("crops").DeleteOne(ctx, filter)
+	if err != nil {
+		r.l.Error("error on delete crop", err)
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return ErrCropNotFound
+	}
+
+	return nil
+}
+
+// Removes every crop that belongs to the given farm
+func (r *MongoRepository) DeleteByFarm(
+	ctx context.Context,
+	farmId string,
+) (int64, error) {
+	oid, err := ids.ToObjectID(farmId)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := r.db.Collection("crops").DeleteMany(ctx, bson.M{"farmId": oid})
+	if err != nil {
+		r.l.Error("error on delete crops by farm", err)
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// Flags every crop of the given farm as deleted at the given time
+func (r *MongoRepository) SoftDeleteByFarm(
+	ctx context.Context,
+	farmId string,
+	at time.Time,
+) (int64, error) {
+	oid, err := ids.ToObjectID(farmId)
+	if err != nil {
+		return 0, err
+	}
+
+	set := bson.M{"deletedAt": at}
+	r.timestamps.OnUpdate(set)
+
+	filter := bson.M{"farmId": oid, "deletedAt": nil}
+	update := bson.M{"$set": set}
+
+	result, err := r.db.Collection("crops").UpdateMany(ctx, filter, update)
+	if err != nil {
+		r.l.Error("error on soft delete crops by farm", err)
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// Brings back the crops of the given farm that were soft deleted at the given time
+func (r *MongoRepository) RestoreByFarm(
+	ctx context.Context,
+	farmId string,
+	at time.Time,
+) (int64, error) {
+	oid, err := ids.ToObjectID(farmId)
+	if err != nil {
+		return 0, err
+	}
+
+	set := bson.M{}
+	r.timestamps.OnUpdate(set)
+
+	filter := bson.M{"farmId": oid, "deletedAt": at}
+	update := bson.M{"$set": set, "$unset": bson.M{"deletedAt": ""}}
+
+	result, err := r.db.Collection("crops").UpdateMany(ctx, filter, update)
+	if err != nil {
+		r.l.Error("error on restore crops by farm", err)
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// Checks whether the parent farm of the crops exists
+func (r *MongoRepository) FarmExists(
+	ctx context.Context,
+	farmId string,
+) (bool, error) {
+	oid, err := ids.ToObjectID(farmId)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := r.db.Collection("farms").CountDocuments(ctx, bson.M{"_id": oid, "deletedAt": nil})
+	if err != nil {
+		r.l.Error("error on checking farm existence", err)
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// Reads the land area of the parent farm of the crops
+func (r *MongoRepository) GetFarmArea(
+	ctx context.Context,
+	farmId string,
+) (*FarmArea, error) {
+	oid, err := ids.ToObjectID(farmId)
+	if err != nil {
+		return nil, err
+	}
+
+	var area FarmArea
+	opts := options.FindOne().SetProjection(bson.M{"landAreaHectares": 1, "unitOfMeasurement": 1})
+	err = r.db.Collection("farms").FindOne(ctx, bson.M{"_id": oid, "deletedAt": nil}, opts).Decode(&area)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrFarmNotFound
+		}
+
+		r.l.Error("error on get farm area", err)
+		return nil, err
+	}
+
+	return &area, nil
+}
+
+// Sums the planted area of the crops of the farm in hectares, leaving out
+// exceptCropId when not empty
+func (r *MongoRepository) SumPlantedArea(
+	ctx context.Context,
+	farmId string,
+	exceptCropId string,
+) (decimal.Decimal, error) {
+	oid, err := ids.ToObjectID(farmId)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	match := bson.M{"farmId": oid, "deletedAt": nil}
+	if exceptCropId != "" {
+		cropOid, err := ids.ToObjectID(exceptCropId)
+		if err != nil {
+			return decimal.Decimal{}, err
+		}
+		match["_id"] = bson.M{"$ne": cropOid}
+	}
+
+	cursor, err := r.db.Collection("crops").Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "hectares": bson.M{"$sum": "$plantedAreaHectares"}}}},
+	})
+	if err != nil {
+		r.l.Error("error on sum planted area", err)
+		return decimal.Decimal{}, err
+	}
+
+	var sums []struct {
+		Hectares decimal.Decimal `bson:"hectares"`
+	}
+	if err := cursor.All(ctx, &sums); err != nil {
+		return decimal.Decimal{}, err
+	}
+	if len(sums) == 0 {
+		return decimal.Decimal{}, nil
+	}
+
+	return sums[0].Hectares, nil
+}
+
+// Groups the crops of the farm by type, summing their planted area in hectares
+func (r *MongoRepository) PlantedAreaByType(
+	ctx context.Context,
+	farmId string,
+) ([]PlantedArea, error) {
+	oid, err := ids.ToObjectID(farmId)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := r.db.Collection("crops").Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"farmId": oid, "deletedAt": nil}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      "$type",
+			"crops":    bson.M{"$sum": 1},
+			"hectares": bson.M{"$sum": "$plantedAreaHectares"},
+		}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	})
+	if err != nil {
+		r.l.Error("error on group planted area", err)
+		return nil, err
+	}
+
+	areas := []PlantedArea{}
+	if err := cursor.All(ctx, &areas); err != nil {
+		return nil, err
+	}
+
+	return areas, nil
+}
+
+// Builds the filter that scopes a crop to its parent farm
+func cropFilter(farmId string, cropId string) (bson.M, error) {
+	farmOid, err := ids.ToObjectID(farmId)
+	if err != nil {
+		return nil, err
+	}
+
+	cropOid, err := ids.ToObjectID(cropId)
+	if err != nil {
+		return nil, err
+	}
+
+	return bson.M{"_id": cropOid, "farmId": farmOid}, nil
+}
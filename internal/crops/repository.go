@@ -1,7 +1,32 @@
 package crops
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/mateusfdl/go-api/internal/decimal"
+)
 
 type Repository interface {
 	CreateMany(ctx context.Context, farmId string, dtos *[]CreateCropDTO) error
+	// Inserts the crops of several farms at once, keyed by farm
+	CreateForFarms(ctx context.Context, dtos map[string]*[]CreateCropDTO) error
+	Create(ctx context.Context, farmId string, dto *CreateCropDTO) (string, error)
+	List(ctx context.Context, farmId string) ([]Crop, error)
+	GetByID(ctx context.Context, farmId string, cropId string) (*Crop, error)
+	Update(ctx context.Context, farmId string, cropId string, dto *UpdateCropDTO) (string, error)
+	Transition(ctx context.Context, farmId string, cropId string, from CropStatus, dto *TransitionCropDTO) error
+	Delete(ctx context.Context, farmId string, cropId string) error
+	DeleteByFarm(ctx context.Context, farmId string) (int64, error)
+	SoftDeleteByFarm(ctx context.Context, farmId string, at time.Time) (int64, error)
+	RestoreByFarm(ctx context.Context, farmId string, at time.Time) (int64, error)
+	FarmExists(ctx context.Context, farmId string) (bool, error)
+	GetFarmArea(ctx context.Context, farmId string) (*FarmArea, error)
+	SumPlantedArea(ctx context.Context, farmId string, exceptCropId string) (decimal.Decimal, error)
+	PlantedAreaByType(ctx context.Context, farmId string) ([]PlantedArea, error)
+}
+
+// A collection holding documents owned by a crop, removed along with it
+type Dependent interface {
+	DeleteByCrop(ctx context.Context, farmId string, cropId string) (int64, error)
 }
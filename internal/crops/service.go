@@ -0,0 +1,373 @@
+package crops
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mateusfdl/go-api/adapters/logger"
+	"github.com/mateusfdl/go-api/internal/audit"
+	"github.com/mateusfdl/go-api/internal/calendar"
+	"github.com/mateusfdl/go-api/internal/decimal"
+	"github.com/mateusfdl/go-api/internal/units"
+	"github.com/mateusfdl/go-api/internal/validation"
+)
+
+type Service struct {
+	l              *logger.Logger
+	cropRepository Repository
+	types          TypeCatalog
+	clock          audit.Clock
+	cfg            Config
+	dependents     []Dependent
+}
+
+func NewService(l *logger.Logger, cropRepo Repository, types TypeCatalog, clock audit.Clock, cfg Config) *Service {
+	return &Service{l: l, cropRepository: cropRepo, types: types, clock: clock, cfg: cfg}
+}
+
+// Registers a collection whose documents are deleted in cascade with their crop
+func (s *Service) RegisterDependent(d Dependent) {
+	s.dependents = append(s.dependents, d)
+}
+
+func (s *Service) CreateCrop(ctx context.Context, farmId string, dto *CreateCropDTO) (string, error) {
+	if err := validateFields(dto, s.types, s.cfg.AreaPrecision); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidCropFields, err)
+	}
+
+	farm, err := s.cropRepository.GetFarmArea(ctx, farmId)
+	if err != nil {
+		return "", err
+	}
+
+	if dto.PlantedArea != nil {
+		dto.InheritUnit(farm.UnitOfMeasurement)
+		if err := s.checkAllocation(ctx, farmId, "", farm, dto.PlantedAreaHectares()); err != nil {
+			return "", err
+		}
+	}
+
+	return s.cropRepository.Create(ctx, farmId, dto)
+}
+
+func (s *Service) ListCrops(ctx context.Context, farmId string) ([]Crop, error) {
+	if err := s.ensureFarmExists(ctx, farmId); err != nil {
+		return nil, err
+	}
+
+	return s.cropRepository.List(ctx, farmId)
+}
+
+func (s *Service) GetByID(ctx context.Context, farmId string, cropId string) (*Crop, error) {
+	if err := s.ensureFarmExists(ctx, farmId); err != nil {
+		return nil, err
+	}
+
+	return s.cropRepository.GetByID(ctx, farmId, cropId)
+}
+
+func (s *Service) UpdateCrop(ctx context.Context, farmId string, cropId string, dto *UpdateCropDTO) (*Crop, error) {
+	// Crops sent back with the type they already have keep it, even once it
+	// was deprecated
+	var current CropType
+	if dto.Type != "" && !s.types.IsActive(dto.Type) {
+		crop, err := s.cropRepository.GetByID(ctx, farmId, cropId)
+		if err != nil {
+			return nil, err
+		}
+		current = crop.Type
+	}
+
+	if err := validateUpdateFields(dto, s.types, current, s.cfg.AreaPrecision); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCropFields, err)
+	}
+
+	farm, err := s.cropRepository.GetFarmArea(ctx, farmId)
+	if err != nil {
+		return nil, err
+	}
+
+	if dto.HasDates() {
+		if err := s.checkUpdatedDates(ctx, farmId, cropId, dto); err != nil {
+			return nil, err
+		}
+	}
+
+	if dto.PlantedArea != nil {
+		dto.InheritUnit(farm.UnitOfMeasurement)
+		if err := s.checkAllocation(ctx, farmId, cropId, farm, dto.PlantedAreaHectares()); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := s.cropRepository.Update(ctx, farmId, cropId, dto); err != nil {
+		return nil, err
+	}
+
+	return s.cropRepository.GetByID(ctx, farmId, cropId)
+}
+
+// Checks the dates of the update along with the ones the crop already has
+func (s *Service) checkUpdatedDates(ctx context.Context, farmId string, cropId string, dto *UpdateCropDTO) error {
+	crop, err := s.cropRepository.GetByID(ctx, farmId, cropId)
+	if err != nil {
+		return err
+	}
+
+	planting, expectedHarvest, harvest := crop.PlantingDate, crop.ExpectedHarvestDate, crop.HarvestDate
+	if dto.PlantingDate != nil {
+		planting = dto.PlantingDate
+	}
+	if dto.ExpectedHarvestDate != nil {
+		expectedHarvest = dto.ExpectedHarvestDate
+	}
+	if dto.HarvestDate != nil {
+		harvest = dto.HarvestDate
+	}
+
+	var errs validation.Errors
+	validateDates(&errs, "", planting, expectedHarvest, harvest)
+	if dto.HarvestDate != nil && crop.CurrentStatus() != StatusHarvested {
+		errs.Add("harvestDate", validation.CodeInvalid, "harvestDate can only be set on harvested crops")
+	}
+
+	if err := errs.Err(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidCropFields, err)
+	}
+
+	return nil
+}
+
+// Moves the crop to the next status of its lifecycle. Planting and harvest
+// transitions also set the matching date of the crop.
+func (s *Service) TransitionCrop(ctx context.Context, farmId string, cropId string, dto *TransitionCropDTO) (*Crop, error) {
+	today := calendar.Of(s.clock.Now())
+	if dto.Date == nil {
+		dto.Date = &today
+	}
+
+	if err := validateTransitionFields(dto, today); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCropFields, err)
+	}
+
+	crop, err := s.GetByID(ctx, farmId, cropId)
+	if err != nil {
+		return nil, err
+	}
+
+	from := crop.CurrentStatus()
+	if !from.CanTransitionTo(dto.Status) {
+		return nil, fmt.Errorf("%w: %s crops can not become %s", ErrInvalidStatusTransition, from, dto.Status)
+	}
+
+	if dto.Status == StatusHarvested && crop.PlantingDate != nil && dto.Date.Before(*crop.PlantingDate) {
+		var errs validation.Errors
+		errs.Add("date", validation.CodeInvalid, "harvest date can not be before the planting date "+crop.PlantingDate.String())
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCropFields, errs)
+	}
+
+	if err := s.cropRepository.Transition(ctx, farmId, cropId, from, dto); err != nil {
+		return nil, err
+	}
+
+	return s.cropRepository.GetByID(ctx, farmId, cropId)
+}
+
+// Checks that the planted area of a crop fits the farm along with the other
+// crops, cropId being left out of the sum when it is an update
+func (s *Service) checkAllocation(ctx context.Context, farmId string, cropId string, farm *FarmArea, hectares *decimal.Decimal) error {
+	if hectares == nil {
+		return nil
+	}
+
+	planted, err := s.cropRepository.SumPlantedArea(ctx, farmId, cropId)
+	if err != nil {
+		return err
+	}
+
+	return CheckAllocation(farm.LandAreaHectares, planted.Add(*hectares))
+}
+
+// Fails when the planted area does not fit the land area, both in hectares.
+// Farms in an unknown unit have no land area in hectares and are not checked.
+func CheckAllocation(landAreaHectares decimal.Decimal, plantedHectares decimal.Decimal) error {
+	if landAreaHectares.IsZero() || plantedHectares.Cmp(landAreaHectares) <= 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s ha planted out of %s ha", ErrPlantedAreaExceedsLandArea, plantedHectares, landAreaHectares)
+}
+
+func (s *Service) DeleteCrop(ctx context.Context, farmId string, cropId string) error {
+	if _, err := s.GetByID(ctx, farmId, cropId); err != nil {
+		return err
+	}
+
+	for _, d := range s.dependents {
+		if _, err := d.DeleteByCrop(ctx, farmId, cropId); err != nil {
+			return fmt.Errorf("failed to delete dependents of crop: %w", err)
+		}
+	}
+
+	return s.cropRepository.Delete(ctx, farmId, cropId)
+}
+
+func (s *Service) ensureFarmExists(ctx context.Context, farmId string) error {
+	exists, err := s.cropRepository.FarmExists(ctx, farmId)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return ErrFarmNotFound
+	}
+
+	return nil
+}
+
+func validateFields(dto *CreateCropDTO, types TypeCatalog, precision int) error {
+	var errs validation.Errors
+	ValidateCrop(&errs, "", dto, types, precision)
+	return errs.Err()
+}
+
+// The current type of the crop is accepted as it is, only newly given types
+// having to be active
+func validateUpdateFields(dto *UpdateCropDTO, types TypeCatalog, current CropType, precision int) error {
+	var errs validation.Errors
+	if dto.Type != "" && dto.Type != current {
+		validateType(&errs, "type", dto.Type, types)
+	}
+
+	validatePlantedArea(&errs, "", dto.PlantedArea, dto.UnitOfMeasurement, precision)
+	validateSeason(&errs, "", dto.Season)
+	if dto.Status != "" {
+		errs.Add("status", validation.CodeInvalid, "status can only be changed through transitions")
+	}
+
+	return errs.Err()
+}
+
+func validateTransitionFields(dto *TransitionCropDTO, today calendar.Date) error {
+	var errs validation.Errors
+	if dto.Status == "" {
+		errs.Add("status", validation.CodeRequired, "status is required")
+	} else {
+		validateStatus(&errs, "status", dto.Status)
+	}
+
+	if dto.Date.After(today) {
+		errs.Add("date", validation.CodeInvalid, "date can not be in the future")
+	}
+
+	return errs.Err()
+}
+
+// Appends the problems of the crop to errs, prefixing its fields with path.
+// Planted areas are limited to precision decimal places.
+func ValidateCrop(errs *validation.Errors, path string, dto *CreateCropDTO, types TypeCatalog, precision int) {
+	field := validation.Field(path, "type")
+	if dto.Type == "" {
+		errs.Add(field, validation.CodeRequired, "crop type is required")
+	} else {
+		validateType(errs, field, dto.Type, types)
+	}
+
+	validatePlantedArea(errs, path, dto.PlantedArea, dto.UnitOfMeasurement, precision)
+	validateSeason(errs, path, dto.Season)
+	validateDates(errs, path, dto.PlantingDate, dto.ExpectedHarvestDate, dto.HarvestDate)
+	validateInitialStatus(errs, path, dto)
+}
+
+// Crops can be created at any point of their lifecycle, provided they carry
+// the dates of the steps they went through
+func validateInitialStatus(errs *validation.Errors, path string, dto *CreateCropDTO) {
+	status := dto.Status
+	if status == "" {
+		status = StatusPlanned
+	} else if !validateStatus(errs, validation.Field(path, "status"), status) {
+		return
+	}
+
+	if status != StatusPlanned && dto.PlantingDate == nil {
+		errs.Add(validation.Field(path, "plantingDate"), validation.CodeRequired, "plantingDate is required once the crop is "+string(status))
+	}
+
+	if status == StatusHarvested && dto.HarvestDate == nil {
+		errs.Add(validation.Field(path, "harvestDate"), validation.CodeRequired, "harvestDate is required once the crop is harvested")
+	}
+
+	if status != StatusHarvested && dto.HarvestDate != nil {
+		errs.Add(validation.Field(path, "harvestDate"), validation.CodeInvalid, "harvestDate can only be set on harvested crops")
+	}
+}
+
+func validateStatus(errs *validation.Errors, field string, status CropStatus) bool {
+	if status.IsValid() {
+		return true
+	}
+
+	names := make([]string, len(CropStatuses))
+	for i, s := range CropStatuses {
+		names[i] = string(s)
+	}
+	errs.Add(field, validation.CodeInvalid, "status must be one of "+strings.Join(names, ", "))
+	return false
+}
+
+func validateSeason(errs *validation.Errors, path string, season string) {
+	if season != "" && !ValidSeason(season) {
+		errs.Add(validation.Field(path, "season"), validation.CodeInvalid, "season must be a year or a pair of years, like 2025/26")
+	}
+}
+
+// Harvests can not come before the planting
+func validateDates(errs *validation.Errors, path string, planting, expectedHarvest, harvest *calendar.Date) {
+	if planting == nil {
+		return
+	}
+
+	if expectedHarvest != nil && expectedHarvest.Before(*planting) {
+		errs.Add(validation.Field(path, "expectedHarvestDate"), validation.CodeInvalid, "expectedHarvestDate can not be before plantingDate")
+	}
+
+	if harvest != nil && harvest.Before(*planting) {
+		errs.Add(validation.Field(path, "harvestDate"), validation.CodeInvalid, "harvestDate can not be before plantingDate")
+	}
+}
+
+// Deprecated types are kept by existing crops but can not be given anymore
+func validateType(errs *validation.Errors, field string, t CropType, types TypeCatalog) {
+	if types.IsActive(t) {
+		return
+	}
+
+	if types.IsKnown(t) {
+		errs.Add(field, validation.CodeDeprecated, "crop type "+string(t)+" is deprecated")
+		return
+	}
+
+	errs.Add(field, validation.CodeInvalid, "invalid crop type")
+}
+
+// The unit only applies to the planted area, so it can not be given alone
+func validatePlantedArea(errs *validation.Errors, path string, area *decimal.Decimal, unit string, precision int) {
+	field := validation.Field(path, "plantedArea")
+	if area != nil && area.Sign() <= 0 {
+		errs.Add(field, validation.CodePositive, "plantedArea must be positive")
+	} else if area != nil && area.Places() > precision {
+		errs.Add(field, validation.CodeInvalid, fmt.Sprintf("plantedArea must have at most %d decimal places", precision))
+	}
+
+	if unit == "" {
+		return
+	}
+
+	field = validation.Field(path, "unitOfMeasurement")
+	if area == nil {
+		errs.Add(field, validation.CodeInvalid, "unitOfMeasurement can only be given along with plantedArea")
+	} else if _, err := units.Default.Lookup(unit); err != nil {
+		errs.Add(field, validation.CodeInvalid, "unitOfMeasurement must be one of "+strings.Join(units.Default.Symbols(), ", "))
+	}
+}
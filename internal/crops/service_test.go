@@ -0,0 +1,87 @@
+package crops_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mateusfdl/go-api/adapters/logger"
+	"github.com/mateusfdl/go-api/internal/audit"
+	"github.com/mateusfdl/go-api/internal/crops"
+	"github.com/mateusfdl/go-api/internal/decimal"
+	"github.com/mateusfdl/go-api/internal/validation"
+)
+
+func TestValidateCropPlantedArea(t *testing.T) {
+	precise := decimal.MustParse("1.234")
+	rounded := decimal.MustParse("1.23")
+
+	cases := []struct {
+		name  string
+		dto   crops.CreateCropDTO
+		field string
+	}{
+		{"unit without planted area", crops.CreateCropDTO{Type: crops.CropTypeCorn, UnitOfMeasurement: "acres"}, "crops[0].unitOfMeasurement"},
+		{"too many decimal places", crops.CreateCropDTO{Type: crops.CropTypeCorn, PlantedArea: &precise}, "crops[0].plantedArea"},
+		{"valid", crops.CreateCropDTO{Type: crops.CropTypeCorn, PlantedArea: &rounded, UnitOfMeasurement: "acres"}, ""},
+	}
+	for _, c := range cases {
+		var errs validation.Errors
+		crops.ValidateCrop(&errs, "crops[0]", &c.dto, crops.BuiltinTypes{}, 2)
+
+		if c.field == "" && len(errs) != 0 {
+			t.Errorf("%s: expect no problem, but got %v", c.name, errs)
+		}
+		if c.field != "" && (len(errs) != 1 || errs[0].Field != c.field) {
+			t.Errorf("%s: expect a problem on %s, but got %v", c.name, c.field, errs)
+		}
+	}
+}
+
+// Catalog where COFFEE was deprecated
+type deprecatingCatalog struct{}
+
+func (deprecatingCatalog) IsActive(t crops.CropType) bool {
+	return t != crops.CropTypeCoffee && crops.BuiltinTypes{}.IsKnown(t)
+}
+
+func (deprecatingCatalog) IsKnown(t crops.CropType) bool {
+	return crops.BuiltinTypes{}.IsKnown(t)
+}
+
+type fakeCropRepository struct {
+	crops.Repository
+	crop    crops.Crop
+	updated bool
+}
+
+func (r *fakeCropRepository) GetByID(ctx context.Context, farmId string, cropId string) (*crops.Crop, error) {
+	return &r.crop, nil
+}
+
+func (r *fakeCropRepository) GetFarmArea(ctx context.Context, farmId string) (*crops.FarmArea, error) {
+	return &crops.FarmArea{LandAreaHectares: decimal.MustParse("100"), UnitOfMeasurement: "ha"}, nil
+}
+
+func (r *fakeCropRepository) Update(ctx context.Context, farmId string, cropId string, dto *crops.UpdateCropDTO) (string, error) {
+	r.updated = true
+	return cropId, nil
+}
+
+func TestUpdateCropKeepsItsDeprecatedType(t *testing.T) {
+	l := logger.New(logger.Config{Level: "error"})
+	repo := &fakeCropRepository{crop: crops.Crop{ID: "c1", Type: crops.CropTypeCoffee}}
+	s := crops.NewService(l, repo, deprecatingCatalog{}, audit.SystemClock, crops.Config{AreaPrecision: 2})
+
+	insured := true
+	if _, err := s.UpdateCrop(context.Background(), "f1", "c1", &crops.UpdateCropDTO{Type: crops.CropTypeCoffee, IsInsured: &insured}); err != nil || !repo.updated {
+		t.Fatalf("Expect the crop to keep its deprecated type, but got %v", err)
+	}
+
+	repo.crop.Type, repo.updated = crops.CropTypeCorn, false
+	_, err := s.UpdateCrop(context.Background(), "f1", "c1", &crops.UpdateCropDTO{Type: crops.CropTypeCoffee})
+	var problems validation.Errors
+	if !errors.As(err, &problems) || problems[0].Code != validation.CodeDeprecated || repo.updated {
+		t.Errorf("Expect the deprecated type to be rejected when newly given, but got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package croptypes
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mateusfdl/go-api/adapters/logger"
+	"github.com/mateusfdl/go-api/internal/crops"
+)
+
+// In-memory copy of the crop type catalog, consulted on every crop validation.
+// It is reloaded after writes and on every refresh interval, so that the
+// changes made through other instances are picked up.
+type Catalog struct {
+	l        *logger.Logger
+	repo     Repository
+	interval time.Duration
+
+	mu    sync.RWMutex
+	types map[crops.CropType]CropType
+}
+
+func NewCatalog(l *logger.Logger, repo Repository, cfg Config) *Catalog {
+	return &Catalog{
+		l:        l,
+		repo:     repo,
+		interval: time.Duration(cfg.RefreshIntervalSeconds) * time.Second,
+		types:    make(map[crops.CropType]CropType),
+	}
+}
+
+// Replaces the cached catalog with the stored one
+func (c *Catalog) Load(ctx context.Context) error {
+	list, err := c.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	types := make(map[crops.CropType]CropType, len(list))
+	for _, t := range list {
+		types[t.Code] = t
+	}
+
+	c.mu.Lock()
+	c.types = types
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Reloads the catalog on every interval until ctx is cancelled
+func (c *Catalog) Start(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.l.Info("Crop type catalog refresh stopped")
+			return
+		case <-ticker.C:
+			if err := c.Load(ctx); err != nil {
+				c.l.Error("Failed to refresh crop type catalog", err)
+			}
+		}
+	}
+}
+
+func (c *Catalog) Get(code crops.CropType) (CropType, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	t, ok := c.types[code]
+	return t, ok
+}
+
+// Crop types sorted by code, deprecated ones only when asked for
+func (c *Catalog) List(includeDeprecated bool) []CropType {
+	c.mu.RLock()
+	list := make([]CropType, 0, len(c.types))
+	for _, t := range c.types {
+		if includeDeprecated || !t.Deprecated {
+			list = append(list, t)
+		}
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+func (c *Catalog) IsActive(code crops.CropType) bool {
+	t, ok := c.Get(code)
+	return ok && !t.Deprecated
+}
+
+func (c *Catalog) IsKnown(code crops.CropType) bool {
+	_, ok := c.Get(code)
+	return ok
+}
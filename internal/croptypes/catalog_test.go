@@ -0,0 +1,116 @@
+package croptypes_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mateusfdl/go-api/adapters/logger"
+	"github.com/mateusfdl/go-api/internal/crops"
+	"github.com/mateusfdl/go-api/internal/croptypes"
+)
+
+type fakeRepository struct {
+	croptypes.Repository
+	types []croptypes.CropType
+	err   error
+}
+
+func (r *fakeRepository) List(ctx context.Context) ([]croptypes.CropType, error) {
+	return r.types, r.err
+}
+
+func newCatalog(repo *fakeRepository) *croptypes.Catalog {
+	l := logger.New(logger.Config{Level: "error"})
+	return croptypes.NewCatalog(l, repo, croptypes.Config{RefreshIntervalSeconds: 60})
+}
+
+func TestCatalogReportsActiveAndKnownTypes(t *testing.T) {
+	repo := &fakeRepository{types: []croptypes.CropType{
+		{Code: "SUGARCANE", Name: "Sugarcane"},
+		{Code: "TOBACCO", Name: "Tobacco", Deprecated: true},
+	}}
+	catalog := newCatalog(repo)
+	if err := catalog.Load(context.Background()); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	cases := []struct {
+		code          crops.CropType
+		active, known bool
+	}{
+		{"SUGARCANE", true, true},
+		{"TOBACCO", false, true},
+		{"WHEAT", false, false},
+	}
+	for _, c := range cases {
+		if got := catalog.IsActive(c.code); got != c.active {
+			t.Errorf("IsActive(%s) = %v, want %v", c.code, got, c.active)
+		}
+		if got := catalog.IsKnown(c.code); got != c.known {
+			t.Errorf("IsKnown(%s) = %v, want %v", c.code, got, c.known)
+		}
+	}
+
+	if list := catalog.List(false); len(list) != 1 || list[0].Code != "SUGARCANE" {
+		t.Errorf("Expect only SUGARCANE listed, but got %+v", list)
+	}
+	if list := catalog.List(true); len(list) != 2 {
+		t.Errorf("Expect 2 crop types with deprecated ones, but got %d", len(list))
+	}
+}
+
+func TestCatalogKeepsTypesWhenLoadFails(t *testing.T) {
+	repo := &fakeRepository{types: []croptypes.CropType{{Code: "CORN", Name: "Corn"}}}
+	catalog := newCatalog(repo)
+	if err := catalog.Load(context.Background()); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	repo.err = errors.New("connection lost")
+	if err := catalog.Load(context.Background()); err == nil {
+		t.Fatal("Expect Load to fail")
+	}
+
+	if !catalog.IsActive("CORN") {
+		t.Error("Expect CORN to remain in the catalog")
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	labels := map[string]string{"pt-BR": "Cana-de-açúcar", "es": "Caña de azúcar"}
+
+	cases := []struct {
+		header string
+		want   string
+		ok     bool
+	}{
+		{"pt-BR", "Cana-de-açúcar", true},
+		{"pt", "Cana-de-açúcar", true},
+		{"es-AR", "Caña de azúcar", true},
+		{"en, es;q=0.5", "Caña de azúcar", true},
+		{"es;q=0.4, pt-br;q=0.8", "Cana-de-açúcar", true},
+		{"fr", "", false},
+		{"", "", false},
+	}
+	for _, c := range cases {
+		got, ok := croptypes.Localize(labels, c.header)
+		if got != c.want || ok != c.ok {
+			t.Errorf("Localize(%q) = %q, %v, want %q, %v", c.header, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestLocalizePicksRegionalVariantsDeterministically(t *testing.T) {
+	labels := map[string]string{"pt-PT": "Milho (PT)", "pt-BR": "Milho (BR)", "es": "Maíz"}
+	for i := 0; i < 20; i++ {
+		if got, _ := croptypes.Localize(labels, "pt"); got != "Milho (BR)" {
+			t.Fatalf("Localize(pt) = %q, want the first variant in alphabetical order", got)
+		}
+	}
+
+	labels["pt"] = "Milho"
+	if got, _ := croptypes.Localize(labels, "pt-AO"); got != "Milho" {
+		t.Errorf("Localize(pt-AO) = %q, want the label of the bare primary language", got)
+	}
+}
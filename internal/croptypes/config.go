@@ -0,0 +1,7 @@
+package croptypes
+
+type Config struct {
+	// Seconds between reloads of the cached catalog, picking up the changes
+	// made through other instances
+	RefreshIntervalSeconds int
+}
@@ -0,0 +1,119 @@
+package croptypes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	http_adapter "github.com/mateusfdl/go-api/adapters/http"
+	"github.com/mateusfdl/go-api/adapters/logger"
+	"github.com/mateusfdl/go-api/internal/crops"
+	"github.com/mateusfdl/go-api/internal/validation"
+)
+
+const AcceptLanguageHeader = "Accept-Language"
+
+type Controller struct {
+	s *Service
+	l *logger.Logger
+	h *http_adapter.HTTP
+}
+
+func NewController(h *http_adapter.HTTP, s *Service, l *logger.Logger) *Controller {
+	return &Controller{s: s, l: l, h: h}
+}
+
+// Register all crop type routes, writes being restricted to admins
+func (c *Controller) RegisterRoutes() {
+	c.l.Info("Registering crop type routes")
+	c.registerErrors()
+	c.h.Router.HandleFunc("/crop-types", c.h.Handle(c.ListCropTypes)).Methods("GET").Name("ListCropTypes")
+	c.h.Router.HandleFunc("/crop-types", c.h.Handle(c.h.Admin(c.CreateCropType))).Methods("POST").Name("CreateCropType")
+	c.h.Router.HandleFunc("/crop-types/{code}", c.h.Handle(c.GetCropType)).Methods("GET").Name("GetCropType")
+	c.h.Router.HandleFunc("/crop-types/{code}", c.h.Handle(c.h.Admin(c.UpdateCropType))).Methods("PUT").Name("UpdateCropType")
+	c.h.Router.HandleFunc("/crop-types/{code}", c.h.Handle(c.h.Admin(c.DeprecateCropType))).Methods("DELETE").Name("DeprecateCropType")
+}
+
+// Register the HTTP status of every crop type error
+func (c *Controller) registerErrors() {
+	c.h.RegisterError(ErrCropTypeNotFound, http.StatusNotFound, "CROP_TYPE_NOT_FOUND")
+	c.h.RegisterError(ErrCropTypeAlreadyExists, http.StatusConflict, "CROP_TYPE_ALREADY_EXISTS")
+	c.h.RegisterError(ErrInvalidCropTypeFields, http.StatusBadRequest, "INVALID_CROP_TYPE_FIELDS")
+}
+
+func (c *Controller) ListCropTypes(w http.ResponseWriter, r *http.Request) error {
+	var errs validation.Errors
+	includeDeprecated := validation.QueryBool(r.URL.Query(), "includeDeprecated", false, &errs)
+	if err := errs.Err(); err != nil {
+		return fmt.Errorf("%w: %w", http_adapter.ErrInvalidQuery, err)
+	}
+
+	types := c.s.ListCropTypes(includeDeprecated)
+	for i := range types {
+		localize(&types[i], r)
+	}
+
+	return http_adapter.WriteJSON(w, http.StatusOK, types)
+}
+
+func (c *Controller) GetCropType(w http.ResponseWriter, r *http.Request) error {
+	t, err := c.s.GetByCode(crops.CropType(mux.Vars(r)["code"]))
+	if err != nil {
+		return err
+	}
+
+	localize(t, r)
+	return http_adapter.WriteJSON(w, http.StatusOK, t)
+}
+
+func (c *Controller) CreateCropType(w http.ResponseWriter, r *http.Request) error {
+	var dto CreateCropTypeDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		return fmt.Errorf("%w: %v", http_adapter.ErrMalformedBody, err)
+	}
+
+	t, err := c.s.CreateCropType(r.Context(), &dto)
+	if err != nil {
+		return err
+	}
+
+	localize(t, r)
+	return http_adapter.WriteJSON(w, http.StatusCreated, t)
+}
+
+func (c *Controller) UpdateCropType(w http.ResponseWriter, r *http.Request) error {
+	var dto UpdateCropTypeDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		return fmt.Errorf("%w: %v", http_adapter.ErrMalformedBody, err)
+	}
+
+	t, err := c.s.UpdateCropType(r.Context(), crops.CropType(mux.Vars(r)["code"]), &dto)
+	if err != nil {
+		return err
+	}
+
+	localize(t, r)
+	return http_adapter.WriteJSON(w, http.StatusOK, t)
+}
+
+// Crop types are only deprecated, as existing crops keep referring to them
+func (c *Controller) DeprecateCropType(w http.ResponseWriter, r *http.Request) error {
+	t, err := c.s.DeprecateCropType(r.Context(), crops.CropType(mux.Vars(r)["code"]))
+	if err != nil {
+		return err
+	}
+
+	localize(t, r)
+	return http_adapter.WriteJSON(w, http.StatusOK, t)
+}
+
+// Fills the label in the language asked by the client, the name otherwise
+func localize(t *CropType, r *http.Request) {
+	if label, ok := Localize(t.Labels, r.Header.Get(AcceptLanguageHeader)); ok {
+		t.Label = label
+		return
+	}
+
+	t.Label = t.Name
+}
@@ -0,0 +1,41 @@
+package croptypes
+
+import "github.com/mateusfdl/go-api/internal/crops"
+
+type CreateCropTypeDTO struct {
+	Code   crops.CropType    `json:"code"`
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels"`
+}
+
+// Replaces every field of the crop type but its code
+type UpdateCropTypeDTO struct {
+	Name       string            `json:"name"`
+	Labels     map[string]string `json:"labels"`
+	Deprecated bool              `json:"deprecated"`
+}
+
+func (dto *CreateCropTypeDTO) ToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"_id":        dto.Code,
+		"name":       dto.Name,
+		"labels":     labelsOrEmpty(dto.Labels),
+		"deprecated": false,
+	}
+}
+
+func (dto *UpdateCropTypeDTO) ToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"name":       dto.Name,
+		"labels":     labelsOrEmpty(dto.Labels),
+		"deprecated": dto.Deprecated,
+	}
+}
+
+func labelsOrEmpty(labels map[string]string) map[string]string {
+	if labels == nil {
+		return map[string]string{}
+	}
+
+	return labels
+}
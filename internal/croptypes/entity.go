@@ -0,0 +1,21 @@
+package croptypes
+
+import (
+	"time"
+
+	"github.com/mateusfdl/go-api/internal/crops"
+)
+
+// A crop type of the catalog, identified by its code
+type CropType struct {
+	Code crops.CropType `bson:"_id"`
+	Name string         `bson:"name"`
+	// Names by language tag, like pt-BR
+	Labels map[string]string `bson:"labels"`
+	// Kept by existing crops, but not given to new ones
+	Deprecated bool      `bson:"deprecated"`
+	CreatedAt  time.Time `bson:"createdAt"`
+	UpdatedAt  time.Time `bson:"updatedAt"`
+	// Name in the language asked by the client, only filled in HTTP responses
+	Label string `bson:"-" json:",omitempty"`
+}
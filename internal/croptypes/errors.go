@@ -0,0 +1,9 @@
+package croptypes
+
+import "errors"
+
+var (
+	ErrCropTypeNotFound      = errors.New("Crop type not found")
+	ErrCropTypeAlreadyExists = errors.New("Crop type already exists")
+	ErrInvalidCropTypeFields = errors.New("invalid crop type fields")
+)
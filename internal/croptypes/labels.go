@@ -0,0 +1,89 @@
+package croptypes
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Picks the label of the most preferred language of an Accept-Language
+// header. Tags match exactly or by their primary language, so pt-BR falls back
+// to a pt label and the other way around, the label of the bare primary
+// language being preferred. Among regional variants, like pt-BR and pt-PT for
+// pt, the first tag in alphabetical order wins, so that every request gets the
+// same label. Reports false when none matches.
+func Localize(labels map[string]string, acceptLanguage string) (string, bool) {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, tag := range preferredLanguages(acceptLanguage) {
+		if label, ok := lookupLabel(labels, keys, tag); ok {
+			return label, true
+		}
+	}
+
+	for _, tag := range preferredLanguages(acceptLanguage) {
+		primary, _, _ := strings.Cut(tag, "-")
+		if label, ok := lookupLabel(labels, keys, primary); ok {
+			return label, true
+		}
+
+		for _, key := range keys {
+			keyPrimary, _, _ := strings.Cut(key, "-")
+			if strings.EqualFold(primary, keyPrimary) {
+				return labels[key], true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// Matches the tag regardless of case, going through the keys in order
+func lookupLabel(labels map[string]string, keys []string, tag string) (string, bool) {
+	for _, key := range keys {
+		if strings.EqualFold(key, tag) {
+			return labels[key], true
+		}
+	}
+
+	return "", false
+}
+
+// Language tags of the header, by decreasing quality
+func preferredLanguages(header string) []string {
+	type language struct {
+		tag     string
+		quality float64
+	}
+
+	var languages []language
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if quality > 0 {
+			languages = append(languages, language{tag: tag, quality: quality})
+		}
+	}
+
+	sort.SliceStable(languages, func(i, j int) bool { return languages[i].quality > languages[j].quality })
+
+	tags := make([]string, len(languages))
+	for i, l := range languages {
+		tags[i] = l.tag
+	}
+	return tags
+}
@@ -0,0 +1,28 @@
+package croptypes
+
+import (
+	"github.com/mateusfdl/go-api/adapters/http"
+	"github.com/mateusfdl/go-api/adapters/logger"
+	"github.com/mateusfdl/go-api/internal/audit"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type CropTypesModule struct {
+	Repository Repository
+	Service    *Service
+	Controller *Controller
+	Catalog    *Catalog
+}
+
+func New(
+	l *logger.Logger,
+	h *http.HTTP,
+	db *mongo.Database,
+	cfg Config,
+) *CropTypesModule {
+	r := NewMongoRepository(db, l, audit.SystemClock)
+	catalog := NewCatalog(l, r, cfg)
+	s := NewService(l, r, catalog)
+	c := NewController(h, s, l)
+	return &CropTypesModule{Repository: r, Service: s, Controller: c, Catalog: catalog}
+}
@@ -0,0 +1,130 @@
+package croptypes
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mateusfdl/go-api/adapters/logger"
+	"github.com/mateusfdl/go-api/internal/audit"
+	"github.com/mateusfdl/go-api/internal/crops"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoRepository struct {
+	db         *mongo.Database
+	l          *logger.Logger
+	timestamps *audit.Timestamps
+}
+
+func NewMongoRepository(db *mongo.Database, l *logger.Logger, clock audit.Clock) *MongoRepository {
+	return &MongoRepository{db: db, l: l, timestamps: audit.NewTimestamps(clock)}
+}
+
+func (r *MongoRepository) Create(ctx context.Context, dto *CreateCropTypeDTO) error {
+	doc := dto.ToMap()
+	r.timestamps.OnCreate(doc)
+
+	if _, err := r.db.Collection("cropTypes").InsertOne(ctx, doc); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrCropTypeAlreadyExists
+		}
+
+		r.l.Error("error on create crop type", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *MongoRepository) List(ctx context.Context) ([]CropType, error) {
+	opts := options.Find().SetSort(bson.M{"_id": 1})
+	cursor, err := r.db.Collection("cropTypes").Find(ctx, bson.M{}, opts)
+	if err != nil {
+		r.l.Error("error on list crop types", err)
+		return nil, err
+	}
+
+	types := []CropType{}
+	if err := cursor.All(ctx, &types); err != nil {
+		return nil, err
+	}
+
+	return types, nil
+}
+
+func (r *MongoRepository) GetByCode(ctx context.Context, code crops.CropType) (*CropType, error) {
+	var t CropType
+	err := r.db.Collection("cropTypes").FindOne(ctx, bson.M{"_id": code}).Decode(&t)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrCropTypeNotFound
+		}
+
+		r.l.Error("error on get crop type", err)
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (r *MongoRepository) Update(ctx context.Context, code crops.CropType, dto *UpdateCropTypeDTO) error {
+	set := dto.ToMap()
+	r.timestamps.OnUpdate(set)
+
+	return r.update(ctx, code, set)
+}
+
+// Flags the crop type as deprecated, it is never removed as crops refer to it
+func (r *MongoRepository) Deprecate(ctx context.Context, code crops.CropType) error {
+	set := bson.M{"deprecated": true}
+	r.timestamps.OnUpdate(set)
+
+	return r.update(ctx, code, set)
+}
+
+func (r *MongoRepository) update(ctx context.Context, code crops.CropType, set bson.M) error {
+	result, err := r.db.Collection("cropTypes").UpdateOne(ctx, bson.M{"_id": code}, bson.M{"$set": set})
+	if err != nil {
+		r.l.Error("error on update crop type", err)
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrCropTypeNotFound
+	}
+
+	return nil
+}
+
+// Inserts the given crop types unless they already exist, leaving the ones
+// edited through the API untouched. Returns how many were inserted.
+func (r *MongoRepository) Seed(ctx context.Context, codes []crops.CropType) (int64, error) {
+	var inserted int64
+	for _, code := range codes {
+		doc := bson.M{"name": displayName(code), "labels": bson.M{}, "deprecated": false}
+		r.timestamps.OnCreate(doc)
+
+		opts := options.Update().SetUpsert(true)
+		result, err := r.db.Collection("cropTypes").UpdateOne(ctx, bson.M{"_id": code}, bson.M{"$setOnInsert": doc}, opts)
+		if err != nil {
+			r.l.Error("error on seed crop types", err)
+			return inserted, err
+		}
+
+		inserted += result.UpsertedCount
+	}
+
+	return inserted, nil
+}
+
+// Name of a seeded crop type, like Corn for CORN
+func displayName(code crops.CropType) string {
+	name := strings.ToLower(strings.ReplaceAll(string(code), "_", " "))
+	if name == "" {
+		return name
+	}
+
+	return strings.ToUpper(name[:1]) + name[1:]
+}
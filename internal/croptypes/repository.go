@@ -0,0 +1,16 @@
+package croptypes
+
+import (
+	"context"
+
+	"github.com/mateusfdl/go-api/internal/crops"
+)
+
+type Repository interface {
+	Create(ctx context.Context, dto *CreateCropTypeDTO) error
+	List(ctx context.Context) ([]CropType, error)
+	GetByCode(ctx context.Context, code crops.CropType) (*CropType, error)
+	Update(ctx context.Context, code crops.CropType, dto *UpdateCropTypeDTO) error
+	Deprecate(ctx context.Context, code crops.CropType) error
+	Seed(ctx context.Context, codes []crops.CropType) (int64, error)
+}
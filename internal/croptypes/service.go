@@ -0,0 +1,126 @@
+package croptypes
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mateusfdl/go-api/adapters/logger"
+	"github.com/mateusfdl/go-api/internal/crops"
+	"github.com/mateusfdl/go-api/internal/validation"
+)
+
+// Codes are upper case identifiers, like SUGARCANE or WINTER_WHEAT
+var codePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]{1,31}$`)
+
+type Service struct {
+	l       *logger.Logger
+	repo    Repository
+	catalog *Catalog
+}
+
+func NewService(l *logger.Logger, repo Repository, catalog *Catalog) *Service {
+	return &Service{l: l, repo: repo, catalog: catalog}
+}
+
+// Seeds the built-in crop types and loads the catalog, meant to run once on
+// start before serving requests
+func (s *Service) Bootstrap(ctx context.Context) error {
+	inserted, err := s.repo.Seed(ctx, crops.CropTypes)
+	if err != nil {
+		return err
+	}
+	if inserted > 0 {
+		s.l.Info("Seeded crop types", "count", inserted)
+	}
+
+	return s.catalog.Load(ctx)
+}
+
+// Served from the cached catalog
+func (s *Service) ListCropTypes(includeDeprecated bool) []CropType {
+	return s.catalog.List(includeDeprecated)
+}
+
+func (s *Service) GetByCode(code crops.CropType) (*CropType, error) {
+	t, ok := s.catalog.Get(code)
+	if !ok {
+		return nil, ErrCropTypeNotFound
+	}
+
+	return &t, nil
+}
+
+func (s *Service) CreateCropType(ctx context.Context, dto *CreateCropTypeDTO) (*CropType, error) {
+	if err := validateFields(dto); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCropTypeFields, err)
+	}
+
+	if err := s.repo.Create(ctx, dto); err != nil {
+		return nil, err
+	}
+
+	return s.reload(ctx, dto.Code)
+}
+
+func (s *Service) UpdateCropType(ctx context.Context, code crops.CropType, dto *UpdateCropTypeDTO) (*CropType, error) {
+	if err := validateUpdateFields(dto); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCropTypeFields, err)
+	}
+
+	if err := s.repo.Update(ctx, code, dto); err != nil {
+		return nil, err
+	}
+
+	return s.reload(ctx, code)
+}
+
+func (s *Service) DeprecateCropType(ctx context.Context, code crops.CropType) (*CropType, error) {
+	if err := s.repo.Deprecate(ctx, code); err != nil {
+		return nil, err
+	}
+
+	return s.reload(ctx, code)
+}
+
+// Refreshes the catalog after a write and returns the stored crop type. A
+// failed refresh is only logged, the next periodic one catches up.
+func (s *Service) reload(ctx context.Context, code crops.CropType) (*CropType, error) {
+	if err := s.catalog.Load(ctx); err != nil {
+		s.l.Error("Failed to reload crop type catalog", err)
+	}
+
+	return s.repo.GetByCode(ctx, code)
+}
+
+func validateFields(dto *CreateCropTypeDTO) error {
+	var errs validation.Errors
+	if dto.Code == "" {
+		errs.Add("code", validation.CodeRequired, "code is required")
+	} else if !codePattern.MatchString(string(dto.Code)) {
+		errs.Add("code", validation.CodeInvalid, "code must be upper case letters, digits and underscores")
+	}
+
+	errs.Required("name", dto.Name)
+	validateLabels(&errs, dto.Labels)
+	return errs.Err()
+}
+
+func validateUpdateFields(dto *UpdateCropTypeDTO) error {
+	var errs validation.Errors
+	errs.Required("name", dto.Name)
+	validateLabels(&errs, dto.Labels)
+	return errs.Err()
+}
+
+func validateLabels(errs *validation.Errors, labels map[string]string) {
+	for tag, label := range labels {
+		if strings.TrimSpace(tag) == "" {
+			errs.Add("labels", validation.CodeInvalid, "label language tags can not be blank")
+			continue
+		}
+
+		errs.Required(validation.Field("labels", tag), label)
+	}
+}
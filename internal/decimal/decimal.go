@@ -0,0 +1,275 @@
+package decimal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrInvalidDecimal = errors.New("invalid decimal")
+
+// Significant digits a Decimal128 can hold
+const maxDigits = 34
+
+// An exact decimal number, stored as a Decimal128 and written in JSON as a
+// plain number, so that 12.5 stays 12.5 all the way down
+type Decimal struct {
+	d primitive.Decimal128
+}
+
+// Parses a plain or scientific decimal number. NaN and infinities are rejected.
+func Parse(s string) (Decimal, error) {
+	d, err := primitive.ParseDecimal128(strings.TrimSpace(s))
+	if err != nil || d.IsNaN() || d.IsInf() != 0 {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+	}
+
+	return Decimal{d: d}, nil
+}
+
+// Panics on invalid input, meant for constants
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func NewFromInt(i int64) Decimal {
+	return fromBigInt(big.NewInt(i), 0)
+}
+
+// Takes the shortest decimal representation of the float, so 0.1 is 0.1.
+// NaN and infinities are rejected.
+func FromFloat(f float64) (Decimal, error) {
+	return Parse(strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+// Panics on NaN and infinities, meant for constants
+func NewFromFloat(f float64) Decimal {
+	d, err := FromFloat(f)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func (d Decimal) parts() (*big.Int, int) {
+	coef, exp, err := d.d.BigInt()
+	if err != nil {
+		// Only NaN and infinities fail, which are never built
+		return new(big.Int), 0
+	}
+
+	return coef, exp
+}
+
+// Rounds the coefficient half away from zero until it fits a Decimal128
+func fromBigInt(coef *big.Int, exp int) Decimal {
+	for len(new(big.Int).Abs(coef).Text(10)) > maxDigits {
+		coef, exp = roundDigit(coef), exp+1
+	}
+
+	d, ok := primitive.ParseDecimal128FromBigInt(coef, exp)
+	if !ok {
+		return Decimal{}
+	}
+
+	return Decimal{d: d}
+}
+
+func roundDigit(coef *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(coef, big.NewInt(10), new(big.Int))
+	if r.CmpAbs(big.NewInt(5)) >= 0 {
+		q.Add(q, big.NewInt(int64(coef.Sign())))
+	}
+
+	return q
+}
+
+func (d Decimal) Sign() int {
+	coef, _ := d.parts()
+	return coef.Sign()
+}
+
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+func (d Decimal) Cmp(other Decimal) int {
+	return d.rat().Cmp(other.rat())
+}
+
+func (d Decimal) Mul(other Decimal) Decimal {
+	a, aexp := d.parts()
+	b, bexp := other.parts()
+	return fromBigInt(new(big.Int).Mul(a, b), aexp+bexp)
+}
+
+func (d Decimal) Add(other Decimal) Decimal {
+	// Zeros may carry extreme exponents, not worth aligning to
+	if d.IsZero() {
+		return other
+	}
+	if other.IsZero() {
+		return d
+	}
+
+	a, aexp := d.parts()
+	b, bexp := other.parts()
+	for aexp > bexp {
+		a, aexp = a.Mul(a, big.NewInt(10)), aexp-1
+	}
+	for bexp > aexp {
+		b, bexp = b.Mul(b, big.NewInt(10)), bexp-1
+	}
+
+	return fromBigInt(a.Add(a, b), aexp)
+}
+
+func (d Decimal) Sub(other Decimal) Decimal {
+	return d.Add(other.Neg())
+}
+
+func (d Decimal) Neg() Decimal {
+	coef, exp := d.parts()
+	return fromBigInt(coef.Neg(coef), exp)
+}
+
+// Number of digits after the decimal point, trailing zeros excluded
+func (d Decimal) Places() int {
+	coef, exp := d.parts()
+	if coef.Sign() == 0 {
+		return 0
+	}
+
+	ten := big.NewInt(10)
+	r := new(big.Int)
+	for exp < 0 {
+		q, _ := new(big.Int).QuoRem(coef, ten, r)
+		if r.Sign() != 0 {
+			break
+		}
+		coef, exp = q, exp+1
+	}
+
+	if exp >= 0 {
+		return 0
+	}
+	return -exp
+}
+
+func (d Decimal) Float64() float64 {
+	f, _ := d.rat().Float64()
+	return f
+}
+
+func (d Decimal) rat() *big.Rat {
+	coef, exp := d.parts()
+	r := new(big.Rat).SetInt(coef)
+	if coef.Sign() == 0 {
+		return r
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(exp))), nil)
+	if exp < 0 {
+		return r.Quo(r, new(big.Rat).SetInt(scale))
+	}
+
+	return r.Mul(r, new(big.Rat).SetInt(scale))
+}
+
+// Plain notation, without exponent nor trailing zeros
+func (d Decimal) String() string {
+	places := d.Places()
+	s := d.rat().FloatString(places)
+	if s == "-0" {
+		return "0"
+	}
+
+	return s
+}
+
+func (d Decimal) Decimal128() primitive.Decimal128 {
+	return d.d
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// Accepts numbers and strings holding a number, the latter for clients that
+// would lose digits going through floats
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+
+	var s string
+	if b[0] == '"' {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+	} else {
+		var n json.Number
+		if err := json.Unmarshal(b, &n); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidDecimal, b)
+		}
+		s = n.String()
+	}
+
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+	return nil
+}
+
+func (d Decimal) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bson.MarshalValue(d.d)
+}
+
+// Also reads the integers and doubles written before land areas were decimals
+func (d *Decimal) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	raw := bson.RawValue{Type: t, Value: data}
+	switch t {
+	case bson.TypeDecimal128:
+		*d = Decimal{d: raw.Decimal128()}
+	case bson.TypeInt32:
+		*d = NewFromInt(int64(raw.Int32()))
+	case bson.TypeInt64:
+		*d = NewFromInt(raw.Int64())
+	case bson.TypeDouble:
+		parsed, err := FromFloat(raw.Double())
+		if err != nil {
+			return err
+		}
+		*d = parsed
+	case bson.TypeNull, bson.TypeUndefined:
+		*d = Decimal{}
+	default:
+		return fmt.Errorf("%w: cannot decode %s", ErrInvalidDecimal, t)
+	}
+
+	return nil
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
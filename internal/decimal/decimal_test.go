@@ -0,0 +1,147 @@
+package decimal_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/mateusfdl/go-api/internal/decimal"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestParse(t *testing.T) {
+	cases := map[string]string{
+		"12.5":   "12.5",
+		"12.50":  "12.5",
+		"-3":     "-3",
+		"1.5E+3": "1500",
+		"0.0001": "0.0001",
+		"0":      "0",
+	}
+
+	for input, want := range cases {
+		d, err := decimal.Parse(input)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", input, err)
+		}
+		if d.String() != want {
+			t.Errorf("Parse(%q) = %s, want %s", input, d, want)
+		}
+	}
+
+	for _, input := range []string{"", "abc", "NaN", "Infinity"} {
+		if _, err := decimal.Parse(input); !errors.Is(err, decimal.ErrInvalidDecimal) {
+			t.Errorf("Parse(%q): expect ErrInvalidDecimal, but got %v", input, err)
+		}
+	}
+}
+
+func TestPlaces(t *testing.T) {
+	cases := map[string]int{"12": 0, "12.5": 1, "12.50": 1, "0.125": 3, "1E+2": 0, "0": 0}
+
+	for input, want := range cases {
+		if got := decimal.MustParse(input).Places(); got != want {
+			t.Errorf("Places(%s) = %d, want %d", input, got, want)
+		}
+	}
+}
+
+func TestMulIsExact(t *testing.T) {
+	got := decimal.MustParse("50").Mul(decimal.NewFromFloat(0.40468564224))
+	if got.String() != "20.234282112" {
+		t.Errorf("Expect 20.234282112, but got %s", got)
+	}
+
+	got = decimal.MustParse("0.1").Mul(decimal.MustParse("3"))
+	if got.Cmp(decimal.MustParse("0.3")) != 0 {
+		t.Errorf("Expect 0.3, but got %s", got)
+	}
+}
+
+func TestAddAndSub(t *testing.T) {
+	cases := []struct{ a, b, sum, diff string }{
+		{"12.5", "0.25", "12.75", "12.25"},
+		{"1E+2", "0.01", "100.01", "99.99"},
+		{"0", "3.5", "3.5", "-3.5"},
+	}
+
+	for _, tt := range cases {
+		a, b := decimal.MustParse(tt.a), decimal.MustParse(tt.b)
+		if got := a.Add(b).String(); got != tt.sum {
+			t.Errorf("%s + %s = %s, want %s", tt.a, tt.b, got, tt.sum)
+		}
+		if got := a.Sub(b).String(); got != tt.diff {
+			t.Errorf("%s - %s = %s, want %s", tt.a, tt.b, got, tt.diff)
+		}
+	}
+
+	var zero decimal.Decimal
+	if got := zero.Add(zero); !got.IsZero() {
+		t.Errorf("Expect zero, but got %s", got)
+	}
+}
+
+func TestMulRoundsToDecimal128Digits(t *testing.T) {
+	third := decimal.MustParse("0.3333333333333333333333333333333333")
+	got := third.Mul(third)
+	if got.String() != "0.1111111111111111111111111111111111" {
+		t.Errorf("Expect product rounded to 34 digits, but got %s", got)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var v struct {
+		A decimal.Decimal `json:"a"`
+		B decimal.Decimal `json:"b"`
+	}
+	if err := json.Unmarshal([]byte(`{"a": 12.50, "b": "0.1"}`), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	b, _ := json.Marshal(v)
+	if string(b) != `{"a":12.5,"b":0.1}` {
+		t.Errorf("Expect numbers, but got %s", b)
+	}
+
+	if err := json.Unmarshal([]byte(`{"a": true}`), &v); err == nil {
+		t.Errorf("Expect booleans to be rejected")
+	}
+}
+
+func TestBSON(t *testing.T) {
+	type doc struct {
+		V decimal.Decimal `bson:"v"`
+	}
+
+	raw, err := bson.Marshal(doc{V: decimal.MustParse("12.5")})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if typ := bson.Raw(raw).Lookup("v").Type; typ != bson.TypeDecimal128 {
+		t.Errorf("Expect Decimal128, but got %s", typ)
+	}
+
+	for _, legacy := range []interface{}{int32(12), int64(12), 12.0} {
+		raw, _ := bson.Marshal(bson.M{"v": legacy})
+		var d doc
+		if err := bson.Unmarshal(raw, &d); err != nil {
+			t.Fatalf("Unmarshal %T failed: %v", legacy, err)
+		}
+		if d.V.String() != "12" {
+			t.Errorf("Expect %T decoded as 12, but got %s", legacy, d.V)
+		}
+	}
+}
+
+func TestBSONRejectsNonFiniteDoubles(t *testing.T) {
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		raw, _ := bson.Marshal(bson.M{"v": f})
+		var d struct {
+			V decimal.Decimal `bson:"v"`
+		}
+		if err := bson.Unmarshal(raw, &d); !errors.Is(err, decimal.ErrInvalidDecimal) {
+			t.Errorf("Expect ErrInvalidDecimal decoding %v, but got %v", f, err)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package farms
+
+type Config struct {
+	TrashRetentionDays   int
+	PurgeIntervalMinutes int
+	// Rejects writes that do not carry an If-Match header
+	RequireIfMatch bool
+	// Decimal places land areas can be given with
+	LandAreaPrecision int
+	// Percent the area enclosed by a boundary may differ from the land area
+	// by, 0 leaving them unchecked
+	BoundaryTolerance int
+}
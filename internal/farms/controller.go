@@ -1,215 +1,628 @@
 package farms
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	http_adapter "github.com/mateusfdl/go-api/adapters/http"
 	"github.com/mateusfdl/go-api/adapters/logger"
 	"github.com/mateusfdl/go-api/internal/crops"
+	"github.com/mateusfdl/go-api/internal/decimal"
+	"github.com/mateusfdl/go-api/internal/geo"
+	"github.com/mateusfdl/go-api/internal/ids"
+	"github.com/mateusfdl/go-api/internal/pagination"
+	"github.com/mateusfdl/go-api/internal/patch"
+	"github.com/mateusfdl/go-api/internal/units"
+	"github.com/mateusfdl/go-api/internal/validation"
 )
 
+const (
+	maxPatchSize    = 1 << 20
+	maxSearchLength = 256
+	maxImportSize   = 64 << 20
+	// Longest an import may take, however steadily its rows come in
+	maxImportDuration = 10 * time.Minute
+)
+
+// Media types of NDJSON imports, under the names they are commonly sent with
+var ndjsonMediaTypes = []string{"application/x-ndjson", "application/ndjson", "application/jsonl"}
+
 type Controller struct {
 	farmService *Service
 	l           *logger.Logger
 	h           *http_adapter.HTTP
+	cropTypes   crops.TypeCatalog
+	cfg         Config
 }
 
-func NewController(h *http_adapter.HTTP, farmService *Service, logger *logger.Logger) *Controller {
-	return &Controller{farmService: farmService, l: logger, h: h}
+func NewController(
+	h *http_adapter.HTTP,
+	farmService *Service,
+	logger *logger.Logger,
+	cropTypes crops.TypeCatalog,
+	cfg Config,
+) *Controller {
+	return &Controller{farmService: farmService, l: logger, h: h, cropTypes: cropTypes, cfg: cfg}
 }
 
 // Register all Farm routes
 func (c *Controller) RegisterRoutes() {
 	c.l.Info("Registering farm routes")
-	c.h.Router.HandleFunc("/farms", c.CreateFarm).Methods("POST").Name("CreateFarm")
-	c.h.Router.HandleFunc("/farms", c.ListFarms).Methods("GET").Name("ListFarms").Queries("skip", "{skip}", "limit", "{limit}")
-	c.h.Router.HandleFunc("/farms/{id}", c.GetFarmByID).Methods("GET").Name("GetFarmByID")
-	c.h.Router.HandleFunc("/farms/{id}", c.UpdateFarm).Methods("PUT").Name("UpdateFarm")
-	c.h.Router.HandleFunc("/farms/{id}", c.DeleteFarm).Methods("DELETE").Name("DeleteFarm")
+	c.registerErrors()
+	c.h.ValidateParam("id", ids.ValidateParam)
+	c.h.Router.HandleFunc("/farms", c.h.Handle(c.CreateFarm)).Methods("POST").Name("CreateFarm")
+	c.h.Router.HandleFunc("/farms", c.h.Handle(c.ListFarms)).Methods("GET").Name("ListFarms")
+	c.h.Router.HandleFunc("/farms/trash", c.h.Handle(c.ListTrash)).Methods("GET").Name("ListTrash")
+	c.h.Router.HandleFunc("/farms/search", c.h.Handle(c.SearchFarms)).Methods("GET").Name("SearchFarms")
+	c.h.Router.HandleFunc("/farms/import", c.h.Handle(c.ImportFarms)).Methods("POST").Name("ImportFarms")
+	c.h.Router.HandleFunc("/farms/export", c.h.Handle(c.ExportFarms)).Methods("GET").Name("ExportFarms")
+	c.h.Router.HandleFunc("/farms/{id}", c.h.Handle(c.GetFarmByID)).Methods("GET").Name("GetFarmByID")
+	c.h.Router.HandleFunc("/farms/{id}", c.h.Handle(c.UpdateFarm)).Methods("PUT").Name("UpdateFarm")
+	c.h.Router.HandleFunc("/farms/{id}", c.h.Handle(c.PatchFarm)).Methods("PATCH").Name("PatchFarm")
+	c.h.Router.HandleFunc("/farms/{id}", c.h.Handle(c.DeleteFarm)).Methods("DELETE").Name("DeleteFarm")
+	c.h.Router.HandleFunc("/farms/{id}/restore", c.h.Handle(c.RestoreFarm)).Methods("POST").Name("RestoreFarm")
+	c.h.Router.HandleFunc("/farms/{id}/utilization", c.h.Handle(c.GetUtilization)).Methods("GET").Name("GetFarmUtilization")
+	c.h.Router.HandleFunc("/stats", c.h.Handle(c.GetStats)).Methods("GET").Name("GetStats")
+}
+
+// Register the HTTP status of every Farm error
+func (c *Controller) registerErrors() {
+	c.h.RegisterError(ErrFarmNotFound, http.StatusNotFound, "FARM_NOT_FOUND")
+	c.h.RegisterError(ErrFarmAlreadyExists, http.StatusConflict, "FARM_ALREADY_EXISTS")
+	c.h.RegisterError(ids.ErrInvalidID, http.StatusBadRequest, "INVALID_ID")
+	c.h.RegisterError(ErrInvalidFarmFields, http.StatusBadRequest, "INVALID_FARM_FIELDS")
+	c.h.RegisterError(ErrInvalidImport, http.StatusBadRequest, "INVALID_IMPORT")
+	c.h.RegisterError(patch.ErrInvalidPatch, http.StatusBadRequest, "INVALID_PATCH")
+	c.h.RegisterError(ErrFarmVersionMismatch, http.StatusPreconditionFailed, "PRECONDITION_FAILED")
+	c.h.RegisterError(ErrPreconditionRequired, http.StatusPreconditionRequired, "PRECONDITION_REQUIRED")
+	c.h.RegisterError(pagination.ErrInvalidCursor, http.StatusBadRequest, "INVALID_CURSOR")
 }
 
-func (c *Controller) CreateFarm(w http.ResponseWriter, r *http.Request) {
+func (c *Controller) CreateFarm(w http.ResponseWriter, r *http.Request) error {
 	var dto CreateFarmDTO
-	err := json.NewDecoder(r.Body).Decode(&dto)
-	if err != nil {
-		c.l.Error("Failed to decode request body")
-		w.WriteHeader(http.StatusBadRequest)
-		return
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		return fmt.Errorf("%w: %v", http_adapter.ErrMalformedBody, err)
 	}
 
 	id, err := c.farmService.CreateFarm(r.Context(), &dto)
-	if errors.Is(err, ErrInvalidFarmFields) {
-		w.WriteHeader(http.StatusBadRequest)
-		return
+	if err != nil {
+		return err
+	}
+
+	return http_adapter.WriteJSON(w, http.StatusCreated, map[string]string{"id": id})
+}
+
+// Creates farms from a CSV or NDJSON body, read as it streams in. Answers a
+// report of every row, even when some of them failed.
+func (c *Controller) ImportFarms(w http.ResponseWriter, r *http.Request) error {
+	var errs validation.Errors
+	dryRun := validation.QueryBool(r.URL.Query(), "dryRun", false, &errs)
+	if err := errs.Err(); err != nil {
+		return fmt.Errorf("%w: %w", http_adapter.ErrInvalidQuery, err)
+	}
+
+	// Unparsable headers leave the media type empty
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	body := http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	var rows RowReader
+	switch {
+	case mediaType == "text/csv":
+		var err error
+		if rows, err = NewCSVRowReader(body); err != nil {
+			return err
+		}
+	case slices.Contains(ndjsonMediaTypes, mediaType):
+		rows = NewNDJSONRowReader(body)
+	default:
+		return fmt.Errorf("%w: expected text/csv or %s", http_adapter.ErrUnsupportedMediaType, ndjsonMediaTypes[0])
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), maxImportDuration)
+	defer cancel()
+	end, _ := ctx.Deadline()
+	rows = newDeadlineRowReader(rows, http.NewResponseController(w), time.Duration(c.h.Timeout)*time.Second, end)
+
+	report, err := c.farmService.ImportFarms(ctx, rows, dryRun)
+	if err != nil {
+		return err
 	}
+
+	return http_adapter.WriteJSON(w, http.StatusOK, report)
+}
+
+// Imports outlast the server timeouts, which suit bounded requests. Rows must
+// instead each come within the server timeout, and the import end within
+// maxImportDuration, so that a client trickling its body can not hold the
+// connection forever. Writers that can not move deadlines are left as they
+// are.
+type deadlineRowReader struct {
+	RowReader
+	rc   *http.ResponseController
+	idle time.Duration
+	end  time.Time
+}
+
+func newDeadlineRowReader(rows RowReader, rc *http.ResponseController, idle time.Duration, end time.Time) RowReader {
+	// Leaves the time to answer the report once the import is cut short
+	_ = rc.SetWriteDeadline(end.Add(idle))
+	return &deadlineRowReader{RowReader: rows, rc: rc, idle: idle, end: end}
+}
+
+func (d *deadlineRowReader) Next() (*ImportRow, error) {
+	deadline := d.end
+	if next := time.Now().Add(d.idle); d.idle > 0 && next.Before(deadline) {
+		deadline = next
+	}
+	_ = d.rc.SetReadDeadline(deadline)
+
+	return d.RowReader.Next()
+}
+
+// Streams every farm matching the listing filters as a file download.
+// Pagination parameters are ignored.
+func (c *Controller) ExportFarms(w http.ResponseWriter, r *http.Request) error {
+	query := r.URL.Query()
+	format := ExportCSV
+	if query.Has("format") {
+		format = ExportFormat(strings.ToLower(query.Get("format")))
+	}
+	if !format.IsValid() {
+		var errs validation.Errors
+		errs.Add("format", validation.CodeInvalid, fmt.Sprintf("format must be one of %v", ExportFormats))
+		return fmt.Errorf("%w: %w", http_adapter.ErrInvalidQuery, errs)
+	}
+
+	dto, unit, err := parseListFarmQuery(query, c.cropTypes)
 	if err != nil {
-		c.l.Error("Failed to create farm", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return err
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	_, err = fmt.Fprintf(w, `{"id": "%v"}`, id)
+	export, err := NewExportWriter(format, w)
 	if err != nil {
-		c.l.Error("Failed to write response")
+		return err
 	}
+
+	// Exports outlast the server write timeout, which only suits bounded
+	// responses. Writers that can not lift it are left as they are.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="farms.%s"`, format.Extension()))
+	err = c.farmService.ExportFarms(r.Context(), dto, export, func(farm *Farm) {
+		farm.ETag = ETag(farm.Version)
+		if unit != nil {
+			farm.ConvertLandArea(*unit, c.cfg.LandAreaPrecision)
+		}
+	})
+	if err != nil {
+		// Errors ahead of the first farm are still answered as problems, later
+		// ones abort the download
+		w.Header().Del("Content-Disposition")
+		return err
+	}
+
+	return nil
 }
 
-func (c *Controller) ListFarms(w http.ResponseWriter, r *http.Request) {
+func (c *Controller) ListFarms(w http.ResponseWriter, r *http.Request) error {
+	dto, unit, err := parseListFarmQuery(r.URL.Query(), c.cropTypes)
+	if err != nil {
+		return err
+	}
+
+	page, err := c.farmService.ListFarms(r.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	for i := range page.Items {
+		page.Items[i].ETag = ETag(page.Items[i].Version)
+		if unit != nil {
+			page.Items[i].ConvertLandArea(*unit, c.cfg.LandAreaPrecision)
+		}
+	}
+
+	return http_adapter.WriteJSONWithHash(w, r, http.StatusOK, page)
+}
 
+func (c *Controller) SearchFarms(w http.ResponseWriter, r *http.Request) error {
 	query := r.URL.Query()
-	skip := query.Get("skip")
-	limit := query.Get("limit")
-	cropType := query.Get("cropType")
-	landArea := query.Get("landArea")
 
-	if skip == "" || limit == "" {
-		c.l.Error("Invalid query parameters")
-		w.WriteHeader(http.StatusBadRequest)
-		return
+	var errs validation.Errors
+	unit := queryUnit(query, "unit", &errs)
+	dto := SearchFarmQuery{
+		Params: pagination.ParseParams(query, &errs),
+		Q:      strings.TrimSpace(query.Get("q")),
+	}
+	errs.Required("q", dto.Q)
+	if len(dto.Q) > maxSearchLength {
+		errs.Add("q", validation.CodeInvalid, fmt.Sprintf("q must have at most %d characters", maxSearchLength))
+	}
+	if err := errs.Err(); err != nil {
+		return fmt.Errorf("%w: %w", http_adapter.ErrInvalidQuery, err)
 	}
 
-	skipInt, err := strconv.Atoi(skip)
+	page, err := c.farmService.SearchFarms(r.Context(), &dto)
 	if err != nil {
-		c.l.Error("Failed to parse skip", err)
-		w.WriteHeader(http.StatusBadRequest)
-		return
+		return err
 	}
 
-	limitInt, err := strconv.Atoi(limit)
+	for i := range page.Items {
+		page.Items[i].ETag = ETag(page.Items[i].Version)
+		if unit != nil {
+			page.Items[i].ConvertLandArea(*unit, c.cfg.LandAreaPrecision)
+		}
+	}
+
+	return http_adapter.WriteJSONWithHash(w, r, http.StatusOK, page)
+}
+
+func (c *Controller) GetFarmByID(w http.ResponseWriter, r *http.Request) error {
+	var errs validation.Errors
+	unit := queryUnit(r.URL.Query(), "unit", &errs)
+	if err := errs.Err(); err != nil {
+		return fmt.Errorf("%w: %w", http_adapter.ErrInvalidQuery, err)
+	}
+
+	farm, err := c.farmService.GetByID(r.Context(), mux.Vars(r)["id"])
 	if err != nil {
-		c.l.Error("Failed to parse limit", err)
-		w.WriteHeader(http.StatusBadRequest)
-		return
+		return err
 	}
 
-	landAreaInt, err := strconv.Atoi(landArea)
-	if err != nil && landArea != "" {
-		c.l.Error("Failed to parse landArea", err)
-		w.WriteHeader(http.StatusBadRequest)
-		return
+	farm.ETag = ETag(farm.Version)
+	if unit != nil {
+		farm.ConvertLandArea(*unit, c.cfg.LandAreaPrecision)
 	}
+	return http_adapter.WriteJSONWithETag(w, r, http.StatusOK, farm.ETag, farm)
+}
 
-	dto := ListFarmQuery{
-		Skip:     skipInt,
-		Limit:    limitInt,
-		LandArea: int64(landAreaInt),
-		CropType: crops.CropType(cropType),
+func (c *Controller) UpdateFarm(w http.ResponseWriter, r *http.Request) error {
+	versions, err := c.ifMatch(r)
+	if err != nil {
+		return err
 	}
 
-	farms, err := c.farmService.ListFarms(r.Context(), &dto)
+	var dto UpdateFarmDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		return fmt.Errorf("%w: %v", http_adapter.ErrMalformedBody, err)
+	}
+
+	farm, err := c.farmService.UpdateFarm(r.Context(), mux.Vars(r)["id"], &dto, versions)
 	if err != nil {
-		c.l.Error("Failed to list farms", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return err
 	}
 
-	// Default value for empty farms
-	if len(farms) == 0 {
-		farms = []Farm{}
+	return writeFarm(w, farm)
+}
+
+// Partially updates the farm with a JSON Merge Patch (RFC 7396)
+func (c *Controller) PatchFarm(w http.ResponseWriter, r *http.Request) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (mediaType != patch.MergePatchContentType && mediaType != "application/json") {
+		return fmt.Errorf("%w: expected %s", http_adapter.ErrUnsupportedMediaType, patch.MergePatchContentType)
 	}
 
-	response, err := json.Marshal(farms)
+	versions, err := c.ifMatch(r)
 	if err != nil {
-		c.l.Error("Failed to marshal response", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return err
 	}
 
-	w.WriteHeader(http.StatusOK)
-	_, err = w.Write(response)
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPatchSize))
 	if err != nil {
-		c.l.Error("Failed to write response", err)
+		return fmt.Errorf("%w: %v", http_adapter.ErrMalformedBody, err)
 	}
+
+	farm, err := c.farmService.PatchFarm(r.Context(), mux.Vars(r)["id"], body, versions)
+	if err != nil {
+		return err
+	}
+
+	return writeFarm(w, farm)
 }
 
-func (c *Controller) GetFarmByID(w http.ResponseWriter, r *http.Request) {
-	id := mux.Vars(r)["id"]
-	if id == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		return
+func (c *Controller) DeleteFarm(w http.ResponseWriter, r *http.Request) error {
+	var errs validation.Errors
+
+	// Farms are removed permanently unless they are sent to the trash
+	soft := validation.QueryBool(r.URL.Query(), "soft", false, &errs)
+	if err := errs.Err(); err != nil {
+		return fmt.Errorf("%w: %w", http_adapter.ErrInvalidQuery, err)
+	}
+
+	versions, err := c.ifMatch(r)
+	if err != nil {
+		return err
+	}
+
+	result, err := c.farmService.DeleteFarm(r.Context(), mux.Vars(r)["id"], soft, versions)
+	if err != nil {
+		return err
 	}
 
-	farm, err := c.farmService.GetByID(r.Context(), id)
-	if errors.Is(err, ErrFarmNotFound) {
-		w.WriteHeader(http.StatusNotFound)
-		return
+	return http_adapter.WriteJSON(w, http.StatusOK, result)
+}
+
+func (c *Controller) ListTrash(w http.ResponseWriter, r *http.Request) error {
+	query := r.URL.Query()
+
+	var errs validation.Errors
+	dto := ListFarmQuery{Params: pagination.ParseParams(query, &errs)}
+	if err := errs.Err(); err != nil {
+		return fmt.Errorf("%w: %w", http_adapter.ErrInvalidQuery, err)
 	}
+
+	page, err := c.farmService.ListTrash(r.Context(), &dto)
 	if err != nil {
-		c.l.Error("Failed to get farm", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return err
 	}
 
-	response, err := json.Marshal(farm)
+	return http_adapter.WriteJSON(w, http.StatusOK, page)
+}
+
+func (c *Controller) RestoreFarm(w http.ResponseWriter, r *http.Request) error {
+	result, err := c.farmService.RestoreFarm(r.Context(), mux.Vars(r)["id"])
 	if err != nil {
-		c.l.Error("Failed to marshal response", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return err
 	}
 
-	w.WriteHeader(http.StatusOK)
-	_, err = w.Write(response)
+	return http_adapter.WriteJSON(w, http.StatusOK, result)
+}
+
+func (c *Controller) GetUtilization(w http.ResponseWriter, r *http.Request) error {
+	var errs validation.Errors
+	unit := queryUnit(r.URL.Query(), "unit", &errs)
+	if err := errs.Err(); err != nil {
+		return fmt.Errorf("%w: %w", http_adapter.ErrInvalidQuery, err)
+	}
+
+	utilization, err := c.farmService.GetUtilization(r.Context(), mux.Vars(r)["id"], unit)
 	if err != nil {
-		c.l.Error("Failed to write response", err)
+		return err
 	}
+
+	return http_adapter.WriteJSON(w, http.StatusOK, utilization)
 }
 
-func (c *Controller) UpdateFarm(w http.ResponseWriter, r *http.Request) {
-	var dto UpdateFarmDTO
-	err := json.NewDecoder(r.Body).Decode(&dto)
+// Takes the same filters as the listing, pagination and sorting aside
+func (c *Controller) GetStats(w http.ResponseWriter, r *http.Request) error {
+	dto, unit, err := parseListFarmQuery(r.URL.Query(), c.cropTypes)
+	if err != nil {
+		return err
+	}
+
+	stats, err := c.farmService.GetStats(r.Context(), dto, unit)
 	if err != nil {
-		c.l.Error("Failed to decode request body")
-		w.WriteHeader(http.StatusBadRequest)
-		return
+		return err
 	}
 
-	id := mux.Vars(r)["id"]
-	if id == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		return
+	return http_adapter.WriteJSON(w, http.StatusOK, stats)
+}
+
+// Reads the listing filters from the query string, along with the unit the
+// land areas are expressed in, both in the bounds and in the response. landArea
+// is kept as an alias of landAreaMin for older clients. Deprecated crop types
+// are accepted, as farms may still grow them.
+func parseListFarmQuery(query url.Values, cropTypes crops.TypeCatalog) (*ListFarmQuery, *units.Unit, error) {
+	var errs validation.Errors
+	unit := queryUnit(query, "unit", &errs)
+	dto := &ListFarmQuery{
+		Params:            pagination.ParseParams(query, &errs),
+		LandAreaMin:       queryDecimal(query, "landArea", &errs),
+		UnitOfMeasurement: queryUnit(query, "unitOfMeasurement", &errs),
+		IsIrrigated:       queryOptionalBool(query, "isIrrigated", &errs),
+		IsInsured:         queryOptionalBool(query, "isInsured", &errs),
+		City:              strings.TrimSpace(query.Get("city")),
+		State:             strings.TrimSpace(query.Get("state")),
+		Sort:              pagination.ParseSort(query.Get("sort"), SortableFields, &errs),
+	}
+
+	if query.Has("landAreaMin") {
+		dto.LandAreaMin = queryDecimal(query, "landAreaMin", &errs)
+	}
+	dto.LandAreaMax = queryDecimal(query, "landAreaMax", &errs)
+	if !dto.LandAreaMax.IsZero() && dto.LandAreaMin.Cmp(dto.LandAreaMax) > 0 {
+		errs.Add("landAreaMin", validation.CodeInvalid, "landAreaMin must not be greater than landAreaMax")
+	}
+	if unit != nil {
+		dto.LandAreaMin = unit.HectaresOf(dto.LandAreaMin)
+		dto.LandAreaMax = unit.HectaresOf(dto.LandAreaMax)
+	}
+
+	for _, t := range queryList(query, "cropType") {
+		cropType := crops.CropType(t)
+		if !cropTypes.IsKnown(cropType) {
+			errs.Add("cropType", validation.CodeInvalid, "invalid crop type "+t)
+			continue
+		}
+		dto.CropTypes = append(dto.CropTypes, cropType)
+	}
+
+	if from, ok := queryTime(query, "createdFrom", &errs); ok {
+		dto.CreatedFrom = from
+	}
+	if to, ok := queryTime(query, "createdTo", &errs); ok {
+		// A date includes the whole day, a date-time only up to its millisecond
+		if len(query.Get("createdTo")) == len(time.DateOnly) {
+			dto.CreatedBefore = to.AddDate(0, 0, 1)
+		} else {
+			dto.CreatedBefore = to.Add(time.Millisecond)
+		}
 	}
 
-	_, err = c.farmService.UpdateFarm(r.Context(), id, &dto)
-	if errors.Is(err, ErrInvalidFarmFields) {
-		w.WriteHeader(http.StatusBadRequest)
-		return
+	parseGeoQuery(query, dto, &errs)
+
+	if err := errs.Err(); err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", http_adapter.ErrInvalidQuery, err)
 	}
-	if errors.Is(err, ErrFarmNotFound) {
-		w.WriteHeader(http.StatusNotFound)
-		return
+
+	return dto, unit, nil
+}
+
+// Reads near=lat,lng along with its radiusKm, and within, a polygon of
+// lat,lng pairs separated by semicolons or a GeoJSON polygon
+func parseGeoQuery(query url.Values, dto *ListFarmQuery, errs *validation.Errors) {
+	if value := query.Get("near"); value != "" {
+		near, err := geo.ParseLatLng(value)
+		if err != nil {
+			errs.Add("near", validation.CodeInvalid, err.Error())
+		} else {
+			dto.Near = &near
+		}
+
+		radius, err := strconv.ParseFloat(query.Get("radiusKm"), 64)
+		if err != nil || radius <= 0 {
+			errs.Add("radiusKm", validation.CodeInvalid, "radiusKm must be a positive number of kilometers")
+		} else {
+			dto.RadiusKm = radius
+		}
+	} else if query.Has("radiusKm") {
+		errs.Add("radiusKm", validation.CodeInvalid, "radiusKm requires near")
+	}
+
+	if value := query.Get("within"); value != "" {
+		within, err := geo.ParsePolygon(value)
+		if err != nil {
+			errs.Add("within", validation.CodeInvalid, err.Error())
+		} else {
+			dto.Within = &within
+		}
 	}
+}
+
+// Versions accepted by the If-Match header, nil when any version is. Tags that
+// are not farm versions never match, so they answer 412.
+func (c *Controller) ifMatch(r *http.Request) ([]int64, error) {
+	header := r.Header.Get(http_adapter.IfMatchHeader)
+	if header == "" {
+		if c.cfg.RequireIfMatch {
+			return nil, ErrPreconditionRequired
+		}
+
+		return nil, nil
+	}
+
+	versions := []int64{}
+	for _, tag := range http_adapter.ParseETags(header) {
+		if tag == "*" {
+			return nil, nil
+		}
+
+		if v, ok := parseETag(tag); ok {
+			versions = append(versions, v)
+		}
+	}
+
+	return versions, nil
+}
+
+// Writes the farm after a write, tagged with its new version
+func writeFarm(w http.ResponseWriter, farm *Farm) error {
+	farm.ETag = ETag(farm.Version)
+	w.Header().Set(http_adapter.ETagHeader, farm.ETag)
+	return http_adapter.WriteJSON(w, http.StatusOK, farm)
+}
+
+// Entity tag of the given farm version
+func ETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// If-Match uses the strong comparison, so weak tags never match
+func parseETag(tag string) (int64, bool) {
+	unquoted, err := strconv.Unquote(tag)
+	if err != nil || !strings.HasPrefix(tag, `"`) {
+		return 0, false
+	}
+
+	v, err := strconv.ParseInt(unquoted, 10, 64)
 	if err != nil {
-		c.l.Error("Failed to update farm", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return 0, false
 	}
 
-	w.WriteHeader(http.StatusOK)
+	return v, true
 }
 
-func (c *Controller) DeleteFarm(w http.ResponseWriter, r *http.Request) {
-	id := mux.Vars(r)["id"]
-	if id == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		return
+// Parses an optional boolean query parameter that is nil when absent
+func queryOptionalBool(query url.Values, name string, errs *validation.Errors) *bool {
+	if query.Get(name) == "" {
+		return nil
 	}
 
-	err := c.farmService.DeleteFarm(r.Context(), id)
-	if errors.Is(err, ErrFarmNotFound) {
-		w.WriteHeader(http.StatusNotFound)
-		return
+	b := validation.QueryBool(query, name, false, errs)
+	return &b
+}
+
+// Collects the values of a query parameter given either repeated or comma
+// separated
+func queryList(query url.Values, name string) []string {
+	var values []string
+	for _, v := range query[name] {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
 	}
+
+	return values
+}
+
+// Parses an optional RFC 3339 date-time or date query parameter
+func queryTime(query url.Values, name string, errs *validation.Errors) (time.Time, bool) {
+	value := query.Get(name)
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+
+	errs.Add(name, validation.CodeInvalid, name+" must be a date or an RFC 3339 date-time")
+	return time.Time{}, false
+}
+
+// Parses an optional non negative decimal query parameter
+func queryDecimal(query url.Values, name string, errs *validation.Errors) decimal.Decimal {
+	value := query.Get(name)
+	if value == "" {
+		return decimal.Decimal{}
+	}
+
+	d, err := decimal.Parse(value)
+	if err != nil || d.Sign() < 0 {
+		errs.Add(name, validation.CodeInvalid, name+" must be a non negative number")
+		return decimal.Decimal{}
+	}
+
+	return d
+}
+
+// Parses an optional area unit query parameter, nil when absent
+func queryUnit(query url.Values, name string, errs *validation.Errors) *units.Unit {
+	value := query.Get(name)
+	if value == "" {
+		return nil
+	}
+
+	u, err := units.Default.Lookup(value)
 	if err != nil {
-		c.l.Error("Failed to delete farm", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		errs.Add(name, validation.CodeInvalid, name+" must be one of "+strings.Join(units.Default.Symbols(), ", "))
+		return nil
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	return &u
 }
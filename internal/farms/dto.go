@@ -1,58 +1,278 @@
 package farms
 
-import "github.com/mateusfdl/go-api/internal/crops"
+import (
+	"time"
 
+	"github.com/mateusfdl/go-api/internal/address"
+	"github.com/mateusfdl/go-api/internal/crops"
+	"github.com/mateusfdl/go-api/internal/decimal"
+	"github.com/mateusfdl/go-api/internal/geo"
+	"github.com/mateusfdl/go-api/internal/pagination"
+	"github.com/mateusfdl/go-api/internal/units"
+	"github.com/mateusfdl/go-api/internal/validation"
+)
+
+// Full representation of the farm fields, used to replace them
 type UpdateFarmDTO struct {
-	Name              string `json:"name"`
-	Address           string `json:"address"`
-	LandArea          int64  `json:"landArea"`
-	UnitOfMeasurement string `json:"unitOfMeasurement"`
+	Name              string           `json:"name"`
+	Address           address.Input    `json:"address"`
+	LandArea          *decimal.Decimal `json:"landArea"`
+	UnitOfMeasurement string           `json:"unitOfMeasurement"`
+	Location          *geo.Point       `json:"location"`
+	Boundary          *geo.Polygon     `json:"boundary"`
 }
 
 type CreateFarmDTO struct {
 	Name              string                 `json:"name"`
-	Address           string                 `json:"address"`
-	LandArea          int64                  `json:"landArea"`
+	Address           address.Input          `json:"address"`
+	LandArea          *decimal.Decimal       `json:"landArea"`
 	UnitOfMeasurement string                 `json:"unitOfMeasurement"`
+	Location          *geo.Point             `json:"location"`
+	Boundary          *geo.Polygon           `json:"boundary"`
 	Crops             *[]crops.CreateCropDTO `json:"crops"`
 }
 
+// Filters of the farm listing, zero values matching every farm
 type ListFarmQuery struct {
-	Skip     int            `json:"skip"`
-	Limit    int            `json:"limit"`
-	LandArea int64          `json:"landArea"`
-	CropType crops.CropType `json:"cropType"`
+	pagination.Params
+	// Bounds in hectares
+	LandAreaMin       decimal.Decimal
+	LandAreaMax       decimal.Decimal
+	UnitOfMeasurement *units.Unit
+	// Created at or after
+	CreatedFrom time.Time
+	// Created strictly before
+	CreatedBefore time.Time
+	// Matched regardless of case against the parts of the address
+	City  string
+	State string
+	// Farms having at least one crop matching every crop filter
+	CropTypes   []crops.CropType
+	IsIrrigated *bool
+	IsInsured   *bool
+	// Farms located within RadiusKm of Near
+	Near     *geo.Point
+	RadiusKm float64
+	// Farms located within the polygon
+	Within *geo.Polygon
+	// Tiebreaker excluded, nil sorting newest first
+	Sort []pagination.SortKey
+}
+
+type SearchFarmQuery struct {
+	pagination.Params
+	Q string
+}
+
+type DeleteFarmResult struct {
+	ID      string           `json:"id"`
+	Soft    bool             `json:"soft"`
+	Deleted map[string]int64 `json:"deleted"`
+}
+
+// How much of the land area of a farm is planted. Areas are in Unit.
+type Utilization struct {
+	FarmID        string  `json:"farmId"`
+	Unit          string  `json:"unit"`
+	LandArea      float64 `json:"landArea"`
+	PlantedArea   float64 `json:"plantedArea"`
+	AvailableArea float64 `json:"availableArea"`
+	// Share of the land area that is planted, in percent
+	PlantedPercentage float64               `json:"plantedPercentage"`
+	CropTypes         []CropTypeUtilization `json:"cropTypes"`
+}
+
+type CropTypeUtilization struct {
+	Type              crops.CropType `json:"type"`
+	Crops             int            `json:"crops"`
+	PlantedArea       float64        `json:"plantedArea"`
+	PlantedPercentage float64        `json:"plantedPercentage"`
+}
+
+// Figures over the farms matching the listing filters. Areas are in Unit and
+// percentages go from 0 to 100.
+type Stats struct {
+	Unit            string  `json:"unit"`
+	TotalFarms      int     `json:"totalFarms"`
+	TotalLandArea   float64 `json:"totalLandArea"`
+	AverageLandArea float64 `json:"averageLandArea"`
+	TotalCrops      int     `json:"totalCrops"`
+	// Shares of the crops that are irrigated and insured
+	IrrigatedPercentage float64         `json:"irrigatedPercentage"`
+	InsuredPercentage   float64         `json:"insuredPercentage"`
+	CropTypes           []CropTypeStats `json:"cropTypes"`
+	Units               []UnitStats     `json:"units"`
+}
+
+type CropTypeStats struct {
+	Type  crops.CropType `json:"type"`
+	Crops int            `json:"crops"`
+	Farms int            `json:"farms"`
+	// Share of the crops having the type
+	Percentage float64 `json:"percentage"`
+}
+
+// Farms given their land area in a unit
+type UnitStats struct {
+	Unit  string `json:"unit"`
+	Farms int    `json:"farms"`
+	// Land area of the farms in their own unit, and in the unit of the stats
+	LandArea          float64 `json:"landArea"`
+	ConvertedLandArea float64 `json:"convertedLandArea"`
+	// Share of the farms given their land area in the unit
+	Percentage float64 `json:"percentage"`
+}
+
+type ImportStatus string
+
+const (
+	ImportCreated ImportStatus = "created"
+	// Would be created, in dry runs
+	ImportValid   ImportStatus = "valid"
+	ImportInvalid ImportStatus = "invalid"
+	// Valid, but could not be written
+	ImportFailed ImportStatus = "failed"
+)
+
+// Outcome of an import, row by row
+type ImportReport struct {
+	DryRun  bool `json:"dryRun"`
+	Rows    int  `json:"rows"`
+	Created int  `json:"created"`
+	Valid   int  `json:"valid"`
+	Invalid int  `json:"invalid"`
+	Failed  int  `json:"failed"`
+	// Set when the input could not be read to its end or the import was
+	// cancelled, the rows after it being left out
+	Error   string            `json:"error,omitempty"`
+	Results []ImportRowResult `json:"results"`
+}
+
+type ImportRowResult struct {
+	Line     int               `json:"line"`
+	Status   ImportStatus      `json:"status"`
+	ID       string            `json:"id,omitempty"`
+	Error    string            `json:"error,omitempty"`
+	Problems validation.Errors `json:"problems,omitempty"`
+}
+
+type RestoreFarmResult struct {
+	ID       string           `json:"id"`
+	Restored map[string]int64 `json:"restored"`
+}
+
+// Builds the replacement DTO matching the current state of the farm
+func NewUpdateFarmDTO(farm *Farm) *UpdateFarmDTO {
+	landArea := farm.LandArea
+	return &UpdateFarmDTO{
+		Name:              farm.Name,
+		Address:           farmAddress(farm),
+		LandArea:          &landArea,
+		UnitOfMeasurement: farm.UnitOfMeasurement,
+		Location:          farm.Location,
+		Boundary:          farm.Boundary,
+	}
+}
+
+// The address the way the farm was given it: as its parts when the line is
+// written from them, as the line otherwise
+func farmAddress(farm *Farm) address.Input {
+	if farm.StructuredAddress != nil && farm.StructuredAddress.Format() == farm.Address {
+		return address.FromParts(*farm.StructuredAddress)
+	}
+
+	return address.FromLine(farm.Address)
 }
 
+// Fields mapped to nil are removed from the document
 func (dto *UpdateFarmDTO) ToMap() map[string]interface{} {
 	m := make(map[string]interface{})
 
-	if dto.Name != "" {
-		m["name"] = dto.Name
-	}
+	m["name"] = dto.Name
+	m["unitOfMeasurement"] = dto.UnitOfMeasurement
 
-	if dto.Address != "" {
-		m["address"] = dto.Address
+	line, parts := dto.Address.Resolve()
+	m["address"] = line
+	m["structuredAddress"] = nil
+	if parts != nil {
+		m["structuredAddress"] = *parts
 	}
 
-	if dto.LandArea != 0 {
-		m["landArea"] = dto.LandArea
+	if dto.LandArea != nil {
+		m["landArea"] = *dto.LandArea
+		m["landAreaHectares"] = landAreaHectares(*dto.LandArea, dto.UnitOfMeasurement)
+	} else {
+		m["landArea"] = nil
+		m["landAreaHectares"] = nil
 	}
 
-	if dto.UnitOfMeasurement != "" {
-		m["unitOfMeasurement"] = dto.UnitOfMeasurement
+	m["location"] = nil
+	if dto.Location != nil {
+		m["location"] = *dto.Location
+	}
+	m["boundary"] = nil
+	m["boundaryAreaHectares"] = nil
+	if dto.Boundary != nil {
+		m["boundary"] = *dto.Boundary
+		m["boundaryAreaHectares"] = boundaryAreaHectares(*dto.Boundary)
 	}
 
 	return m
 }
 
+// The farm fields of the creation, validated the same way as a replacement
+func (dto *CreateFarmDTO) farmFields() *UpdateFarmDTO {
+	fields := &UpdateFarmDTO{
+		Name:              dto.Name,
+		Address:           dto.Address,
+		LandArea:          dto.LandArea,
+		UnitOfMeasurement: dto.UnitOfMeasurement,
+		Location:          dto.Location,
+		Boundary:          dto.Boundary,
+	}
+
+	return fields
+}
+
 func (dto *CreateFarmDTO) ToMap() map[string]interface{} {
 	m := make(map[string]interface{})
 
 	m["name"] = dto.Name
-	m["address"] = dto.Address
+	line, parts := dto.Address.Resolve()
+	m["address"] = line
+	if parts != nil {
+		m["structuredAddress"] = *parts
+	}
 	m["landArea"] = dto.LandArea
+	m["landAreaHectares"] = nil
+	if dto.LandArea != nil {
+		m["landAreaHectares"] = landAreaHectares(*dto.LandArea, dto.UnitOfMeasurement)
+	}
 	m["unitOfMeasurement"] = dto.UnitOfMeasurement
+	// Geometries are only stored when given
+	if dto.Location != nil {
+		m["location"] = *dto.Location
+	}
+	if dto.Boundary != nil {
+		m["boundary"] = *dto.Boundary
+		m["boundaryAreaHectares"] = boundaryAreaHectares(*dto.Boundary)
+	}
+	m["version"] = 1
 
 	return m
 }
+
+// Normalized land area, nil for units that are not registered
+func landAreaHectares(landArea decimal.Decimal, unit string) interface{} {
+	u, err := units.Default.Lookup(unit)
+	if err != nil {
+		return nil
+	}
+
+	return u.HectaresOf(landArea)
+}
+
+// Area enclosed by the boundary, rounded to the square meter
+func boundaryAreaHectares(boundary geo.Polygon) float64 {
+	return units.Round(boundary.AreaHectares(), 4)
+}
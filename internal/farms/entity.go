@@ -3,16 +3,78 @@ package farms
 import (
 	"time"
 
+	"github.com/mateusfdl/go-api/internal/address"
 	"github.com/mateusfdl/go-api/internal/crops"
+	"github.com/mateusfdl/go-api/internal/decimal"
+	"github.com/mateusfdl/go-api/internal/geo"
+	"github.com/mateusfdl/go-api/internal/units"
 )
 
 type Farm struct {
-	ID                string       `bson:"_id"`
-	Name              string       `bson:"name"`
-	Address           string       `bson:"address"`
-	LandArea          int64        `bson:"landArea"`
-	UnitOfMeasurement string       `bson:"unitOfMeasurement"`
-	Crops             []crops.Crop `bson:"crops"`
-	CreatedAt         time.Time    `bson:"createdAt"`
-	UpdatedAt         time.Time    `bson:"updatedAt"`
+	ID      string `bson:"_id"`
+	Name    string `bson:"name"`
+	Address string `bson:"address"`
+	// Parts of the address, parsed from the line when given as such
+	StructuredAddress *address.Address `bson:"structuredAddress,omitempty"`
+	LandArea          decimal.Decimal  `bson:"landArea"`
+	UnitOfMeasurement string           `bson:"unitOfMeasurement"`
+	// Land area normalized to hectares, compared by filters and sorting
+	LandAreaHectares decimal.Decimal `bson:"landAreaHectares"`
+	// Optional GeoJSON point and boundary, both covered by 2dsphere indexes
+	Location *geo.Point   `bson:"location,omitempty"`
+	Boundary *geo.Polygon `bson:"boundary,omitempty"`
+	// Area enclosed by the boundary
+	BoundaryAreaHectares *float64     `bson:"boundaryAreaHectares,omitempty"`
+	Crops                []crops.Crop `bson:"crops"`
+	CreatedAt            time.Time    `bson:"createdAt"`
+	UpdatedAt            time.Time    `bson:"updatedAt"`
+	DeletedAt            *time.Time   `bson:"deletedAt,omitempty"`
+	// Incremented on every write, backs the optimistic concurrency control
+	Version int64 `bson:"version"`
+	// Entity tag of the version, only filled in HTTP responses
+	ETag string `bson:"-" json:",omitempty"`
+	// Land area in the unit requested by the client, only filled in HTTP responses
+	ConvertedLandArea *units.Area `bson:"-" json:",omitempty"`
+}
+
+// Expresses the land area in the given unit, rounded to the given places
+func (f *Farm) ConvertLandArea(u units.Unit, places int) {
+	f.ConvertedLandArea = &units.Area{
+		Value: units.Round(u.FromHectares(f.LandAreaHectares.Float64()), places),
+		Unit:  u.Symbol,
+	}
+}
+
+// A farm found by a text search
+type SearchResult struct {
+	Farm  `bson:",inline"`
+	Score float64 `bson:"score"`
+	// Searched fields with their matching words highlighted
+	Highlights map[string]string `bson:"-"`
+}
+
+// Sums over the farms matching a listing filter and their crops
+type FarmTotals struct {
+	Farms            int                  `bson:"farms"`
+	LandAreaHectares decimal.Decimal      `bson:"landAreaHectares"`
+	Crops            int                  `bson:"crops"`
+	Irrigated        int                  `bson:"irrigated"`
+	Insured          int                  `bson:"insured"`
+	CropTypes        []CropTypeTotals     `bson:"cropTypes"`
+	Units            []LandAreaUnitTotals `bson:"units"`
+}
+
+type CropTypeTotals struct {
+	Type  crops.CropType `bson:"_id"`
+	Crops int            `bson:"crops"`
+	// Farms growing the type
+	Farms int `bson:"farms"`
+}
+
+// Farms given their land area in a unit, as named by them
+type LandAreaUnitTotals struct {
+	Unit             string          `bson:"_id"`
+	Farms            int             `bson:"farms"`
+	LandArea         decimal.Decimal `bson:"landArea"`
+	LandAreaHectares decimal.Decimal `bson:"landAreaHectares"`
 }
@@ -7,4 +7,14 @@ var (
 	ErrFarmAlreadyExists = errors.New("Farm already exists")
 	ErrOnConvertObjectID = errors.New("failed to convert to ObjectID")
 	ErrInvalidFarmFields = errors.New("invalid farm fields")
+	ErrOnPersistCrops    = errors.New("failed to bulk persist crops")
+	ErrInvalidImport     = errors.New("invalid import")
+	// Rows of an import that can not be decoded, reported without the
+	// decoder message
+	ErrMalformedImportRow = errors.New("malformed row")
+	// Reported in place of the driver error, wrapped in ErrInvalidFarmFields
+	ErrUnindexableGeometry = errors.New("location or boundary can not be indexed, e.g. a self intersecting ring")
+
+	ErrFarmVersionMismatch  = errors.New("Farm was modified by another request")
+	ErrPreconditionRequired = errors.New("If-Match header is required")
 )
@@ -0,0 +1,254 @@
+package farms
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/mateusfdl/go-api/internal/address"
+	"github.com/mateusfdl/go-api/internal/crops"
+)
+
+// Formats farms can be exported in
+type ExportFormat string
+
+const (
+	ExportCSV     ExportFormat = "csv"
+	ExportNDJSON  ExportFormat = "ndjson"
+	ExportGeoJSON ExportFormat = "geojson"
+)
+
+var ExportFormats = []ExportFormat{ExportCSV, ExportNDJSON, ExportGeoJSON}
+
+func (f ExportFormat) IsValid() bool {
+	switch f {
+	case ExportCSV, ExportNDJSON, ExportGeoJSON:
+		return true
+	}
+
+	return false
+}
+
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportNDJSON:
+		return "application/x-ndjson"
+	case ExportGeoJSON:
+		return "application/geo+json"
+	}
+
+	return "text/csv; charset=utf-8"
+}
+
+// Extension of exported files
+func (f ExportFormat) Extension() string {
+	if f == ExportGeoJSON {
+		return "geojson"
+	}
+
+	return string(f)
+}
+
+// Writes exported farms one at a time. Nothing is written before the first
+// farm or Close, so that failures ahead of them can still be answered as
+// errors.
+type ExportWriter interface {
+	Write(farm *Farm) error
+	// Ends the export, writing the header even when there was no farm
+	Close() error
+}
+
+func NewExportWriter(format ExportFormat, w io.Writer) (ExportWriter, error) {
+	switch format {
+	case ExportCSV:
+		return &csvExportWriter{w: csv.NewWriter(w)}, nil
+	case ExportNDJSON:
+		return &ndjsonExportWriter{enc: json.NewEncoder(w)}, nil
+	case ExportGeoJSON:
+		return &geoJSONExportWriter{w: w}, nil
+	}
+
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+// Columns of CSV exports. Farms take a row per crop, the farm columns being
+// repeated on each, and a single row with empty crop columns when they have
+// none.
+var (
+	CSVExportFarmColumns = []string{
+		"id", "name", "address", "street", "number", "district", "city", "state", "country", "postalCode",
+		"landArea", "unitOfMeasurement", "landAreaHectares", "latitude", "longitude", "createdAt", "updatedAt",
+	}
+	CSVExportCropColumns = []string{
+		"cropId", "cropType", "cropIsIrrigated", "cropIsInsured", "cropPlantedArea", "cropUnitOfMeasurement",
+		"cropSeason", "cropStatus",
+	}
+)
+
+type csvExportWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func (c *csvExportWriter) writeHeader() error {
+	if c.wroteHeader {
+		return nil
+	}
+
+	c.wroteHeader = true
+	return c.w.Write(append(append([]string{}, CSVExportFarmColumns...), CSVExportCropColumns...))
+}
+
+func (c *csvExportWriter) Write(farm *Farm) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	columns := csvFarmColumns(farm)
+	if len(farm.Crops) == 0 {
+		if err := c.w.Write(append(columns, make([]string, len(CSVExportCropColumns))...)); err != nil {
+			return err
+		}
+	}
+
+	for i := range farm.Crops {
+		row := append(columns[:len(columns):len(columns)], csvCropColumns(&farm.Crops[i])...)
+		if err := c.w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	// Rows are buffered until flushed, which is when write errors show up
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvExportWriter) Close() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func csvFarmColumns(farm *Farm) []string {
+	var parts address.Address
+	if farm.StructuredAddress != nil {
+		parts = *farm.StructuredAddress
+	}
+
+	var lat, lng string
+	if farm.Location != nil {
+		lat = strconv.FormatFloat(farm.Location.Lat(), 'f', -1, 64)
+		lng = strconv.FormatFloat(farm.Location.Lng(), 'f', -1, 64)
+	}
+
+	return []string{
+		farm.ID, farm.Name, farm.Address,
+		parts.Street, parts.Number, parts.District, parts.City, parts.State, parts.Country, parts.PostalCode,
+		farm.LandArea.String(), farm.UnitOfMeasurement, farm.LandAreaHectares.String(),
+		lat, lng,
+		farm.CreatedAt.UTC().Format(time.RFC3339), farm.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func csvCropColumns(crop *crops.Crop) []string {
+	var plantedArea string
+	if crop.PlantedArea != nil {
+		plantedArea = crop.PlantedArea.String()
+	}
+
+	return []string{
+		crop.ID, string(crop.Type),
+		strconv.FormatBool(crop.IsIrrigated), strconv.FormatBool(crop.IsInsured),
+		plantedArea, crop.UnitOfMeasurement, crop.Season, string(crop.CurrentStatus()),
+	}
+}
+
+// Writes farms as they are listed, one per line
+type ndjsonExportWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonExportWriter) Write(farm *Farm) error {
+	return n.enc.Encode(farm)
+}
+
+func (n *ndjsonExportWriter) Close() error {
+	return nil
+}
+
+// Writes a FeatureCollection with a feature per farm. Their geometry is the
+// boundary, or the location when there is none, and their properties the
+// farm as it is listed.
+type geoJSONExportWriter struct {
+	w        io.Writer
+	features int
+}
+
+type geoJSONFeature struct {
+	Type     string      `json:"type"`
+	ID       string      `json:"id"`
+	Geometry interface{} `json:"geometry"`
+	// Properties holds the farm as listed
+	Properties *Farm `json:"properties"`
+}
+
+func (g *geoJSONExportWriter) Write(farm *Farm) error {
+	feature := geoJSONFeature{Type: "Feature", ID: farm.ID, Properties: farm}
+	switch {
+	case farm.Boundary != nil:
+		feature.Geometry = farm.Boundary
+	case farm.Location != nil:
+		feature.Geometry = farm.Location
+	}
+
+	b, err := json.Marshal(feature)
+	if err != nil {
+		return err
+	}
+
+	prefix := ",\n"
+	if g.features == 0 {
+		prefix = `{"type":"FeatureCollection","features":[` + "\n"
+	}
+	g.features++
+
+	if _, err := io.WriteString(g.w, prefix); err != nil {
+		return err
+	}
+	_, err = g.w.Write(b)
+	return err
+}
+
+func (g *geoJSONExportWriter) Close() error {
+	trailer := "\n]}\n"
+	if g.features == 0 {
+		trailer = `{"type":"FeatureCollection","features":[]}` + "\n"
+	}
+
+	_, err := io.WriteString(g.w, trailer)
+	return err
+}
+
+// Streams the farms matching the listing filters into w, without holding
+// them in memory. Farms are handed to each before being written, e.g. to
+// convert their land area.
+func (s *Service) ExportFarms(ctx context.Context, filter *ListFarmQuery, w ExportWriter, each func(farm *Farm)) error {
+	err := s.farmRepository.Export(ctx, filter, func(farm *Farm) error {
+		if each != nil {
+			each(farm)
+		}
+		return w.Write(farm)
+	})
+	if err != nil {
+		return err
+	}
+
+	return w.Close()
+}
@@ -0,0 +1,144 @@
+package farms_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mateusfdl/go-api/internal/address"
+	"github.com/mateusfdl/go-api/internal/crops"
+	"github.com/mateusfdl/go-api/internal/decimal"
+	"github.com/mateusfdl/go-api/internal/farms"
+	"github.com/mateusfdl/go-api/internal/geo"
+)
+
+func exportedFarms() []*farms.Farm {
+	area := decimal.MustParse("4")
+	location := geo.NewPoint(-55.71, -12.54)
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	return []*farms.Farm{
+		{
+			ID:                "1",
+			Name:              "Farm 1",
+			Address:           "Fazenda Boa Vista, Sorriso - MT",
+			StructuredAddress: &address.Address{Street: "Fazenda Boa Vista", City: "Sorriso", State: "MT"},
+			LandArea:          decimal.MustParse("12.5"),
+			UnitOfMeasurement: "ha",
+			LandAreaHectares:  decimal.MustParse("12.5"),
+			Location:          &location,
+			Crops: []crops.Crop{
+				{ID: "c1", Type: crops.CropTypeCorn, IsIrrigated: true, PlantedArea: &area, UnitOfMeasurement: "ha"},
+				{ID: "c2", Type: crops.CropTypeSoybean, Status: crops.StatusPlanted},
+			},
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+		},
+		{ID: "2", Name: "Farm 2", Address: "Rua 1", LandArea: decimal.MustParse("1"), CreatedAt: createdAt, UpdatedAt: createdAt},
+	}
+}
+
+func export(t *testing.T, format farms.ExportFormat, list []*farms.Farm) string {
+	var b bytes.Buffer
+	w, err := farms.NewExportWriter(format, &b)
+	if err != nil {
+		t.Fatalf("NewExportWriter failed: %v", err)
+	}
+
+	for _, farm := range list {
+		if err := w.Write(farm); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	return b.String()
+}
+
+func TestCSVExportFlattensCrops(t *testing.T) {
+	records, err := csv.NewReader(strings.NewReader(export(t, farms.ExportCSV, exportedFarms()))).ReadAll()
+	if err != nil {
+		t.Fatalf("Expect valid CSV, but got %v", err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("Expect a header and 3 rows, but got %v", records)
+	}
+
+	column := make(map[string]int)
+	for i, c := range records[0] {
+		column[c] = i
+	}
+
+	first, second, bare := records[1], records[2], records[3]
+	if first[column["id"]] != "1" || first[column["city"]] != "Sorriso" || first[column["latitude"]] != "-12.54" {
+		t.Errorf("Unexpected farm columns %v", first)
+	}
+	if first[column["cropType"]] != "CORN" || first[column["cropPlantedArea"]] != "4" || first[column["cropIsIrrigated"]] != "true" {
+		t.Errorf("Unexpected crop columns %v", first)
+	}
+	if second[column["id"]] != "1" || second[column["cropType"]] != "SOYBEANS" || second[column["cropStatus"]] != "planted" {
+		t.Errorf("Expect the farm to be repeated with its second crop, but got %v", second)
+	}
+	if bare[column["id"]] != "2" || bare[column["cropId"]] != "" || bare[column["createdAt"]] != "2025-03-01T12:00:00Z" {
+		t.Errorf("Expect a farm without crops to take a single row, but got %v", bare)
+	}
+}
+
+func TestExportWritesHeadersWithoutFarms(t *testing.T) {
+	if got := export(t, farms.ExportCSV, nil); !strings.HasPrefix(got, "id,name,") || strings.Count(got, "\n") != 1 {
+		t.Errorf("Expect only the header, but got %q", got)
+	}
+	if got := export(t, farms.ExportNDJSON, nil); got != "" {
+		t.Errorf("Expect nothing, but got %q", got)
+	}
+
+	var collection struct {
+		Type     string
+		Features []interface{}
+	}
+	if err := json.Unmarshal([]byte(export(t, farms.ExportGeoJSON, nil)), &collection); err != nil || collection.Type != "FeatureCollection" || collection.Features == nil {
+		t.Errorf("Expect an empty FeatureCollection, but got %+v, %v", collection, err)
+	}
+}
+
+func TestNDJSONExport(t *testing.T) {
+	lines := strings.Split(strings.TrimSuffix(export(t, farms.ExportNDJSON, exportedFarms()), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expect a line per farm, but got %q", lines)
+	}
+
+	var farm farms.Farm
+	if err := json.Unmarshal([]byte(lines[0]), &farm); err != nil || farm.Name != "Farm 1" || len(farm.Crops) != 2 {
+		t.Errorf("Unexpected farm %+v, %v", farm, err)
+	}
+}
+
+func TestGeoJSONExport(t *testing.T) {
+	var collection struct {
+		Type     string `json:"type"`
+		Features []struct {
+			ID         string          `json:"id"`
+			Geometry   *geo.Point      `json:"geometry"`
+			Properties json.RawMessage `json:"properties"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal([]byte(export(t, farms.ExportGeoJSON, exportedFarms())), &collection); err != nil {
+		t.Fatalf("Expect valid JSON, but got %v", err)
+	}
+	if len(collection.Features) != 2 {
+		t.Fatalf("Expect a feature per farm, but got %+v", collection.Features)
+	}
+
+	located, bare := collection.Features[0], collection.Features[1]
+	if located.ID != "1" || located.Geometry == nil || located.Geometry.Lat() != -12.54 {
+		t.Errorf("Expect the location as geometry, but got %+v", located.Geometry)
+	}
+	if bare.ID != "2" || bare.Geometry != nil || !bytes.Contains(bare.Properties, []byte(`"Name":"Farm 2"`)) {
+		t.Errorf("Expect a null geometry and the farm as properties, but got %+v", bare)
+	}
+}
@@ -0,0 +1,434 @@
+package farms
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	mongo_adapter "github.com/mateusfdl/go-api/adapters/mongo"
+	"github.com/mateusfdl/go-api/internal/address"
+	"github.com/mateusfdl/go-api/internal/crops"
+	"github.com/mateusfdl/go-api/internal/decimal"
+	"github.com/mateusfdl/go-api/internal/geo"
+	"github.com/mateusfdl/go-api/internal/validation"
+)
+
+const (
+	// Farms are written this many at a time
+	importBatchSize = 100
+	// Longest NDJSON line, a farm with its crops and boundary
+	maxImportLineSize = 1 << 20
+)
+
+// A farm read from an import, or the reason it could not be read
+type ImportRow struct {
+	// Line of the input the row starts on
+	Line int
+	Farm *CreateFarmDTO
+	Err  error
+}
+
+// Reads an import one row at a time, so that inputs are never held in memory
+type RowReader interface {
+	// Returns io.EOF once every row was read. Other errors end the import,
+	// rows that can not be decoded being reported in their ImportRow instead.
+	Next() (*ImportRow, error)
+}
+
+// Columns of CSV imports, matched regardless of case. Addresses are given
+// either on a line or in their parts, and crops as types separated by
+// semicolons.
+var CSVColumns = []string{
+	"name", "address", "street", "number", "district", "city", "state", "country", "postalCode",
+	"landArea", "unitOfMeasurement", "latitude", "longitude", "crops",
+}
+
+type csvRowReader struct {
+	r       *csv.Reader
+	columns []string
+}
+
+// Reads the header right away, failing on columns it does not know
+func NewCSVRowReader(r io.Reader) (RowReader, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: missing CSV header", ErrInvalidImport)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+
+	known := make(map[string]string)
+	for _, c := range CSVColumns {
+		known[strings.ToLower(c)] = c
+	}
+
+	columns := make([]string, len(header))
+	seen := make(map[string]bool)
+	for i, h := range header {
+		h = strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))
+		column, ok := known[strings.ToLower(h)]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown column %q, expected some of %s", ErrInvalidImport, h, strings.Join(CSVColumns, ", "))
+		}
+		if seen[column] {
+			return nil, fmt.Errorf("%w: column %q given twice", ErrInvalidImport, h)
+		}
+		seen[column] = true
+		columns[i] = column
+	}
+
+	return &csvRowReader{r: cr, columns: columns}, nil
+}
+
+func (c *csvRowReader) Next() (*ImportRow, error) {
+	record, err := c.r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, io.EOF
+	}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return &ImportRow{Line: parseErr.StartLine, Err: fmt.Errorf("%w: %w", ErrMalformedImportRow, parseErr.Err)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	line, _ := c.r.FieldPos(0)
+	values := make(map[string]string)
+	for i, v := range record {
+		values[c.columns[i]] = strings.TrimSpace(v)
+	}
+
+	farm, err := farmFromCSV(values)
+	return &ImportRow{Line: line, Farm: farm, Err: err}, nil
+}
+
+// Values that can not be parsed are reported like validation problems, the
+// remaining fields being validated afterwards as any other farm
+func farmFromCSV(values map[string]string) (*CreateFarmDTO, error) {
+	var errs validation.Errors
+	dto := &CreateFarmDTO{
+		Name:              values["name"],
+		UnitOfMeasurement: values["unitOfMeasurement"],
+	}
+
+	dto.Address = address.FromLine(values["address"])
+	parts := address.Address{
+		Street:     values["street"],
+		Number:     values["number"],
+		District:   values["district"],
+		City:       values["city"],
+		State:      values["state"],
+		Country:    values["country"],
+		PostalCode: values["postalCode"],
+	}
+	if values["address"] == "" && !parts.IsZero() {
+		dto.Address = address.FromParts(parts)
+	}
+
+	if v := values["landArea"]; v != "" {
+		landArea, err := decimal.Parse(v)
+		if err != nil {
+			errs.Add("landArea", validation.CodeInvalid, "landArea must be a number")
+		} else {
+			dto.LandArea = &landArea
+		}
+	}
+
+	if lat, lng := values["latitude"], values["longitude"]; lat != "" || lng != "" {
+		location, err := geo.ParseLatLng(lat + "," + lng)
+		if err != nil {
+			errs.Add("location", validation.CodeInvalid, "latitude and longitude must both be given as degrees")
+		} else {
+			dto.Location = &location
+		}
+	}
+
+	cropList := []crops.CreateCropDTO{}
+	for _, t := range strings.Split(values["crops"], ";") {
+		if t = strings.TrimSpace(t); t != "" {
+			cropList = append(cropList, crops.CreateCropDTO{Type: crops.CropType(t)})
+		}
+	}
+	dto.Crops = &cropList
+
+	return dto, errs.Err()
+}
+
+type ndjsonRowReader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+// Reads a farm per line, in the body format of POST /farms. Blank lines are
+// skipped.
+func NewNDJSONRowReader(r io.Reader) RowReader {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), maxImportLineSize)
+	return &ndjsonRowReader{s: s}
+}
+
+func (n *ndjsonRowReader) Next() (*ImportRow, error) {
+	for n.s.Scan() {
+		n.line++
+		b := bytes.TrimSpace(n.s.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+
+		var dto CreateFarmDTO
+		if err := json.Unmarshal(b, &dto); err != nil {
+			return &ImportRow{Line: n.line, Err: fmt.Errorf("%w: %w", ErrMalformedImportRow, err)}, nil
+		}
+
+		return &ImportRow{Line: n.line, Farm: &dto}, nil
+	}
+
+	if err := n.s.Err(); err != nil {
+		return nil, fmt.Errorf("line %d: %w", n.line+1, err)
+	}
+
+	return nil, io.EOF
+}
+
+// Validates every row like a farm creation and writes the valid ones in
+// batches, each batch either inside a transaction or, on standalone servers,
+// compensated when it fails. The farms of a failed batch are then written one
+// at a time. Dry runs only validate. An input that can not be read to its end,
+// or a cancelled context, stops the import, the rows read so far being kept
+// and the pending ones reported as failed.
+func (s *Service) ImportFarms(ctx context.Context, rows RowReader, dryRun bool) (*ImportReport, error) {
+	report := &ImportReport{DryRun: dryRun, Results: []ImportRowResult{}}
+
+	// Index of the result of every farm of the batch
+	var batch []*CreateFarmDTO
+	var pending []int
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		var created []string
+		errs := make([]error, len(batch))
+		if err := ctx.Err(); err != nil {
+			for i := range errs {
+				errs[i] = err
+			}
+		} else {
+			created, errs = s.importBatch(ctx, batch)
+		}
+
+		for i, r := range pending {
+			res := &report.Results[r]
+			if err := errs[i]; err != nil {
+				res.Status, res.Error = ImportFailed, s.importRowError(res.Line, err)
+				report.Failed++
+				continue
+			}
+
+			res.Status, res.ID = ImportCreated, created[i]
+			report.Created++
+		}
+
+		batch, pending = batch[:0], pending[:0]
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			report.Error = s.importReadError(report.Rows, err)
+			break
+		}
+
+		row, err := rows.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			report.Error = s.importReadError(report.Rows, err)
+			break
+		}
+
+		report.Rows++
+		res := ImportRowResult{Line: row.Line}
+		if err := s.checkImportRow(row); err != nil {
+			res.Status = ImportInvalid
+			var problems validation.Errors
+			if errors.As(err, &problems) {
+				res.Problems = problems
+			} else {
+				res.Error = s.importRowError(row.Line, err)
+			}
+			report.Invalid++
+			report.Results = append(report.Results, res)
+			continue
+		}
+
+		if dryRun {
+			res.Status = ImportValid
+			report.Valid++
+			report.Results = append(report.Results, res)
+			continue
+		}
+
+		report.Results = append(report.Results, res)
+		batch = append(batch, row.Farm)
+		pending = append(pending, len(report.Results)-1)
+		if len(batch) == importBatchSize {
+			flush()
+		}
+	}
+
+	flush()
+	if report.Failed > 0 {
+		s.l.Warn("Farm import left rows out", "failed", report.Failed, "invalid", report.Invalid)
+	}
+
+	return report, nil
+}
+
+const importCancelledMessage = "import was cancelled or took too long"
+
+// Message a row is reported with. Errors of farms and crops keep theirs, which
+// never hold driver text, and other errors are logged with the line and
+// reported in general terms.
+func (s *Service) importRowError(line int, err error) string {
+	switch {
+	case errors.Is(err, ErrMalformedImportRow):
+		s.l.Warn("Farm import row could not be decoded", "line", line, "error", err)
+		return ErrMalformedImportRow.Error()
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return importCancelledMessage
+	case errors.Is(err, ErrInvalidFarmFields), errors.Is(err, ErrFarmAlreadyExists), errors.Is(err, crops.ErrPlantedAreaExceedsLandArea):
+		return err.Error()
+	}
+
+	s.l.Error("Farm import row could not be written", "line", line, err)
+	return "row could not be written"
+}
+
+// Message an import that stopped reading its input is reported with
+func (s *Service) importReadError(rows int, err error) string {
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return importCancelledMessage
+	case errors.Is(err, bufio.ErrTooLong):
+		return fmt.Sprintf("row %d is longer than %d bytes", rows+1, maxImportLineSize)
+	}
+
+	s.l.Warn("Farm import input could not be read to its end", "rows", rows, "error", err)
+	return "input could not be read to its end"
+}
+
+// Problems are returned as validation.Errors, other errors as they are.
+// Values that could not be parsed are reported along with the problems of
+// the other fields, the parsed fields left empty not being reported twice.
+func (s *Service) checkImportRow(row *ImportRow) error {
+	var problems validation.Errors
+	if row.Err != nil && !errors.As(row.Err, &problems) {
+		return row.Err
+	}
+
+	if err := validateFields(row.Farm, s.cfg, s.cropTypes); err != nil {
+		var fieldProblems validation.Errors
+		if !errors.As(err, &fieldProblems) {
+			return err
+		}
+
+		reported := make(map[string]bool)
+		for _, p := range problems {
+			reported[p.Field] = true
+		}
+		for _, p := range fieldProblems {
+			if !reported[p.Field] {
+				problems = append(problems, p)
+			}
+		}
+	}
+	if err := problems.Err(); err != nil {
+		return err
+	}
+
+	return allocateCrops(row.Farm)
+}
+
+// Writes the batch at once, falling back to a farm at a time when it fails so
+// that every row is reported with its own error. Ids and errors are in the
+// order of the batch.
+func (s *Service) importBatch(ctx context.Context, batch []*CreateFarmDTO) ([]string, []error) {
+	created := make([]string, len(batch))
+	errs := make([]error, len(batch))
+
+	ids, err := s.writeBatch(ctx, batch)
+	if err == nil {
+		return ids, errs
+	}
+	if ctx.Err() != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return created, errs
+	}
+
+	s.l.Warn("Farm import batch failed, writing its farms one at a time", "farms", len(batch), "error", err)
+	for i, dto := range batch {
+		created[i], errs[i] = s.persistFarm(ctx, dto)
+	}
+
+	return created, errs
+}
+
+func (s *Service) writeBatch(ctx context.Context, batch []*CreateFarmDTO) ([]string, error) {
+	var created []string
+	err := s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		created, err = s.createFarmsWithCrops(ctx, batch)
+		return err
+	})
+	if errors.Is(err, mongo_adapter.ErrTransactionsNotSupported) {
+		created, err = s.createFarmsWithCrops(ctx, batch)
+		if err != nil {
+			// Farms the server rejected have no id, as they were never written
+			for _, id := range created {
+				if id != "" {
+					s.rollBackFarm(ctx, id)
+				}
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (s *Service) createFarmsWithCrops(ctx context.Context, batch []*CreateFarmDTO) ([]string, error) {
+	created, err := s.farmRepository.CreateMany(ctx, batch)
+	if err != nil {
+		return created, err
+	}
+
+	farmCrops := make(map[string]*[]crops.CreateCropDTO)
+	for i, dto := range batch {
+		if dto.Crops != nil && len(*dto.Crops) > 0 {
+			farmCrops[created[i]] = dto.Crops
+		}
+	}
+
+	if err := s.cropRepository.CreateForFarms(ctx, farmCrops); err != nil {
+		return created, fmt.Errorf("%w: %w", ErrOnPersistCrops, err)
+	}
+
+	return created, nil
+}
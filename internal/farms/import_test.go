@@ -0,0 +1,228 @@
+package farms_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mateusfdl/go-api/adapters/logger"
+	mongo_adapter "github.com/mateusfdl/go-api/adapters/mongo"
+	"github.com/mateusfdl/go-api/internal/crops"
+	"github.com/mateusfdl/go-api/internal/farms"
+	"github.com/mateusfdl/go-api/internal/validation"
+)
+
+func TestCSVRowReader(t *testing.T) {
+	input := "Name,landArea,unitOfMeasurement,city,state,latitude,longitude,crops\n" +
+		"Farm 1,12.5,ha,Sorriso,MT,-12.54,-55.71,CORN;SOYBEANS\n" +
+		"Farm 2,lots,ha,Sorriso,MT,,,\n" +
+		"Farm 3,10\n"
+
+	rows, err := farms.NewCSVRowReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewCSVRowReader failed: %v", err)
+	}
+
+	row, err := rows.Next()
+	if err != nil || row.Err != nil {
+		t.Fatalf("Expect a valid first row, but got %v, %v", err, row.Err)
+	}
+	farm := row.Farm
+	if row.Line != 2 || farm.Name != "Farm 1" || farm.LandArea.String() != "12.5" || !farm.Address.IsStructured() {
+		t.Errorf("Unexpected first row %+v", farm)
+	}
+	if farm.Location == nil || farm.Location.Lat() != -12.54 || len(*farm.Crops) != 2 {
+		t.Errorf("Expect a location and 2 crops, but got %+v, %+v", farm.Location, farm.Crops)
+	}
+
+	row, _ = rows.Next()
+	if row.Line != 3 || row.Err == nil {
+		t.Errorf("Expect the malformed land area to be reported, but got %+v", row)
+	}
+
+	row, _ = rows.Next()
+	if row.Line != 4 || !errors.Is(row.Err, farms.ErrMalformedImportRow) {
+		t.Errorf("Expect the missing columns to be reported, but got %+v", row)
+	}
+
+	if _, err := rows.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("Expect io.EOF, but got %v", err)
+	}
+}
+
+func TestCSVRowReaderRejectsUnknownColumns(t *testing.T) {
+	_, err := farms.NewCSVRowReader(strings.NewReader("name,size\nFarm 1,10\n"))
+	if !errors.Is(err, farms.ErrInvalidImport) {
+		t.Errorf("Expect ErrInvalidImport, but got %v", err)
+	}
+}
+
+func TestNDJSONRowReaderSkipsBlankLines(t *testing.T) {
+	rows := farms.NewNDJSONRowReader(strings.NewReader("{\"name\": \"Farm 1\"}\n\n{\"name\": \n"))
+
+	row, err := rows.Next()
+	if err != nil || row.Line != 1 || row.Farm.Name != "Farm 1" {
+		t.Errorf("Unexpected first row %+v, %v", row, err)
+	}
+
+	row, err = rows.Next()
+	if err != nil || row.Line != 3 || !errors.Is(row.Err, farms.ErrMalformedImportRow) {
+		t.Errorf("Expect the malformed third line to be reported, but got %+v, %v", row, err)
+	}
+
+	if _, err := rows.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("Expect io.EOF, but got %v", err)
+	}
+}
+
+const importInput = `{"name": "Farm 1", "landArea": 10, "unitOfMeasurement": "ha", "address": "Rua 1", "crops": [{"type": "CORN"}]}
+{"name": "", "landArea": 10, "unitOfMeasurement": "ha", "address": "Rua 2"}
+{"name": "Farm 3", "landArea": 10, "unitOfMeasurement": "ha", "address": "Rua 3"}
+`
+
+func TestImportFarms(t *testing.T) {
+	l := logger.New(logger.Config{Level: "error"})
+	farmRepo := &fakeFarmRepository{}
+	var cropRepo crops.Repository = &fakeCropRepository{}
+	s := farms.NewService(l, farmRepo, &cropRepo, crops.BuiltinTypes{}, &fakeTransactor{}, farms.Config{LandAreaPrecision: 2})
+
+	report, err := s.ImportFarms(context.Background(), farms.NewNDJSONRowReader(strings.NewReader(importInput)), false)
+	if err != nil {
+		t.Fatalf("ImportFarms failed: %v", err)
+	}
+
+	if report.Rows != 3 || report.Created != 2 || report.Invalid != 1 || len(farmRepo.created) != 2 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+
+	invalid := report.Results[1]
+	if invalid.Status != farms.ImportInvalid || invalid.Line != 2 || len(invalid.Problems) == 0 || invalid.Problems[0].Field != "name" {
+		t.Errorf("Expect the second row to be invalid on its name, but got %+v", invalid)
+	}
+	if report.Results[2].Status != farms.ImportCreated || report.Results[2].ID != farmRepo.created[1] {
+		t.Errorf("Expect the third row to be created, but got %+v", report.Results[2])
+	}
+}
+
+func TestImportFarmsDryRun(t *testing.T) {
+	l := logger.New(logger.Config{Level: "error"})
+	farmRepo := &fakeFarmRepository{}
+	var cropRepo crops.Repository = &fakeCropRepository{}
+	s := farms.NewService(l, farmRepo, &cropRepo, crops.BuiltinTypes{}, &fakeTransactor{}, farms.Config{LandAreaPrecision: 2})
+
+	report, err := s.ImportFarms(context.Background(), farms.NewNDJSONRowReader(strings.NewReader(importInput)), true)
+	if err != nil {
+		t.Fatalf("ImportFarms failed: %v", err)
+	}
+
+	if report.Valid != 2 || report.Invalid != 1 || report.Created != 0 || len(farmRepo.created) != 0 {
+		t.Errorf("Expect nothing written, but got %+v and %v", report, farmRepo.created)
+	}
+}
+
+func TestImportFarmsReportsParseAndFieldProblems(t *testing.T) {
+	l := logger.New(logger.Config{Level: "error"})
+	var cropRepo crops.Repository = &fakeCropRepository{}
+	s := farms.NewService(l, &fakeFarmRepository{}, &cropRepo, crops.BuiltinTypes{}, &fakeTransactor{}, farms.Config{LandAreaPrecision: 2})
+
+	rows, err := farms.NewCSVRowReader(strings.NewReader("name,address,landArea,unitOfMeasurement\n,Rua 1,lots,ha\n"))
+	if err != nil {
+		t.Fatalf("NewCSVRowReader failed: %v", err)
+	}
+
+	report, err := s.ImportFarms(context.Background(), rows, true)
+	if err != nil {
+		t.Fatalf("ImportFarms failed: %v", err)
+	}
+
+	problems := report.Results[0].Problems
+	if len(problems) != 2 || problems[0].Field != "landArea" || problems[0].Code != validation.CodeInvalid || problems[1].Field != "name" {
+		t.Errorf("Expect the unparsable land area and the missing name, but got %+v", problems)
+	}
+}
+
+func TestImportFarmsCompensatesFailedBatches(t *testing.T) {
+	l := logger.New(logger.Config{Level: "error"})
+	farmRepo := &fakeFarmRepository{}
+	cropRepo := &fakeCropRepository{createErr: errors.New("write failed")}
+	var repo crops.Repository = cropRepo
+	s := farms.NewService(l, farmRepo, &repo, crops.BuiltinTypes{}, &fakeTransactor{err: mongo_adapter.ErrTransactionsNotSupported}, farms.Config{LandAreaPrecision: 2})
+
+	report, err := s.ImportFarms(context.Background(), farms.NewNDJSONRowReader(strings.NewReader(importInput)), false)
+	if err != nil {
+		t.Fatalf("ImportFarms failed: %v", err)
+	}
+
+	// Only the first farm has crops, which fail when it is written on its own
+	if report.Failed != 1 || report.Created != 1 || report.Results[0].Status != farms.ImportFailed {
+		t.Errorf("Expect the row with crops to fail on its own, but got %+v", report)
+	}
+	if report.Results[2].Status != farms.ImportCreated {
+		t.Errorf("Expect the row without crops to be created, but got %+v", report.Results[2])
+	}
+	if len(farmRepo.deleted) != 3 || farmRepo.deleted[0] != farmRepo.created[0] || farmRepo.deleted[1] != farmRepo.created[1] {
+		t.Errorf("Expect the batch and then the failed farm to be rolled back, but got %v", farmRepo.deleted)
+	}
+}
+
+func TestImportFarmsRollsBackOnlyWrittenFarms(t *testing.T) {
+	l := logger.New(logger.Config{Level: "error"})
+	farmRepo := &fakeFarmRepository{rejected: "Farm 3"}
+	var cropRepo crops.Repository = &fakeCropRepository{}
+	s := farms.NewService(l, farmRepo, &cropRepo, crops.BuiltinTypes{}, &fakeTransactor{err: mongo_adapter.ErrTransactionsNotSupported}, farms.Config{LandAreaPrecision: 2})
+
+	report, err := s.ImportFarms(context.Background(), farms.NewNDJSONRowReader(strings.NewReader(importInput)), false)
+	if err != nil {
+		t.Fatalf("ImportFarms failed: %v", err)
+	}
+
+	if len(farmRepo.deleted) != 1 || farmRepo.deleted[0] != farmRepo.created[0] {
+		t.Errorf("Expect only the written farm of the batch to be rolled back, but got %v", farmRepo.deleted)
+	}
+	if report.Created != 1 || report.Results[0].Status != farms.ImportCreated || report.Results[0].ID == "" {
+		t.Errorf("Expect the first row to be created on its own, but got %+v", report.Results[0])
+	}
+	if rejected := report.Results[2]; report.Failed != 1 || rejected.Status != farms.ImportFailed || rejected.Error == "" || rejected.Error == errFarmRejected.Error() {
+		t.Errorf("Expect the rejected row to fail without the repository error, but got %+v", rejected)
+	}
+}
+
+// Cancels the import once the given number of rows were read
+type cancellingRowReader struct {
+	farms.RowReader
+	cancel func()
+	after  int
+}
+
+func (r *cancellingRowReader) Next() (*farms.ImportRow, error) {
+	if r.after--; r.after < 0 {
+		r.cancel()
+	}
+
+	return r.RowReader.Next()
+}
+
+func TestImportFarmsReportsCancellation(t *testing.T) {
+	l := logger.New(logger.Config{Level: "error"})
+	farmRepo := &fakeFarmRepository{}
+	var cropRepo crops.Repository = &fakeCropRepository{}
+	s := farms.NewService(l, farmRepo, &cropRepo, crops.BuiltinTypes{}, &fakeTransactor{}, farms.Config{LandAreaPrecision: 2})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rows := &cancellingRowReader{RowReader: farms.NewNDJSONRowReader(strings.NewReader(importInput)), cancel: cancel, after: 1}
+
+	report, err := s.ImportFarms(ctx, rows, false)
+	if err != nil {
+		t.Fatalf("Expect the partial report, but got %v", err)
+	}
+
+	if report.Error == "" || strings.Contains(report.Error, context.Canceled.Error()) || report.Rows != 2 {
+		t.Errorf("Expect the import to stop after two rows, but got %+v", report)
+	}
+	if report.Failed != 1 || report.Results[0].Status != farms.ImportFailed || len(farmRepo.created) != 0 {
+		t.Errorf("Expect the pending row to fail unwritten, but got %+v and %v", report.Results[0], farmRepo.created)
+	}
+}
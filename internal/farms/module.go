@@ -3,6 +3,8 @@ package farms
 import (
 	"github.com/mateusfdl/go-api/adapters/http"
 	"github.com/mateusfdl/go-api/adapters/logger"
+	mongo_adapter "github.com/mateusfdl/go-api/adapters/mongo"
+	"github.com/mateusfdl/go-api/internal/audit"
 	"github.com/mateusfdl/go-api/internal/crops"
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -11,17 +13,21 @@ type FarmModule struct {
 	Repo       Repository
 	Service    *Service
 	Controller *Controller
+	PurgeJob   *PurgeJob
 }
 
 func New(
 	l *logger.Logger,
 	cropRepo *crops.Repository,
+	cropTypes crops.TypeCatalog,
 	h *http.HTTP,
 	db *mongo.Database,
-
+	cfg Config,
 ) *FarmModule {
-	r := NewMongoRepository(db, l)
-	s := NewService(l, r, cropRepo)
-	c := NewController(h, s, l)
-	return &FarmModule{Repo: r, Service: s, Controller: c}
+	r := NewMongoRepository(db, l, audit.SystemClock)
+	s := NewService(l, r, cropRepo, cropTypes, mongo_adapter.NewTransactor(db), cfg)
+	s.RegisterDependent("crops", *cropRepo)
+	c := NewController(h, s, l, cropTypes, cfg)
+	j := NewPurgeJob(l, s, cfg)
+	return &FarmModule{Repo: r, Service: s, Controller: c, PurgeJob: j}
 }
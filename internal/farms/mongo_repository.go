@@ -2,21 +2,32 @@ package farms
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/mateusfdl/go-api/adapters/logger"
+	"github.com/mateusfdl/go-api/internal/audit"
+	"github.com/mateusfdl/go-api/internal/decimal"
+	"github.com/mateusfdl/go-api/internal/geo"
+	"github.com/mateusfdl/go-api/internal/ids"
+	"github.com/mateusfdl/go-api/internal/pagination"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type MongoRepository struct {
-	db *mongo.Database
-	l  *logger.Logger
+	db         *mongo.Database
+	l          *logger.Logger
+	timestamps *audit.Timestamps
 }
 
-func NewMongoRepository(db *mongo.Database, l *logger.Logger) *MongoRepository {
-	return &MongoRepository{db: db, l: l}
+func NewMongoRepository(db *mongo.Database, l *logger.Logger, clock audit.Clock) *MongoRepository {
+	return &MongoRepository{db: db, l: l, timestamps: audit.NewTimestamps(clock)}
 }
 
 func (r *MongoRepository) Create(
@@ -24,12 +35,16 @@ func (r *MongoRepository) Create(
 	dto *CreateFarmDTO,
 ) (string, error) {
 	fields := dto.ToMap()
+	r.timestamps.OnCreate(fields)
 
 	doc, err := r.db.Collection("farms").InsertOne(ctx, fields)
 	if err != nil {
 		if ok := mongo.IsDuplicateKeyError(err); ok {
 			return "", ErrFarmAlreadyExists
 		}
+		if isGeoKeyError(err) {
+			return "", r.geometryError(err)
+		}
 
 		return "", err
 	}
@@ -42,51 +57,438 @@ func (r *MongoRepository) Create(
 	return oid.Hex(), nil
 }
 
+func (r *MongoRepository) CreateMany(
+	ctx context.Context,
+	dtos []*CreateFarmDTO,
+) ([]string, error) {
+	docs := make([]interface{}, len(dtos))
+	created := make([]string, len(dtos))
+	for i, dto := range dtos {
+		oid := primitive.NewObjectID()
+		fields := dto.ToMap()
+		fields["_id"] = oid
+		r.timestamps.OnCreate(fields)
+		docs[i] = fields
+		created[i] = oid.Hex()
+	}
+
+	// Unordered, so that a rejected farm does not keep the others from being
+	// written
+	_, err := r.db.Collection("farms").InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	var bwe mongo.BulkWriteException
+	if errors.As(err, &bwe) {
+		for _, we := range bwe.WriteErrors {
+			created[we.Index] = ""
+		}
+	}
+	if isGeoKeyError(err) {
+		return created, r.geometryError(err)
+	}
+	if err != nil {
+		r.l.Error("error on bulk create farms", err)
+		return created, err
+	}
+
+	return created, nil
+}
+
+// Farms are listed newest first
+var listSortKeys = []pagination.SortKey{
+	{Field: "createdAt", Desc: true},
+	{Field: "_id", Desc: true},
+}
+
+// The trash is listed most recently deleted first
+var trashSortKeys = []pagination.SortKey{
+	{Field: "deletedAt", Desc: true},
+	{Field: "_id", Desc: true},
+}
+
+// Fields the listing can be sorted by
+var SortableFields = []string{"name", "landArea", "createdAt", "updatedAt"}
+
+// Sortable fields stored under another name. Land areas are compared in
+// hectares so that every unit sorts together.
+var sortColumns = map[string]string{"landArea": "landAreaHectares"}
+
+// Values sort fields are paginated with when documents lack them, like
+// createdAt on farms written before timestamps. They match the zero values
+// farms decode to, which their cursors are taken from.
+var sortDefaults = map[string]interface{}{
+	"name":             "",
+	"landAreaHectares": decimal.Decimal{},
+	"createdAt":        time.Time{},
+	"updatedAt":        time.Time{},
+}
+
 func (r *MongoRepository) List(
 	ctx context.Context,
 	filter *ListFarmQuery,
-) ([]Farm, error) {
-	pipeline := mongo.Pipeline{}
+) (*pagination.Page[Farm], error) {
+	pipeline, cropsJoined := filterPipeline(filter)
+	keys := sortKeysOf(filter)
 
-	pipeline = append(pipeline, bson.D{
-		{Key: "$lookup", Value: bson.M{
-			"from":         "crops",
-			"localField":   "_id",
-			"foreignField": "farmId",
-			"as":           "crops",
-		}},
+	return paginate(ctx, r, pipeline, cropsJoined, keys, filter.Params, func(f *Farm) (pagination.Cursor, error) {
+		return farmCursor(f, keys)
 	})
+}
 
-	if filter.CropType != "" || filter.LandArea != 0 {
-		matchStage := bson.M{}
+// Sort keys of the listing, tiebreaker included
+func sortKeysOf(filter *ListFarmQuery) []pagination.SortKey {
+	if len(filter.Sort) == 0 {
+		return listSortKeys
+	}
 
-		if filter.CropType != "" {
-			matchStage["crops.type"] = filter.CropType
+	sort := make([]pagination.SortKey, len(filter.Sort))
+	for i, k := range filter.Sort {
+		sort[i] = k
+		if column, ok := sortColumns[k.Field]; ok {
+			sort[i].Field = column
 		}
+	}
 
-		if filter.LandArea != 0 {
-			matchStage["landArea"] = bson.M{"$gte": filter.LandArea}
+	return pagination.WithTiebreaker(sort)
+}
+
+// Streams the farms matching the listing filters, in the listing order and
+// with their crops, one at a time from the cursor. Pagination is ignored.
+func (r *MongoRepository) Export(ctx context.Context, filter *ListFarmQuery, fn func(farm *Farm) error) error {
+	pipeline, cropsJoined := filterPipeline(filter)
+	pipeline = append(pipeline, pagination.SortStage(sortKeysOf(filter)))
+	if !cropsJoined {
+		pipeline = append(pipeline, lookupCropsStage())
+	}
+
+	cursor, err := r.db.Collection("farms").Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		r.l.Error("error on exporting farms", err)
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var farm Farm
+		if err := cursor.Decode(&farm); err != nil {
+			return err
 		}
 
-		pipeline = append(pipeline, bson.D{{Key: "$match", Value: matchStage}})
+		if err := fn(&farm); err != nil {
+			return err
+		}
 	}
 
-	pipeline = append(pipeline, bson.D{{Key: "$sort", Value: bson.M{"createdAt": -1}}})
-	pipeline = append(pipeline, bson.D{{Key: "$skip", Value: filter.Skip}})
-	pipeline = append(pipeline, bson.D{{Key: "$limit", Value: filter.Limit}})
+	return cursor.Err()
+}
+
+// Best matches first
+var searchSortKeys = []pagination.SortKey{
+	{Field: "score", Desc: true},
+	{Field: "_id", Desc: true},
+}
+
+// Searches the text index on name and address, scoring each farm by relevance
+func (r *MongoRepository) Search(
+	ctx context.Context,
+	filter *SearchFarmQuery,
+) (*pagination.Page[SearchResult], error) {
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.M{"$text": bson.M{"$search": filter.Q}, "deletedAt": nil}}},
+		bson.D{{Key: "$addFields", Value: bson.M{"score": bson.M{"$meta": "textScore"}}}},
+	}
+
+	return paginate(ctx, r, pipeline, false, searchSortKeys, filter.Params, func(res *SearchResult) (pagination.Cursor, error) {
+		id, err := farmCursor(&res.Farm, searchSortKeys[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		return append(pagination.Cursor{{Key: "score", Value: res.Score}}, id...), nil
+	})
+}
+
+// Sums up the farms matching the listing filters and their crops in a single
+// $facet, so that every figure is computed over the same farms
+func (r *MongoRepository) Stats(ctx context.Context, filter *ListFarmQuery) (*FarmTotals, error) {
+	pipeline, cropsJoined := filterPipeline(filter)
+	if !cropsJoined {
+		pipeline = append(pipeline, lookupCropsStage())
+	}
+
+	crops := bson.D{{Key: "$unwind", Value: "$crops"}}
+	count := func(field string) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{"$crops." + field, 1, 0}}}
+	}
+	first := func(facet string, field string) bson.M {
+		return bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$" + facet + "." + field, 0}}, 0}}
+	}
+
+	pipeline = append(pipeline,
+		bson.D{{Key: "$facet", Value: bson.M{
+			"farms": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":              nil,
+					"farms":            bson.M{"$sum": 1},
+					"landAreaHectares": bson.M{"$sum": "$landAreaHectares"},
+				}},
+			},
+			"crops": bson.A{
+				crops,
+				bson.M{"$group": bson.M{
+					"_id":       nil,
+					"crops":     bson.M{"$sum": 1},
+					"irrigated": count("isIrrigated"),
+					"insured":   count("isInsured"),
+				}},
+			},
+			"cropTypes": bson.A{
+				crops,
+				bson.M{"$group": bson.M{
+					"_id":   "$crops.type",
+					"crops": bson.M{"$sum": 1},
+					"farms": bson.M{"$addToSet": "$_id"},
+				}},
+				bson.M{"$project": bson.M{"crops": 1, "farms": bson.M{"$size": "$farms"}}},
+				bson.M{"$sort": bson.D{{Key: "crops", Value: -1}, {Key: "_id", Value: 1}}},
+			},
+			// Farms keep the unit name they were given, merged afterwards
+			"units": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":              bson.M{"$toLower": bson.M{"$trim": bson.M{"input": "$unitOfMeasurement"}}},
+					"farms":            bson.M{"$sum": 1},
+					"landArea":         bson.M{"$sum": "$landArea"},
+					"landAreaHectares": bson.M{"$sum": "$landAreaHectares"},
+				}},
+				bson.M{"$sort": bson.M{"_id": 1}},
+			},
+		}}},
+		bson.D{{Key: "$project", Value: bson.M{
+			"farms":            first("farms", "farms"),
+			"landAreaHectares": first("farms", "landAreaHectares"),
+			"crops":            first("crops", "crops"),
+			"irrigated":        first("crops", "irrigated"),
+			"insured":          first("crops", "insured"),
+			"cropTypes":        1,
+			"units":            1,
+		}}},
+	)
 
 	cursor, err := r.db.Collection("farms").Aggregate(ctx, pipeline)
 	if err != nil {
+		r.l.Error("error on farm stats", err)
+		return nil, err
+	}
+
+	var totals []FarmTotals
+	if err := cursor.All(ctx, &totals); err != nil {
 		return nil, err
 	}
+	if len(totals) == 0 {
+		return &FarmTotals{}, nil
+	}
+
+	return &totals[0], nil
+}
 
-	var farms []Farm
-	if err := cursor.All(ctx, &farms); err != nil {
+// Stages keeping the farms that match the listing filters, reporting whether
+// crops were joined
+func filterPipeline(filter *ListFarmQuery) (mongo.Pipeline, bool) {
+	match := bson.M{"deletedAt": nil}
+
+	landArea := bson.M{}
+	if !filter.LandAreaMin.IsZero() {
+		landArea["$gte"] = filter.LandAreaMin
+	}
+	if !filter.LandAreaMax.IsZero() {
+		landArea["$lte"] = filter.LandAreaMax
+	}
+	if len(landArea) > 0 {
+		match["landAreaHectares"] = landArea
+	}
+
+	// Any of the names of the unit, as farms keep the one they were given
+	if filter.UnitOfMeasurement != nil {
+		names := make([]string, 0)
+		for _, name := range filter.UnitOfMeasurement.Names() {
+			names = append(names, regexp.QuoteMeta(name))
+		}
+		match["unitOfMeasurement"] = primitive.Regex{Pattern: "^\\s*(" + strings.Join(names, "|") + ")\\s*$", Options: "i"}
+	}
+
+	for field, value := range map[string]string{"city": filter.City, "state": filter.State} {
+		if value != "" {
+			match["structuredAddress."+field] = primitive.Regex{Pattern: "^\\s*" + regexp.QuoteMeta(value) + "\\s*$", Options: "i"}
+		}
+	}
+
+	createdAt := bson.M{}
+	if !filter.CreatedFrom.IsZero() {
+		createdAt["$gte"] = filter.CreatedFrom
+	}
+	if !filter.CreatedBefore.IsZero() {
+		createdAt["$lt"] = filter.CreatedBefore
+	}
+	if len(createdAt) > 0 {
+		match["createdAt"] = createdAt
+	}
+
+	// $geoWithin rather than $near, which can not run inside a pipeline nor
+	// keep the requested sorting
+	var located bson.A
+	if filter.Near != nil {
+		located = append(located, bson.M{"location": bson.M{"$geoWithin": bson.M{
+			"$centerSphere": bson.A{filter.Near.Coordinates, geo.RadiansOf(filter.RadiusKm)},
+		}}})
+	}
+	if filter.Within != nil {
+		located = append(located, bson.M{"location": bson.M{"$geoWithin": bson.M{"$geometry": filter.Within}}})
+	}
+	if len(located) > 0 {
+		match["$and"] = located
+	}
+
+	pipeline := mongo.Pipeline{bson.D{{Key: "$match", Value: match}}}
+
+	// Crops are only joined before paginating when they are filtered on
+	cropsJoined := false
+	if crop := cropMatch(filter); len(crop) > 0 {
+		pipeline = append(pipeline, lookupCropsStage())
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"crops": bson.M{"$elemMatch": crop}}}})
+		cropsJoined = true
+	}
+
+	return pipeline, cropsJoined
+}
+
+// Conditions a single crop of the farm has to meet
+func cropMatch(filter *ListFarmQuery) bson.M {
+	match := bson.M{}
+	if len(filter.CropTypes) > 0 {
+		match["type"] = bson.M{"$in": filter.CropTypes}
+	}
+	if filter.IsIrrigated != nil {
+		match["isIrrigated"] = *filter.IsIrrigated
+	}
+	if filter.IsInsured != nil {
+		match["isInsured"] = *filter.IsInsured
+	}
+
+	return match
+}
+
+// Runs the filtering pipeline one page at a time, resuming after the cursor
+func paginate[T any](
+	ctx context.Context,
+	r *MongoRepository,
+	pipeline mongo.Pipeline,
+	cropsJoined bool,
+	keys []pagination.SortKey,
+	params pagination.Params,
+	cursorOf func(item *T) (pagination.Cursor, error),
+) (*pagination.Page[T], error) {
+	var total *int64
+	if params.WithTotal {
+		count, err := r.count(ctx, pipeline)
+		if err != nil {
+			return nil, err
+		}
+		total = &count
+	}
+
+	paged := append(mongo.Pipeline{}, pipeline...)
+	if coalesce := pagination.CoalesceStage(keys, sortDefaults); coalesce != nil {
+		paged = append(paged, coalesce)
+	}
+	if params.After != nil {
+		after, err := pagination.AfterFilter(keys, params.After)
+		if err != nil {
+			return nil, err
+		}
+		paged = append(paged, bson.D{{Key: "$match", Value: after}})
+	}
+
+	paged = append(paged, pagination.SortStage(keys))
+	paged = append(paged, bson.D{{Key: "$limit", Value: params.Limit + 1}})
+	if !cropsJoined {
+		paged = append(paged, lookupCropsStage())
+	}
+
+	cursor, err := r.db.Collection("farms").Aggregate(ctx, paged)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []T
+	if err := cursor.All(ctx, &items); err != nil {
 		r.l.Error("error on listing farms", err)
 		return nil, err
 	}
 
-	return farms, nil
+	page, err := pagination.NewPage(items, params.Limit, func(item T) (pagination.Cursor, error) {
+		return cursorOf(&item)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page.TotalCount = total
+	return page, nil
+}
+
+// Counts the documents matched by the filtering pipeline
+func (r *MongoRepository) count(ctx context.Context, pipeline mongo.Pipeline) (int64, error) {
+	counting := append(mongo.Pipeline{}, pipeline...)
+	counting = append(counting, bson.D{{Key: "$count", Value: "total"}})
+
+	cursor, err := r.db.Collection("farms").Aggregate(ctx, counting)
+	if err != nil {
+		return 0, err
+	}
+
+	var result []struct {
+		Total int64 `bson:"total"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
+		r.l.Error("error on counting farms", err)
+		return 0, err
+	}
+
+	if len(result) == 0 {
+		return 0, nil
+	}
+
+	return result[0].Total, nil
+}
+
+// Position of the farm in a listing sorted by the given keys
+func farmCursor(f *Farm, keys []pagination.SortKey) (pagination.Cursor, error) {
+	cursor := pagination.Cursor{}
+	for _, k := range keys {
+		var value interface{}
+		switch k.Field {
+		case "_id":
+			oid, err := ids.ToObjectID(f.ID)
+			if err != nil {
+				return nil, err
+			}
+			value = oid
+		case "name":
+			value = f.Name
+		case "landAreaHectares":
+			value = f.LandAreaHectares
+		case "createdAt":
+			value = f.CreatedAt
+		case "updatedAt":
+			value = f.UpdatedAt
+		case "deletedAt":
+			value = f.DeletedAt
+		default:
+			return nil, fmt.Errorf("farms can not be paginated by %s", k.Field)
+		}
+
+		cursor = append(cursor, bson.E{Key: k.Field, Value: value})
+	}
+
+	return cursor, nil
 }
 
 func (r *MongoRepository) GetByID(
@@ -94,16 +496,14 @@ func (r *MongoRepository) GetByID(
 	farmId string,
 ) (*Farm, error) {
 
-	oid, err := primitive.ObjectIDFromHex(farmId)
-
+	oid, err := ids.ToObjectID(farmId)
 	if err != nil {
-		r.l.Error("error on convert object id", err)
-		return nil, ErrOnConvertObjectID
+		return nil, err
 	}
 
 	var farm Farm
 
-	err = r.db.Collection("farms").FindOne(ctx, bson.M{"_id": oid}).Decode(&farm)
+	err = r.db.Collection("farms").FindOne(ctx, bson.M{"_id": oid, "deletedAt": nil}).Decode(&farm)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, ErrFarmNotFound
@@ -116,47 +516,248 @@ func (r *MongoRepository) GetByID(
 	return &farm, nil
 }
 
-func (r *MongoRepository) Update(ctx context.Context, farmId string, dto *UpdateFarmDTO) (string, error) {
-	fields := dto.ToMap()
-	fields["updatedAt"] = time.Now()
+func (r *MongoRepository) Update(
+	ctx context.Context,
+	farmId string,
+	dto *UpdateFarmDTO,
+	versions []int64,
+) (string, error) {
+	set := bson.M{}
+	r.timestamps.OnUpdate(set)
+	unset := bson.M{}
+	for k, v := range dto.ToMap() {
+		if v == nil {
+			unset[k] = ""
+		} else {
+			set[k] = v
+		}
+	}
 
-	oid, err := primitive.ObjectIDFromHex(farmId)
+	oid, err := ids.ToObjectID(farmId)
 	if err != nil {
-		r.l.Error("error on convert object id", err)
-		return "", ErrOnConvertObjectID
+		return "", err
 	}
 
-	filter := bson.M{"_id": oid}
-	update := bson.M{"$set": fields}
+	filter := matchVersions(bson.M{"_id": oid, "deletedAt": nil}, versions)
+	update := bson.M{"$set": set, "$inc": bson.M{"version": 1}}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
 
-	_, err = r.db.Collection("farms").UpdateOne(ctx, filter, update)
+	result, err := r.db.Collection("farms").UpdateOne(ctx, filter, update)
+	if isGeoKeyError(err) {
+		return "", r.geometryError(err)
+	}
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			r.l.Error("farm not found", err)
-			return "", ErrFarmNotFound
-		}
-
+		r.l.Error("error on update farm", err)
 		return "", err
 	}
 
+	if result.MatchedCount == 0 {
+		return "", r.missingOrModified(ctx, filter)
+	}
+
 	return oid.Hex(), nil
 }
 
 func (r *MongoRepository) Delete(
 	ctx context.Context,
 	farmId string,
+	versions []int64,
 ) error {
-	oid, err := primitive.ObjectIDFromHex(farmId)
+	oid, err := ids.ToObjectID(farmId)
 	if err != nil {
-		r.l.Error("error on convert object id", err)
-		return ErrOnConvertObjectID
+		return err
 	}
 
-	_, err = r.db.Collection("farms").DeleteOne(ctx, bson.M{"_id": oid})
+	filter := matchVersions(bson.M{"_id": oid}, versions)
+	result, err := r.db.Collection("farms").DeleteOne(ctx, filter)
 	if err != nil {
 		r.l.Error("error on delete farm", err)
 		return err
 	}
 
+	if result.DeletedCount == 0 {
+		return r.missingOrModified(ctx, filter)
+	}
+
 	return nil
 }
+
+// Flags the farm as deleted, hiding it from reads without removing it
+func (r *MongoRepository) SoftDelete(
+	ctx context.Context,
+	farmId string,
+	at time.Time,
+	versions []int64,
+) error {
+	oid, err := ids.ToObjectID(farmId)
+	if err != nil {
+		return err
+	}
+
+	set := bson.M{"deletedAt": at}
+	r.timestamps.OnUpdate(set)
+
+	filter := matchVersions(bson.M{"_id": oid, "deletedAt": nil}, versions)
+	update := bson.M{"$set": set, "$inc": bson.M{"version": 1}}
+
+	result, err := r.db.Collection("farms").UpdateOne(ctx, filter, update)
+	if err != nil {
+		r.l.Error("error on soft delete farm", err)
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return r.missingOrModified(ctx, filter)
+	}
+
+	return nil
+}
+
+// Lists the farms in the trash, most recently deleted first
+func (r *MongoRepository) ListDeleted(
+	ctx context.Context,
+	filter *ListFarmQuery,
+) (*pagination.Page[Farm], error) {
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.M{"deletedAt": bson.M{"$ne": nil}}}},
+	}
+
+	return paginate(ctx, r, pipeline, false, trashSortKeys, filter.Params, func(f *Farm) (pagination.Cursor, error) {
+		return farmCursor(f, trashSortKeys)
+	})
+}
+
+// Returns the ids of the farms soft deleted before the given time
+func (r *MongoRepository) ListDeletedBefore(
+	ctx context.Context,
+	before time.Time,
+) ([]string, error) {
+	filter := bson.M{"deletedAt": bson.M{"$ne": nil, "$lt": before}}
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+
+	cursor, err := r.db.Collection("farms").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		r.l.Error("error on listing expired deleted farms", err)
+		return nil, err
+	}
+
+	ids := make([]string, len(docs))
+	for i, d := range docs {
+		ids[i] = d.ID.Hex()
+	}
+
+	return ids, nil
+}
+
+// Takes the farm out of the trash, returning when it had been deleted
+func (r *MongoRepository) Restore(
+	ctx context.Context,
+	farmId string,
+) (time.Time, error) {
+	oid, err := ids.ToObjectID(farmId)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	set := bson.M{}
+	r.timestamps.OnUpdate(set)
+
+	filter := bson.M{"_id": oid, "deletedAt": bson.M{"$ne": nil}}
+	update := bson.M{"$set": set, "$unset": bson.M{"deletedAt": ""}, "$inc": bson.M{"version": 1}}
+
+	var farm Farm
+	err = r.db.Collection("farms").FindOneAndUpdate(ctx, filter, update).Decode(&farm)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return time.Time{}, ErrFarmNotFound
+		}
+
+		r.l.Error("error on restore farm", err)
+		return time.Time{}, err
+	}
+
+	return *farm.DeletedAt, nil
+}
+
+// Tells apart a farm that does not exist from one that is at another version
+// than the expected ones, after a write matched nothing
+func (r *MongoRepository) missingOrModified(ctx context.Context, filter bson.M) error {
+	if _, ok := filter["version"]; !ok {
+		return ErrFarmNotFound
+	}
+
+	withoutVersion := bson.M{}
+	for k, v := range filter {
+		if k != "version" {
+			withoutVersion[k] = v
+		}
+	}
+
+	count, err := r.db.Collection("farms").CountDocuments(ctx, withoutVersion)
+	if err != nil {
+		r.l.Error("error on checking farm existence", err)
+		return err
+	}
+
+	if count > 0 {
+		return ErrFarmVersionMismatch
+	}
+
+	return ErrFarmNotFound
+}
+
+// Restricts the filter to the expected versions, nil accepting any of them.
+// Farms created before versioning have no version and are at version 0.
+func matchVersions(filter bson.M, versions []int64) bson.M {
+	if versions == nil {
+		return filter
+	}
+
+	in := bson.A{}
+	for _, v := range versions {
+		in = append(in, v)
+		if v == 0 {
+			in = append(in, nil)
+		}
+	}
+
+	filter["version"] = bson.M{"$in": in}
+	return filter
+}
+
+// Raised by 2dsphere indexes on geometries they can not index, like self
+// intersecting rings
+const geoKeyErrorCode = 16755
+
+// Keeps the driver message, which quotes the stored document, out of responses
+func (r *MongoRepository) geometryError(err error) error {
+	r.l.Warn("Farm geometry rejected by the 2dsphere index", "error", err)
+	return fmt.Errorf("%w: %w", ErrInvalidFarmFields, ErrUnindexableGeometry)
+}
+
+// Inserts of a single farm raise a WriteException and bulk inserts a
+// BulkWriteException, both being server errors
+func isGeoKeyError(err error) bool {
+	var se mongo.ServerError
+	return errors.As(err, &se) && se.HasErrorCode(geoKeyErrorCode)
+}
+
+func lookupCropsStage() bson.D {
+	return bson.D{
+		{Key: "$lookup", Value: bson.M{
+			"from":         "crops",
+			"localField":   "_id",
+			"foreignField": "farmId",
+			"as":           "crops",
+		}},
+	}
+}
@@ -0,0 +1,55 @@
+package farms
+
+import (
+	"context"
+	"time"
+
+	"github.com/mateusfdl/go-api/adapters/logger"
+)
+
+// Periodically hard deletes the farms that stayed in the trash for longer
+// than the retention period
+type PurgeJob struct {
+	l         *logger.Logger
+	s         *Service
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewPurgeJob(l *logger.Logger, s *Service, cfg Config) *PurgeJob {
+	return &PurgeJob{
+		l:         l,
+		s:         s,
+		retention: time.Duration(cfg.TrashRetentionDays) * 24 * time.Hour,
+		interval:  time.Duration(cfg.PurgeIntervalMinutes) * time.Minute,
+	}
+}
+
+// Runs the purge right away and then on every interval until ctx is cancelled
+func (j *PurgeJob) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.run(ctx)
+
+		select {
+		case <-ctx.Done():
+			j.l.Info("Farm trash purge job stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *PurgeJob) run(ctx context.Context) {
+	purged, err := j.s.PurgeTrash(ctx, time.Now().Add(-j.retention))
+	if err != nil {
+		j.l.Error("Failed to purge farm trash", err)
+		return
+	}
+
+	if purged > 0 {
+		j.l.Info("Purged farms from trash", "count", purged)
+	}
+}
@@ -1,11 +1,37 @@
 package farms
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/mateusfdl/go-api/internal/pagination"
+)
 
 type Repository interface {
 	Create(ctx context.Context, dto *CreateFarmDTO) (string, error)
-	List(ctx context.Context, filter *ListFarmQuery) ([]Farm, error)
+	// Inserts the farms in a single unordered write, returning their ids in
+	// order. The ids are returned on failure too, left empty for the farms the
+	// server rejected, as the others may have been written.
+	CreateMany(ctx context.Context, dtos []*CreateFarmDTO) ([]string, error)
+	List(ctx context.Context, filter *ListFarmQuery) (*pagination.Page[Farm], error)
+	Search(ctx context.Context, filter *SearchFarmQuery) (*pagination.Page[SearchResult], error)
 	GetByID(ctx context.Context, id string) (*Farm, error)
-	Update(ctx context.Context, id string, dto *UpdateFarmDTO) (string, error)
-	Delete(ctx context.Context, id string) error
+	// Writes taking versions only apply when the farm is at one of them, nil
+	// accepting any version
+	Update(ctx context.Context, id string, dto *UpdateFarmDTO, versions []int64) (string, error)
+	Delete(ctx context.Context, id string, versions []int64) error
+	SoftDelete(ctx context.Context, id string, at time.Time, versions []int64) error
+	ListDeleted(ctx context.Context, filter *ListFarmQuery) (*pagination.Page[Farm], error)
+	Stats(ctx context.Context, filter *ListFarmQuery) (*FarmTotals, error)
+	// Calls fn with every farm matching the filters, stopping on its errors
+	Export(ctx context.Context, filter *ListFarmQuery, fn func(farm *Farm) error) error
+	ListDeletedBefore(ctx context.Context, before time.Time) ([]string, error)
+	Restore(ctx context.Context, id string) (time.Time, error)
+}
+
+// A collection holding documents owned by a farm, removed along with it
+type Dependent interface {
+	DeleteByFarm(ctx context.Context, farmId string) (int64, error)
+	SoftDeleteByFarm(ctx context.Context, farmId string, at time.Time) (int64, error)
+	RestoreByFarm(ctx context.Context, farmId string, at time.Time) (int64, error)
 }
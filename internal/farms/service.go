@@ -1,96 +1,622 @@
 package farms
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/mateusfdl/go-api/adapters/logger"
+	mongo_adapter "github.com/mateusfdl/go-api/adapters/mongo"
+	"github.com/mateusfdl/go-api/internal/address"
 	"github.com/mateusfdl/go-api/internal/crops"
+	"github.com/mateusfdl/go-api/internal/decimal"
+	"github.com/mateusfdl/go-api/internal/pagination"
+	"github.com/mateusfdl/go-api/internal/patch"
+	"github.com/mateusfdl/go-api/internal/search"
+	"github.com/mateusfdl/go-api/internal/units"
+	"github.com/mateusfdl/go-api/internal/validation"
 )
 
+type Transactor interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type Service struct {
 	l              *logger.Logger
 	farmRepository Repository
 	cropRepository crops.Repository
+	cropTypes      crops.TypeCatalog
+	transactor     Transactor
+	dependents     []namedDependent
+	cfg            Config
+}
+
+type namedDependent struct {
+	name string
+	Dependent
+}
+
+func NewService(
+	l *logger.Logger,
+	farmRepo Repository,
+	cropRepo *crops.Repository,
+	cropTypes crops.TypeCatalog,
+	transactor Transactor,
+	cfg Config,
+) *Service {
+	return &Service{
+		l:              l,
+		farmRepository: farmRepo,
+		cropRepository: *cropRepo,
+		cropTypes:      cropTypes,
+		transactor:     transactor,
+		cfg:            cfg,
+	}
 }
 
-func NewService(l *logger.Logger, farmRepo Repository, cropRepo *crops.Repository) *Service {
-	return &Service{l: l, farmRepository: farmRepo, cropRepository: *cropRepo}
+// Registers a collection whose documents are deleted in cascade with their farm
+func (s *Service) RegisterDependent(name string, d Dependent) {
+	s.dependents = append(s.dependents, namedDependent{name: name, Dependent: d})
 }
 
+// Creates the farm along with its crops atomically, either inside a mongo
+// transaction or, on standalone servers, by compensating the farm insert
 func (s *Service) CreateFarm(ctx context.Context, dto *CreateFarmDTO) (string, error) {
-	if err := validateFields(dto); err != nil {
-		return "", ErrInvalidFarmFields
+	if err := validateFields(dto, s.cfg, s.cropTypes); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidFarmFields, err)
+	}
+
+	if err := allocateCrops(dto); err != nil {
+		return "", err
+	}
+
+	return s.persistFarm(ctx, dto)
+}
+
+// Writes a validated farm along with its crops
+func (s *Service) persistFarm(ctx context.Context, dto *CreateFarmDTO) (string, error) {
+	var id string
+	err := s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		id, err = s.createFarmWithCrops(ctx, dto)
+		return err
+	})
+	if errors.Is(err, mongo_adapter.ErrTransactionsNotSupported) {
+		s.l.Warn("Transactions are not supported, falling back to compensating writes")
+		return s.createFarmWithCompensation(ctx, dto)
+	}
+	if err != nil {
+		return "", err
 	}
 
+	return id, nil
+}
+
+func (s *Service) createFarmWithCrops(ctx context.Context, dto *CreateFarmDTO) (string, error) {
 	id, err := s.farmRepository.Create(ctx, dto)
 	if err != nil {
 		return "", err
 	}
 
-	if len(*dto.Crops) == 0 {
+	if dto.Crops == nil || len(*dto.Crops) == 0 {
 		return id, nil
 	}
 
 	err = s.cropRepository.CreateMany(ctx, id, dto.Crops)
 	if err != nil {
-		return id, errors.New("failed to bulk persist crops")
+		return id, fmt.Errorf("%w: %w", ErrOnPersistCrops, err)
 	}
 
 	return id, nil
 }
 
-func validateFields(dto *CreateFarmDTO) error {
-	if dto.Name == "" {
-		return errors.New("name is required")
+// Undoes the farm insert, and any crop persisted before the failure, when the
+// crops could not be created
+func (s *Service) createFarmWithCompensation(ctx context.Context, dto *CreateFarmDTO) (string, error) {
+	id, err := s.createFarmWithCrops(ctx, dto)
+	if err == nil {
+		return id, nil
+	}
+
+	if id != "" {
+		s.rollBackFarm(ctx, id)
+	}
+
+	return "", err
+}
+
+// Removes a farm written without transaction and any crop persisted with it
+func (s *Service) rollBackFarm(ctx context.Context, id string) {
+	if _, err := s.cropRepository.DeleteByFarm(ctx, id); err != nil {
+		s.l.Error("Failed to roll back crops", "farmId", id, err)
 	}
 
-	if dto.LandArea == 0 {
-		return errors.New("land area is required")
+	if err := s.farmRepository.Delete(ctx, id, nil); err != nil && !errors.Is(err, ErrFarmNotFound) {
+		s.l.Error("Failed to roll back farm", "farmId", id, err)
 	}
+}
+
+func validateFields(dto *CreateFarmDTO, cfg Config, cropTypes crops.TypeCatalog) error {
+	var errs validation.Errors
+	validateFarmFields(&errs, dto.farmFields(), cfg)
 
-	if dto.UnitOfMeasurement == "" {
-		return errors.New("unit of measurement is required")
+	if dto.Crops != nil {
+		for i := range *dto.Crops {
+			crops.ValidateCrop(&errs, fmt.Sprintf("crops[%d]", i), &(*dto.Crops)[i], cropTypes, cfg.LandAreaPrecision)
+		}
 	}
 
-	if dto.Address == "" {
-		return errors.New("address is required")
+	return errs.Err()
+}
+
+// Crops created along with the farm take its unit unless they name one, and
+// must fit its land area altogether
+func allocateCrops(dto *CreateFarmDTO) error {
+	if dto.Crops == nil {
+		return nil
 	}
 
-	if dto.Crops != nil && len(*dto.Crops) > 0 {
-		for _, crop := range *dto.Crops {
-			if crop.Type == "" {
-				return errors.New("crop type is required")
-			}
+	var planted decimal.Decimal
+	for i := range *dto.Crops {
+		crop := &(*dto.Crops)[i]
+		crop.InheritUnit(dto.UnitOfMeasurement)
+		if hectares := crop.PlantedAreaHectares(); hectares != nil {
+			planted = planted.Add(*hectares)
+		}
+	}
 
-			validCropType := false
-			for _, cType := range crops.CropTypes {
-				if crop.Type == cType {
-					validCropType = true
-					break
-				}
-			}
+	landArea, _ := landAreaHectares(*dto.LandArea, dto.UnitOfMeasurement).(decimal.Decimal)
+	return crops.CheckAllocation(landArea, planted)
+}
 
-			if !validCropType {
-				return errors.New("invalid crop type")
-			}
+// Replacements are validated like creations, as every field is overwritten
+func validateUpdateFields(dto *UpdateFarmDTO, cfg Config) error {
+	var errs validation.Errors
+	validateFarmFields(&errs, dto, cfg)
+	return errs.Err()
+}
+
+// Land areas are limited to the configured decimal places
+func validateFarmFields(errs *validation.Errors, dto *UpdateFarmDTO, cfg Config) {
+	precision := cfg.LandAreaPrecision
+	errs.Required("name", dto.Name)
+
+	if dto.LandArea == nil {
+		errs.Add("landArea", validation.CodeRequired, "landArea is required")
+	} else if dto.LandArea.Sign() <= 0 {
+		errs.Add("landArea", validation.CodePositive, "landArea must be positive")
+	} else if dto.LandArea.Places() > precision {
+		errs.Add("landArea", validation.CodeInvalid, fmt.Sprintf("landArea must have at most %d decimal places", precision))
+	}
+
+	if strings.TrimSpace(dto.UnitOfMeasurement) == "" {
+		errs.Add("unitOfMeasurement", validation.CodeRequired, "unitOfMeasurement is required")
+	} else if _, err := units.Default.Lookup(dto.UnitOfMeasurement); err != nil {
+		errs.Add("unitOfMeasurement", validation.CodeInvalid, "unitOfMeasurement must be one of "+strings.Join(units.Default.Symbols(), ", "))
+	}
+
+	validateAddress(errs, dto.Address)
+	validateGeometry(errs, dto, cfg.BoundaryTolerance)
+}
+
+// Lines only have to be given, parts need at least the city and state that
+// farms are filtered on
+func validateAddress(errs *validation.Errors, in address.Input) {
+	if !in.IsStructured() {
+		errs.Required("address", in.Line)
+		return
+	}
+
+	errs.Required(validation.Field("address", "city"), in.Parts.City)
+	errs.Required(validation.Field("address", "state"), in.Parts.State)
+}
+
+// The boundary must enclose the land area, give or take tolerance percent,
+// and the location must lie within the boundary
+func validateGeometry(errs *validation.Errors, dto *UpdateFarmDTO, tolerance int) {
+	locationValid := false
+	if dto.Location != nil {
+		if err := dto.Location.Validate(); err != nil {
+			errs.Add("location", validation.CodeInvalid, err.Error())
+		} else {
+			locationValid = true
 		}
 	}
-	return nil
+
+	if dto.Boundary == nil {
+		return
+	}
+	if err := dto.Boundary.Validate(); err != nil {
+		errs.Add("boundary", validation.CodeInvalid, err.Error())
+		return
+	}
+
+	if locationValid && !dto.Boundary.Contains(*dto.Location) {
+		errs.Add("location", validation.CodeInvalid, "location must lie within the boundary")
+	}
+
+	if tolerance <= 0 || dto.LandArea == nil {
+		return
+	}
+	landArea, ok := landAreaHectares(*dto.LandArea, dto.UnitOfMeasurement).(decimal.Decimal)
+	if !ok || landArea.Sign() <= 0 {
+		return
+	}
+
+	enclosed := dto.Boundary.AreaHectares()
+	deviation := math.Abs(enclosed-landArea.Float64()) / landArea.Float64() * 100
+	if deviation > float64(tolerance) {
+		errs.Add("boundary", validation.CodeInvalid, fmt.Sprintf(
+			"boundary encloses %v ha, more than %d%% off the land area of %v ha",
+			units.Round(enclosed, 2), tolerance, units.Round(landArea.Float64(), 2),
+		))
+	}
 }
 
-func (s *Service) ListFarms(ctx context.Context, f *ListFarmQuery) ([]Farm, error) {
+func (s *Service) ListFarms(ctx context.Context, f *ListFarmQuery) (*pagination.Page[Farm], error) {
 	return s.farmRepository.List(ctx, f)
 }
 
+// Searches farms by name and address, highlighting the matched words
+func (s *Service) SearchFarms(ctx context.Context, f *SearchFarmQuery) (*pagination.Page[SearchResult], error) {
+	page, err := s.farmRepository.Search(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := search.Terms(f.Q)
+	for i := range page.Items {
+		res := &page.Items[i]
+		res.Highlights = make(map[string]string)
+		if name, ok := search.Highlight(res.Name, terms); ok {
+			res.Highlights["name"] = name
+		}
+		if address, ok := search.Highlight(res.Address, terms); ok {
+			res.Highlights["address"] = address
+		}
+	}
+
+	return page, nil
+}
+
 func (s *Service) GetByID(ctx context.Context, id string) (*Farm, error) {
 	return s.farmRepository.GetByID(ctx, id)
 }
 
-func (s *Service) UpdateFarm(ctx context.Context, id string, dto *UpdateFarmDTO) (string, error) {
-	return s.farmRepository.Update(ctx, id, dto)
+// Summarizes how much of the land area of the farm is planted, per crop type.
+// Areas are expressed in unit, or in the unit of the farm when nil.
+func (s *Service) GetUtilization(ctx context.Context, id string, unit *units.Unit) (*Utilization, error) {
+	farm, err := s.farmRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	planted, err := s.cropRepository.PlantedAreaByType(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if unit == nil {
+		u, err := units.Default.Lookup(farm.UnitOfMeasurement)
+		if err != nil {
+			u = units.Hectare
+		}
+		unit = &u
+	}
+
+	places := s.cfg.LandAreaPrecision
+	convert := func(hectares decimal.Decimal) float64 {
+		return units.Round(unit.FromHectares(hectares.Float64()), places)
+	}
+	percentage := func(hectares decimal.Decimal) float64 {
+		if farm.LandAreaHectares.IsZero() {
+			return 0
+		}
+		return units.Round(hectares.Float64()/farm.LandAreaHectares.Float64()*100, 2)
+	}
+
+	var total decimal.Decimal
+	result := &Utilization{FarmID: farm.ID, Unit: unit.Symbol, CropTypes: []CropTypeUtilization{}}
+	for _, p := range planted {
+		total = total.Add(p.Hectares)
+		result.CropTypes = append(result.CropTypes, CropTypeUtilization{
+			Type:              p.Type,
+			Crops:             p.Crops,
+			PlantedArea:       convert(p.Hectares),
+			PlantedPercentage: percentage(p.Hectares),
+		})
+	}
+
+	result.LandArea = convert(farm.LandAreaHectares)
+	result.PlantedArea = convert(total)
+	result.AvailableArea = convert(farm.LandAreaHectares.Sub(total))
+	result.PlantedPercentage = percentage(total)
+
+	return result, nil
 }
 
-func (s *Service) DeleteFarm(ctx context.Context, id string) error {
-	return s.farmRepository.Delete(ctx, id)
+// Sums up the farms matching the filters and their crops. Land areas are
+// expressed in unit, hectares by default.
+func (s *Service) GetStats(ctx context.Context, filter *ListFarmQuery, unit *units.Unit) (*Stats, error) {
+	totals, err := s.farmRepository.Stats(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if unit == nil {
+		unit = &units.Hectare
+	}
+
+	places := s.cfg.LandAreaPrecision
+	convert := func(hectares float64) float64 {
+		return units.Round(unit.FromHectares(hectares), places)
+	}
+
+	stats := &Stats{
+		Unit:                unit.Symbol,
+		TotalFarms:          totals.Farms,
+		TotalLandArea:       convert(totals.LandAreaHectares.Float64()),
+		TotalCrops:          totals.Crops,
+		IrrigatedPercentage: percentageOf(totals.Irrigated, totals.Crops),
+		InsuredPercentage:   percentageOf(totals.Insured, totals.Crops),
+		CropTypes:           []CropTypeStats{},
+		Units:               []UnitStats{},
+	}
+	if totals.Farms > 0 {
+		stats.AverageLandArea = convert(totals.LandAreaHectares.Float64() / float64(totals.Farms))
+	}
+
+	for _, t := range totals.CropTypes {
+		stats.CropTypes = append(stats.CropTypes, CropTypeStats{
+			Type:       t.Type,
+			Crops:      t.Crops,
+			Farms:      t.Farms,
+			Percentage: percentageOf(t.Crops, totals.Crops),
+		})
+	}
+
+	for _, u := range mergeUnitTotals(totals.Units) {
+		stats.Units = append(stats.Units, UnitStats{
+			Unit:              u.Unit,
+			Farms:             u.Farms,
+			LandArea:          units.Round(u.LandArea.Float64(), places),
+			ConvertedLandArea: convert(u.LandAreaHectares.Float64()),
+			Percentage:        percentageOf(u.Farms, totals.Farms),
+		})
+	}
+
+	return stats, nil
+}
+
+// Merges the totals of the names of a same unit under its symbol. Unknown
+// units are kept as named.
+func mergeUnitTotals(totals []LandAreaUnitTotals) []LandAreaUnitTotals {
+	merged := []LandAreaUnitTotals{}
+	index := make(map[string]int)
+	for _, t := range totals {
+		if u, err := units.Default.Lookup(t.Unit); err == nil {
+			t.Unit = u.Symbol
+		}
+
+		i, ok := index[t.Unit]
+		if !ok {
+			index[t.Unit] = len(merged)
+			merged = append(merged, t)
+			continue
+		}
+
+		merged[i].Farms += t.Farms
+		merged[i].LandArea = merged[i].LandArea.Add(t.LandArea)
+		merged[i].LandAreaHectares = merged[i].LandAreaHectares.Add(t.LandAreaHectares)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].Farms > merged[j].Farms })
+	return merged
+}
+
+func percentageOf(part int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return units.Round(float64(part)/float64(total)*100, 2)
+}
+
+// Applies a JSON Merge Patch to the farm. The patched farm is validated as a
+// full replacement, so required fields can not be removed with null. The
+// patch is written only if the farm did not change since it was read.
+func (s *Service) PatchFarm(ctx context.Context, id string, mergePatch []byte, versions []int64) (*Farm, error) {
+	farm, err := s.farmRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkVersion(farm, versions); err != nil {
+		return nil, err
+	}
+
+	current, err := json.Marshal(NewUpdateFarmDTO(farm))
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := patch.MergePatch(current, mergePatch)
+	if err != nil {
+		return nil, err
+	}
+
+	var dto UpdateFarmDTO
+	d := json.NewDecoder(bytes.NewReader(patched))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&dto); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFarmFields, err)
+	}
+
+	return s.UpdateFarm(ctx, id, &dto, []int64{farm.Version})
+}
+
+// Replaces the farm fields, returning its representation after the update
+func (s *Service) UpdateFarm(ctx context.Context, id string, dto *UpdateFarmDTO, versions []int64) (*Farm, error) {
+	if err := validateUpdateFields(dto, s.cfg); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFarmFields, err)
+	}
+
+	// The farm can not shrink below the area its crops take
+	planted, err := s.cropRepository.SumPlantedArea(ctx, id, "")
+	if err != nil {
+		return nil, err
+	}
+	landArea, _ := landAreaHectares(*dto.LandArea, dto.UnitOfMeasurement).(decimal.Decimal)
+	if err := crops.CheckAllocation(landArea, planted); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.farmRepository.Update(ctx, id, dto, versions); err != nil {
+		return nil, err
+	}
+
+	return s.farmRepository.GetByID(ctx, id)
+}
+
+// Deletes the farm and every document registered as its dependent, reporting
+// how many of them were removed per collection
+func (s *Service) DeleteFarm(ctx context.Context, id string, soft bool, versions []int64) (*DeleteFarmResult, error) {
+	// Checked up front so that dependents are left alone on a conflict, even
+	// without transactions
+	if versions != nil {
+		farm, err := s.farmRepository.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := checkVersion(farm, versions); err != nil {
+			return nil, err
+		}
+	}
+
+	var result *DeleteFarmResult
+	err := s.runInTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = s.deleteFarmCascade(ctx, id, soft, versions)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Children are removed before the farm so that a failure midway can be
+// completed by retrying the deletion
+func (s *Service) deleteFarmCascade(ctx context.Context, id string, soft bool, versions []int64) (*DeleteFarmResult, error) {
+	result := &DeleteFarmResult{ID: id, Soft: soft, Deleted: make(map[string]int64)}
+	at := time.Now()
+
+	for _, d := range s.dependents {
+		var count int64
+		var err error
+		if soft {
+			count, err = d.SoftDeleteByFarm(ctx, id, at)
+		} else {
+			count, err = d.DeleteByFarm(ctx, id)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete %s of farm: %w", d.name, err)
+		}
+
+		result.Deleted[d.name] = count
+	}
+
+	if soft {
+		return result, s.farmRepository.SoftDelete(ctx, id, at, versions)
+	}
+
+	return result, s.farmRepository.Delete(ctx, id, versions)
+}
+
+// Fails when the farm is not at one of the expected versions, nil accepting any
+func checkVersion(farm *Farm, versions []int64) error {
+	if versions == nil {
+		return nil
+	}
+
+	for _, v := range versions {
+		if v == farm.Version {
+			return nil
+		}
+	}
+
+	return ErrFarmVersionMismatch
+}
+
+func (s *Service) ListTrash(ctx context.Context, f *ListFarmQuery) (*pagination.Page[Farm], error) {
+	return s.farmRepository.ListDeleted(ctx, f)
+}
+
+// Takes a soft deleted farm out of the trash along with the dependents that
+// were deleted together with it
+func (s *Service) RestoreFarm(ctx context.Context, id string) (*RestoreFarmResult, error) {
+	var result *RestoreFarmResult
+	err := s.runInTransaction(ctx, func(ctx context.Context) error {
+		at, err := s.farmRepository.Restore(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		result = &RestoreFarmResult{ID: id, Restored: make(map[string]int64)}
+		for _, d := range s.dependents {
+			count, err := d.RestoreByFarm(ctx, id, at)
+			if err != nil {
+				return fmt.Errorf("failed to restore %s of farm: %w", d.name, err)
+			}
+
+			result.Restored[d.name] = count
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Permanently deletes the farms that were moved to the trash before the given
+// time, returning how many were purged
+func (s *Service) PurgeTrash(ctx context.Context, before time.Time) (int, error) {
+	ids, err := s.farmRepository.ListDeletedBefore(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, id := range ids {
+		if _, err := s.DeleteFarm(ctx, id, false, nil); err != nil {
+			return purged, err
+		}
+
+		purged++
+	}
+
+	return purged, nil
+}
+
+// Runs fn inside a transaction, or directly when the deployment does not
+// support them
+func (s *Service) runInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := s.transactor.WithTransaction(ctx, fn)
+	if errors.Is(err, mongo_adapter.ErrTransactionsNotSupported) {
+		s.l.Debug("Transactions are not supported, running without transaction")
+		return fn(ctx)
+	}
+
+	return err
 }
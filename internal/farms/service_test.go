@@ -0,0 +1,330 @@
+package farms_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mateusfdl/go-api/adapters/logger"
+	mongo_adapter "github.com/mateusfdl/go-api/adapters/mongo"
+	"github.com/mateusfdl/go-api/internal/address"
+	"github.com/mateusfdl/go-api/internal/crops"
+	"github.com/mateusfdl/go-api/internal/decimal"
+	"github.com/mateusfdl/go-api/internal/farms"
+	"github.com/mateusfdl/go-api/internal/geo"
+)
+
+type fakeFarmRepository struct {
+	farms.Repository
+	// Name of the farms the server rejects
+	rejected string
+	created  []string
+	deleted  []string
+	expired  []string
+	version  int64
+	totals   farms.FarmTotals
+}
+
+func (r *fakeFarmRepository) Stats(ctx context.Context, filter *farms.ListFarmQuery) (*farms.FarmTotals, error) {
+	return &r.totals, nil
+}
+
+func (r *fakeFarmRepository) GetByID(ctx context.Context, id string) (*farms.Farm, error) {
+	return &farms.Farm{ID: id, Version: r.version}, nil
+}
+
+var errFarmRejected = errors.New("farm rejected")
+
+func (r *fakeFarmRepository) Create(ctx context.Context, dto *farms.CreateFarmDTO) (string, error) {
+	if r.rejected != "" && dto.Name == r.rejected {
+		return "", errFarmRejected
+	}
+
+	id := "6740c2d1e4b0a1a2b3c4d5e6"
+	r.created = append(r.created, id)
+	return id, nil
+}
+
+func (r *fakeFarmRepository) CreateMany(ctx context.Context, dtos []*farms.CreateFarmDTO) ([]string, error) {
+	ids := make([]string, len(dtos))
+	var err error
+	for i, dto := range dtos {
+		if r.rejected != "" && dto.Name == r.rejected {
+			err = errFarmRejected
+			continue
+		}
+		ids[i] = fmt.Sprintf("6740c2d1e4b0a1a2b3c4%04x", len(r.created))
+		r.created = append(r.created, ids[i])
+	}
+
+	return ids, err
+}
+
+func (r *fakeFarmRepository) Delete(ctx context.Context, id string, versions []int64) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+func (r *fakeFarmRepository) ListDeletedBefore(ctx context.Context, before time.Time) ([]string, error) {
+	return r.expired, nil
+}
+
+type fakeCropRepository struct {
+	crops.Repository
+	createErr error
+	deleted   []string
+}
+
+func (r *fakeCropRepository) CreateMany(ctx context.Context, farmId string, dtos *[]crops.CreateCropDTO) error {
+	return r.createErr
+}
+
+func (r *fakeCropRepository) CreateForFarms(ctx context.Context, dtos map[string]*[]crops.CreateCropDTO) error {
+	return r.createErr
+}
+
+func (r *fakeCropRepository) DeleteByFarm(ctx context.Context, farmId string) (int64, error) {
+	r.deleted = append(r.deleted, farmId)
+	return 0, nil
+}
+
+type fakeTransactor struct {
+	err error
+}
+
+func (t *fakeTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if t.err != nil {
+		return t.err
+	}
+
+	return fn(ctx)
+}
+
+func newCreateFarmDTO() *farms.CreateFarmDTO {
+	landArea := decimal.MustParse("29")
+	return &farms.CreateFarmDTO{
+		Name:              "Farm 1",
+		Address:           address.FromLine("Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil"),
+		LandArea:          &landArea,
+		UnitOfMeasurement: "hectares",
+		Crops:             &[]crops.CreateCropDTO{{Type: crops.CropTypeCorn}},
+	}
+}
+
+func TestCreateFarmCompensatesOnStandaloneServers(t *testing.T) {
+	l := logger.New(logger.Config{Level: "error"})
+	farmRepo := &fakeFarmRepository{}
+	var cropRepo crops.Repository = &fakeCropRepository{createErr: errors.New("write failed")}
+	s := farms.NewService(l, farmRepo, &cropRepo, crops.BuiltinTypes{}, &fakeTransactor{err: mongo_adapter.ErrTransactionsNotSupported}, farms.Config{})
+
+	id, err := s.CreateFarm(context.Background(), newCreateFarmDTO())
+
+	if !errors.Is(err, farms.ErrOnPersistCrops) {
+		t.Fatalf("Expect ErrOnPersistCrops, but got %v", err)
+	}
+	if id != "" {
+		t.Errorf("Expect empty id, but got '%s'", id)
+	}
+	if len(farmRepo.deleted) != 1 || farmRepo.deleted[0] != farmRepo.created[0] {
+		t.Errorf("Expect created farm to be rolled back, but got %v", farmRepo.deleted)
+	}
+	if len(cropRepo.(*fakeCropRepository).deleted) != 1 {
+		t.Errorf("Expect crops of the farm to be rolled back")
+	}
+}
+
+func TestCreateFarmInsideTransaction(t *testing.T) {
+	l := logger.New(logger.Config{Level: "error"})
+	farmRepo := &fakeFarmRepository{}
+	var cropRepo crops.Repository = &fakeCropRepository{}
+	s := farms.NewService(l, farmRepo, &cropRepo, crops.BuiltinTypes{}, &fakeTransactor{}, farms.Config{})
+
+	id, err := s.CreateFarm(context.Background(), newCreateFarmDTO())
+
+	if err != nil {
+		t.Fatalf("CreateFarm() failed: %v", err)
+	}
+	if id != farmRepo.created[0] {
+		t.Errorf("Expect id '%s', but got '%s'", farmRepo.created[0], id)
+	}
+	if len(farmRepo.deleted) != 0 {
+		t.Errorf("Expect no rollback, but got %v", farmRepo.deleted)
+	}
+}
+
+func TestCreateFarmValidatesLandAreaPrecision(t *testing.T) {
+	l := logger.New(logger.Config{Level: "error"})
+	var cropRepo crops.Repository = &fakeCropRepository{}
+	s := farms.NewService(l, &fakeFarmRepository{}, &cropRepo, crops.BuiltinTypes{}, &fakeTransactor{}, farms.Config{LandAreaPrecision: 2})
+
+	cases := map[string]bool{"12.5": true, "12.25": true, "12.250": true, "12.125": false, "0": false, "-1": false}
+	for landArea, valid := range cases {
+		dto := newCreateFarmDTO()
+		d := decimal.MustParse(landArea)
+		dto.LandArea = &d
+
+		_, err := s.CreateFarm(context.Background(), dto)
+		if valid && err != nil {
+			t.Errorf("Expect land area %s to be accepted, but got %v", landArea, err)
+		}
+		if !valid && !errors.Is(err, farms.ErrInvalidFarmFields) {
+			t.Errorf("Expect land area %s to be rejected, but got %v", landArea, err)
+		}
+	}
+}
+
+func TestCreateFarmChecksBoundary(t *testing.T) {
+	l := logger.New(logger.Config{Level: "error"})
+	var cropRepo crops.Repository = &fakeCropRepository{}
+	s := farms.NewService(l, &fakeFarmRepository{}, &cropRepo, crops.BuiltinTypes{}, &fakeTransactor{}, farms.Config{LandAreaPrecision: 2, BoundaryTolerance: 10})
+
+	// About 100 hectares
+	boundary := geo.NewPolygon([][]float64{
+		{-51.2300, -30.0300}, {-51.2196, -30.0300}, {-51.2196, -30.0210}, {-51.2300, -30.0210}, {-51.2300, -30.0300},
+	})
+	inside, outside := geo.NewPoint(-51.225, -30.025), geo.NewPoint(-51.24, -30.025)
+
+	cases := []struct {
+		name     string
+		landArea string
+		location *geo.Point
+		valid    bool
+	}{
+		{"matching land area", "100", &inside, true},
+		{"land area within tolerance", "92", nil, true},
+		{"land area off by more than the tolerance", "29", nil, false},
+		{"location outside the boundary", "100", &outside, false},
+	}
+	for _, c := range cases {
+		dto := newCreateFarmDTO()
+		d := decimal.MustParse(c.landArea)
+		dto.LandArea = &d
+		dto.Boundary = &boundary
+		dto.Location = c.location
+
+		_, err := s.CreateFarm(context.Background(), dto)
+		if c.valid && err != nil {
+			t.Errorf("Expect %s to be accepted, but got %v", c.name, err)
+		}
+		if !c.valid && !errors.Is(err, farms.ErrInvalidFarmFields) {
+			t.Errorf("Expect %s to be rejected, but got %v", c.name, err)
+		}
+	}
+}
+
+func TestCreateFarmRejectsOverAllocatedCrops(t *testing.T) {
+	l := logger.New(logger.Config{Level: "error"})
+	farmRepo := &fakeFarmRepository{}
+	var cropRepo crops.Repository = &fakeCropRepository{}
+	s := farms.NewService(l, farmRepo, &cropRepo, crops.BuiltinTypes{}, &fakeTransactor{}, farms.Config{})
+
+	// 29 hectares hold 20 hectares and 20 acres, about 8.09 hectares, but not 10 more
+	dto := newCreateFarmDTO()
+	areas := []struct{ area, unit string }{{"20", ""}, {"20", "acres"}, {"10", ""}}
+	*dto.Crops = nil
+	for _, a := range areas {
+		area := decimal.MustParse(a.area)
+		*dto.Crops = append(*dto.Crops, crops.CreateCropDTO{Type: crops.CropTypeCorn, PlantedArea: &area, UnitOfMeasurement: a.unit})
+	}
+
+	_, err := s.CreateFarm(context.Background(), dto)
+	if !errors.Is(err, crops.ErrPlantedAreaExceedsLandArea) {
+		t.Fatalf("Expect ErrPlantedAreaExceedsLandArea, but got %v", err)
+	}
+	if len(farmRepo.created) != 0 {
+		t.Errorf("Expect no farm to be created, but got %v", farmRepo.created)
+	}
+
+	*dto.Crops = (*dto.Crops)[:2]
+	if _, err := s.CreateFarm(context.Background(), dto); err != nil {
+		t.Fatalf("CreateFarm() failed: %v", err)
+	}
+	if (*dto.Crops)[0].UnitOfMeasurement != "hectares" {
+		t.Errorf("Expect crop to inherit the unit of the farm, but got '%s'", (*dto.Crops)[0].UnitOfMeasurement)
+	}
+}
+
+func TestPurgeTrashDeletesExpiredFarms(t *testing.T) {
+	l := logger.New(logger.Config{Level: "error"})
+	farmRepo := &fakeFarmRepository{expired: []string{"6740c2d1e4b0a1a2b3c4d5e6", "6740c2d1e4b0a1a2b3c4d5e7"}}
+	cropRepo := &fakeCropRepository{}
+	var repo crops.Repository = cropRepo
+	s := farms.NewService(l, farmRepo, &repo, crops.BuiltinTypes{}, &fakeTransactor{}, farms.Config{})
+	s.RegisterDependent("crops", cropRepo)
+
+	purged, err := s.PurgeTrash(context.Background(), time.Now())
+
+	if err != nil {
+		t.Fatalf("PurgeTrash() failed: %v", err)
+	}
+	if purged != 2 {
+		t.Errorf("Expect 2 purged farms, but got %d", purged)
+	}
+	if len(farmRepo.deleted) != 2 || len(cropRepo.deleted) != 2 {
+		t.Errorf("Expect farms and their crops to be deleted, but got %v and %v", farmRepo.deleted, cropRepo.deleted)
+	}
+}
+
+func TestDeleteFarmLeavesDependentsOnVersionMismatch(t *testing.T) {
+	l := logger.New(logger.Config{Level: "error"})
+	farmRepo := &fakeFarmRepository{version: 3}
+	cropRepo := &fakeCropRepository{}
+	var repo crops.Repository = cropRepo
+	s := farms.NewService(l, farmRepo, &repo, crops.BuiltinTypes{}, &fakeTransactor{}, farms.Config{})
+	s.RegisterDependent("crops", cropRepo)
+
+	_, err := s.DeleteFarm(context.Background(), "6740c2d1e4b0a1a2b3c4d5e6", false, []int64{2})
+
+	if !errors.Is(err, farms.ErrFarmVersionMismatch) {
+		t.Fatalf("Expect ErrFarmVersionMismatch, but got %v", err)
+	}
+	if len(farmRepo.deleted) != 0 || len(cropRepo.deleted) != 0 {
+		t.Errorf("Expect nothing to be deleted, but got %v and %v", farmRepo.deleted, cropRepo.deleted)
+	}
+}
+
+func TestGetStatsMergesUnitNames(t *testing.T) {
+	l := logger.New(logger.Config{Level: "error"})
+	farmRepo := &fakeFarmRepository{totals: farms.FarmTotals{
+		Farms:            4,
+		LandAreaHectares: decimal.MustParse("140.4685"),
+		Crops:            8,
+		Irrigated:        2,
+		Insured:          6,
+		CropTypes:        []farms.CropTypeTotals{{Type: "CORN", Crops: 6, Farms: 3}, {Type: "RICE", Crops: 2, Farms: 1}},
+		Units: []farms.LandAreaUnitTotals{
+			{Unit: "acres", Farms: 1, LandArea: decimal.MustParse("1"), LandAreaHectares: decimal.MustParse("0.4685")},
+			{Unit: "ha", Farms: 1, LandArea: decimal.MustParse("40"), LandAreaHectares: decimal.MustParse("40")},
+			{Unit: "hectares", Farms: 2, LandArea: decimal.MustParse("100"), LandAreaHectares: decimal.MustParse("100")},
+		},
+	}}
+	var cropRepo crops.Repository = &fakeCropRepository{}
+	s := farms.NewService(l, farmRepo, &cropRepo, crops.BuiltinTypes{}, &fakeTransactor{}, farms.Config{LandAreaPrecision: 2})
+
+	stats, err := s.GetStats(context.Background(), &farms.ListFarmQuery{}, nil)
+	if err != nil {
+		t.Fatalf("GetStats failed: %v", err)
+	}
+
+	if stats.Unit != "ha" || stats.TotalLandArea != 140.47 || stats.AverageLandArea != 35.12 {
+		t.Errorf("Expect 140.47 ha in total and 35.12 ha on average, but got %+v", stats)
+	}
+	if stats.IrrigatedPercentage != 25 || stats.InsuredPercentage != 75 {
+		t.Errorf("Expect 25%% irrigated and 75%% insured, but got %v and %v", stats.IrrigatedPercentage, stats.InsuredPercentage)
+	}
+	if stats.CropTypes[0].Percentage != 75 {
+		t.Errorf("Expect CORN to be 75%% of the crops, but got %v", stats.CropTypes[0].Percentage)
+	}
+
+	if len(stats.Units) != 2 {
+		t.Fatalf("Expect hectare names to be merged, but got %+v", stats.Units)
+	}
+	if u := stats.Units[0]; u.Unit != "ha" || u.Farms != 3 || u.LandArea != 140 || u.Percentage != 75 {
+		t.Errorf("Expect 3 farms with 140 ha, but got %+v", u)
+	}
+	if u := stats.Units[1]; u.Unit != "ac" || u.ConvertedLandArea != 0.47 {
+		t.Errorf("Expect acres converted to 0.47 ha, but got %+v", u)
+	}
+}
@@ -0,0 +1,228 @@
+package geo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidGeometry = errors.New("invalid geometry")
+
+const (
+	TypePoint   = "Point"
+	TypePolygon = "Polygon"
+)
+
+// Mean radius used by the spherical computations, as in 2dsphere queries
+const EarthRadiusKm = 6378.1
+
+// A GeoJSON point. Positions are written longitude first.
+type Point struct {
+	Type        string    `json:"type" bson:"type"`
+	Coordinates []float64 `json:"coordinates" bson:"coordinates"`
+}
+
+func NewPoint(lng float64, lat float64) Point {
+	return Point{Type: TypePoint, Coordinates: []float64{lng, lat}}
+}
+
+func (p Point) Lng() float64 {
+	return p.Coordinates[0]
+}
+
+func (p Point) Lat() float64 {
+	return p.Coordinates[1]
+}
+
+func (p Point) Validate() error {
+	if p.Type != TypePoint {
+		return fmt.Errorf("%w: type must be %s", ErrInvalidGeometry, TypePoint)
+	}
+
+	return validatePosition(p.Coordinates)
+}
+
+// A GeoJSON polygon: an outer ring followed by its holes. Rings are closed,
+// their last position repeating the first.
+type Polygon struct {
+	Type        string        `json:"type" bson:"type"`
+	Coordinates [][][]float64 `json:"coordinates" bson:"coordinates"`
+}
+
+func NewPolygon(rings ...[][]float64) Polygon {
+	return Polygon{Type: TypePolygon, Coordinates: rings}
+}
+
+func (p Polygon) Validate() error {
+	if p.Type != TypePolygon {
+		return fmt.Errorf("%w: type must be %s", ErrInvalidGeometry, TypePolygon)
+	}
+	if len(p.Coordinates) == 0 {
+		return fmt.Errorf("%w: polygon must have an outer ring", ErrInvalidGeometry)
+	}
+
+	for _, ring := range p.Coordinates {
+		if len(ring) < 4 {
+			return fmt.Errorf("%w: rings must have at least 4 positions", ErrInvalidGeometry)
+		}
+		for _, pos := range ring {
+			if err := validatePosition(pos); err != nil {
+				return err
+			}
+		}
+		if !samePosition(ring[0], ring[len(ring)-1]) {
+			return fmt.Errorf("%w: rings must end on their first position", ErrInvalidGeometry)
+		}
+	}
+
+	return nil
+}
+
+func validatePosition(pos []float64) error {
+	if len(pos) != 2 {
+		return fmt.Errorf("%w: positions must be [longitude, latitude]", ErrInvalidGeometry)
+	}
+	if math.IsNaN(pos[0]) || pos[0] < -180 || pos[0] > 180 {
+		return fmt.Errorf("%w: longitude must be between -180 and 180", ErrInvalidGeometry)
+	}
+	if math.IsNaN(pos[1]) || pos[1] < -90 || pos[1] > 90 {
+		return fmt.Errorf("%w: latitude must be between -90 and 90", ErrInvalidGeometry)
+	}
+
+	return nil
+}
+
+func samePosition(a []float64, b []float64) bool {
+	return a[0] == b[0] && a[1] == b[1]
+}
+
+// Parses a "lat,lng" pair, the order maps and GPS devices give them in
+func ParseLatLng(s string) (Point, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return Point{}, fmt.Errorf("%w: expected lat,lng", ErrInvalidGeometry)
+	}
+
+	lat, errLat := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	lng, errLng := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if errLat != nil || errLng != nil {
+		return Point{}, fmt.Errorf("%w: expected lat,lng", ErrInvalidGeometry)
+	}
+
+	p := NewPoint(lng, lat)
+	return p, p.Validate()
+}
+
+// Parses a polygon given either as GeoJSON or as "lat,lng" pairs separated by
+// semicolons. The ring of the pairs is closed when it is not already.
+func ParsePolygon(s string) (Polygon, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "{") {
+		var p Polygon
+		if err := json.Unmarshal([]byte(s), &p); err != nil {
+			return Polygon{}, fmt.Errorf("%w: %v", ErrInvalidGeometry, err)
+		}
+		return p, p.Validate()
+	}
+
+	var ring [][]float64
+	for _, pair := range strings.Split(s, ";") {
+		p, err := ParseLatLng(pair)
+		if err != nil {
+			return Polygon{}, err
+		}
+		ring = append(ring, p.Coordinates)
+	}
+	if len(ring) > 0 && !samePosition(ring[0], ring[len(ring)-1]) {
+		ring = append(ring, ring[0])
+	}
+
+	p := NewPolygon(ring)
+	return p, p.Validate()
+}
+
+// Area enclosed by the polygon on a sphere, holes excluded, in hectares
+func (p Polygon) AreaHectares() float64 {
+	var area float64
+	for i, ring := range p.Coordinates {
+		a := math.Abs(ringArea(ring))
+		if i == 0 {
+			area += a
+		} else {
+			area -= a
+		}
+	}
+
+	// Square kilometers to hectares
+	return math.Max(area, 0) * 100
+}
+
+// Signed area of the ring in square kilometers, after "Some Algorithms for
+// Polygons on a Sphere" by Chamberlain and Duquette
+func ringArea(ring [][]float64) float64 {
+	n := len(ring)
+	if n < 3 {
+		return 0
+	}
+
+	var sum float64
+	for i := 0; i < n-1; i++ {
+		lower, middle, upper := ring[i], ring[i+1], ring[(i+2)%(n-1)]
+		sum += (radians(upper[0]) - radians(lower[0])) * math.Sin(radians(middle[1]))
+	}
+
+	return sum * EarthRadiusKm * EarthRadiusKm / 2
+}
+
+// Tells whether the point lies inside the outer ring of the polygon and out
+// of its holes. Edges are taken as straight lines, close enough at farm scale.
+func (p Polygon) Contains(point Point) bool {
+	for i, ring := range p.Coordinates {
+		inside := ringContains(ring, point.Lng(), point.Lat())
+		if i == 0 && !inside {
+			return false
+		}
+		if i > 0 && inside {
+			return false
+		}
+	}
+
+	return len(p.Coordinates) > 0
+}
+
+// Ray casting over the edges of the ring
+func ringContains(ring [][]float64, x float64, y float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+		if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// Great circle distance between two points
+func DistanceKm(a Point, b Point) float64 {
+	dLat := radians(b.Lat() - a.Lat())
+	dLng := radians(b.Lng() - a.Lng())
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(radians(a.Lat()))*math.Cos(radians(b.Lat()))*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * EarthRadiusKm * math.Asin(math.Sqrt(h))
+}
+
+// Angle subtended by a distance at the center of the earth, as $centerSphere
+// takes its radius
+func RadiansOf(km float64) float64 {
+	return km / EarthRadiusKm
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
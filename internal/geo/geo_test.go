@@ -0,0 +1,123 @@
+package geo_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/mateusfdl/go-api/internal/geo"
+)
+
+// Roughly a 1 km by 1 km square around Porto Alegre
+var square = geo.NewPolygon([][]float64{
+	{-51.2300, -30.0300},
+	{-51.2196, -30.0300},
+	{-51.2196, -30.0210},
+	{-51.2300, -30.0210},
+	{-51.2300, -30.0300},
+})
+
+func TestParseLatLng(t *testing.T) {
+	p, err := geo.ParseLatLng(" -30.03, -51.23 ")
+	if err != nil {
+		t.Fatalf("ParseLatLng failed: %v", err)
+	}
+	if p.Lat() != -30.03 || p.Lng() != -51.23 {
+		t.Errorf("Expect lat -30.03 and lng -51.23, but got %v", p.Coordinates)
+	}
+
+	for _, s := range []string{"", "-30.03", "a,b", "91,0", "0,181", "1,2,3"} {
+		if _, err := geo.ParseLatLng(s); !errors.Is(err, geo.ErrInvalidGeometry) {
+			t.Errorf("ParseLatLng(%q) = %v, want ErrInvalidGeometry", s, err)
+		}
+	}
+}
+
+func TestParsePolygonClosesRing(t *testing.T) {
+	p, err := geo.ParsePolygon("-30.03,-51.23;-30.03,-51.2196;-30.021,-51.2196")
+	if err != nil {
+		t.Fatalf("ParsePolygon failed: %v", err)
+	}
+
+	ring := p.Coordinates[0]
+	if len(ring) != 4 || ring[0][0] != ring[3][0] || ring[0][1] != ring[3][1] {
+		t.Errorf("Expect a closed ring of 4 positions, but got %v", ring)
+	}
+}
+
+func TestParsePolygonFromGeoJSON(t *testing.T) {
+	p, err := geo.ParsePolygon(`{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0]]]}`)
+	if err != nil {
+		t.Fatalf("ParsePolygon failed: %v", err)
+	}
+	if len(p.Coordinates[0]) != 4 {
+		t.Errorf("Expect 4 positions, but got %v", p.Coordinates[0])
+	}
+
+	for _, s := range []string{
+		`{"type": "Point", "coordinates": [0, 0]}`,
+		`{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 1]]]}`,
+		`{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [0, 0]]]}`,
+		`{"type": "Polygon"`,
+	} {
+		if _, err := geo.ParsePolygon(s); !errors.Is(err, geo.ErrInvalidGeometry) {
+			t.Errorf("ParsePolygon(%s) = %v, want ErrInvalidGeometry", s, err)
+		}
+	}
+}
+
+func TestAreaHectares(t *testing.T) {
+	area := square.AreaHectares()
+	if math.Abs(area-100) > 2 {
+		t.Errorf("Expect about 100 ha, but got %v", area)
+	}
+
+	// The same ring the other way around encloses the same area
+	reversed := make([][]float64, 0)
+	for i := len(square.Coordinates[0]) - 1; i >= 0; i-- {
+		reversed = append(reversed, square.Coordinates[0][i])
+	}
+	if got := geo.NewPolygon(reversed).AreaHectares(); math.Abs(got-area) > 1e-6 {
+		t.Errorf("Expect %v ha clockwise, but got %v", area, got)
+	}
+}
+
+func TestAreaHectaresExcludesHoles(t *testing.T) {
+	hole := [][]float64{
+		{-51.2280, -30.0280},
+		{-51.2228, -30.0280},
+		{-51.2228, -30.0235},
+		{-51.2280, -30.0235},
+		{-51.2280, -30.0280},
+	}
+	withHole := geo.NewPolygon(square.Coordinates[0], hole)
+
+	got := withHole.AreaHectares()
+	want := square.AreaHectares() - geo.NewPolygon(hole).AreaHectares()
+	if math.Abs(got-want) > 1e-6 || got >= square.AreaHectares() {
+		t.Errorf("Expect %v ha, but got %v", want, got)
+	}
+}
+
+func TestContains(t *testing.T) {
+	if !square.Contains(geo.NewPoint(-51.225, -30.025)) {
+		t.Errorf("Expect the center to be inside the square")
+	}
+	if square.Contains(geo.NewPoint(-51.24, -30.025)) {
+		t.Errorf("Expect a point west of the square to be outside")
+	}
+
+	hole := [][]float64{{-51.226, -30.026}, {-51.224, -30.026}, {-51.224, -30.024}, {-51.226, -30.024}, {-51.226, -30.026}}
+	if geo.NewPolygon(square.Coordinates[0], hole).Contains(geo.NewPoint(-51.225, -30.025)) {
+		t.Errorf("Expect a point in a hole to be outside")
+	}
+}
+
+func TestDistanceKm(t *testing.T) {
+	portoAlegre := geo.NewPoint(-51.2300, -30.0300)
+	saoPaulo := geo.NewPoint(-46.6333, -23.5505)
+
+	if d := geo.DistanceKm(portoAlegre, saoPaulo); math.Abs(d-853) > 10 {
+		t.Errorf("Expect about 853 km, but got %v", d)
+	}
+}
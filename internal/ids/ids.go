@@ -0,0 +1,42 @@
+package ids
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mateusfdl/go-api/internal/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrInvalidID = errors.New("invalid id")
+
+// Checks raw is a 24 hex characters mongo ObjectID, the format every id is
+// stored with
+func Validate(raw string) error {
+	if !primitive.IsValidObjectID(raw) {
+		return fmt.Errorf("%w: '%s'", ErrInvalidID, raw)
+	}
+
+	return nil
+}
+
+// Checks the value of the named route variable, reporting the problem
+// against it
+func ValidateParam(name, raw string) error {
+	if err := Validate(raw); err != nil {
+		errs := validation.Errors{{Field: name, Code: validation.CodeInvalid, Message: err.Error()}}
+		return fmt.Errorf("%w: %w", ErrInvalidID, errs)
+	}
+
+	return nil
+}
+
+// Converts raw into the ObjectID it is stored as
+func ToObjectID(raw string) (primitive.ObjectID, error) {
+	oid, err := primitive.ObjectIDFromHex(raw)
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("%w: '%s'", ErrInvalidID, raw)
+	}
+
+	return oid, nil
+}
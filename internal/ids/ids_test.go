@@ -0,0 +1,43 @@
+package ids_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mateusfdl/go-api/internal/ids"
+	"github.com/mateusfdl/go-api/internal/validation"
+)
+
+func TestValidate(t *testing.T) {
+	if err := ids.Validate("6740c2d1e4b0a1a2b3c4d5e6"); err != nil {
+		t.Errorf("Expect ObjectID to be valid, but got %v", err)
+	}
+
+	if err := ids.Validate("not-an-id"); !errors.Is(err, ids.ErrInvalidID) {
+		t.Errorf("Expect ErrInvalidID, but got %v", err)
+	}
+}
+
+func TestValidateParamReportsField(t *testing.T) {
+	err := ids.ValidateParam("cropId", "123")
+
+	var problems validation.Errors
+	if !errors.As(err, &problems) {
+		t.Fatalf("Expect validation problems, but got %v", err)
+	}
+
+	if problems[0].Field != "cropId" {
+		t.Errorf("Expect field to be 'cropId', but got '%s'", problems[0].Field)
+	}
+}
+
+func TestToObjectID(t *testing.T) {
+	oid, err := ids.ToObjectID("6740c2d1e4b0a1a2b3c4d5e6")
+	if err != nil || oid.Hex() != "6740c2d1e4b0a1a2b3c4d5e6" {
+		t.Errorf("Expect ObjectID conversion, but got %v %v", oid, err)
+	}
+
+	if _, err := ids.ToObjectID("zz"); !errors.Is(err, ids.ErrInvalidID) {
+		t.Errorf("Expect ErrInvalidID, but got %v", err)
+	}
+}
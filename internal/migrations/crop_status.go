@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/mateusfdl/go-api/adapters/logger"
+	"github.com/mateusfdl/go-api/internal/crops"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func init() {
+	Register(Migration{
+		Name:        "crop-status",
+		Description: "Sets crops written before they had a lifecycle as planned",
+		Run:         backfillCropStatus,
+	})
+}
+
+// The API already reads such crops as planned, this makes it visible to
+// queries on the status
+func backfillCropStatus(ctx context.Context, db *mongo.Database, l *logger.Logger) error {
+	filter := bson.M{"status": nil}
+	update := bson.M{"$set": bson.M{"status": crops.StatusPlanned}}
+
+	result, err := db.Collection("crops").UpdateMany(ctx, filter, update)
+	if err != nil {
+		l.Error("Failed to backfill crop status", err)
+		return err
+	}
+
+	l.Info("Backfilled crop status", "modified", result.ModifiedCount)
+	return nil
+}
@@ -0,0 +1,69 @@
+package migrations
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mateusfdl/go-api/adapters/logger"
+	"github.com/mateusfdl/go-api/internal/decimal"
+	"github.com/mateusfdl/go-api/internal/units"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func init() {
+	Register(Migration{
+		Name:        "land-area-hectares",
+		Description: "Stores land areas of farms as decimals and backfills landAreaHectares from their unit of measurement",
+		Run:         backfillLandAreaHectares,
+	})
+}
+
+// Farms written before land areas were normalized only know them in their own
+// unit, as integers. Farms with a unit missing from the registry are left
+// without landAreaHectares and reported, as there is no way to tell their size.
+func backfillLandAreaHectares(ctx context.Context, db *mongo.Database, l *logger.Logger) error {
+	unit := bson.M{"$toLower": bson.M{"$trim": bson.M{"input": "$unitOfMeasurement"}}}
+	landArea := bson.M{"$toDecimal": "$landArea"}
+
+	branches := bson.A{}
+	for _, u := range units.Default.Units() {
+		names := bson.A{}
+		for _, name := range u.Names() {
+			names = append(names, strings.ToLower(name))
+		}
+		branches = append(branches, bson.M{
+			"case": bson.M{"$in": bson.A{unit, names}},
+			"then": bson.M{"$multiply": bson.A{landArea, decimal.NewFromFloat(u.Hectares)}},
+		})
+	}
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"landArea": bson.M{"$not": bson.M{"$type": "decimal"}}},
+		bson.M{"landAreaHectares": bson.M{"$not": bson.M{"$type": "decimal"}}},
+	}}
+	update := mongo.Pipeline{
+		bson.D{{Key: "$set", Value: bson.M{
+			"landArea":         landArea,
+			"landAreaHectares": bson.M{"$switch": bson.M{"branches": branches, "default": nil}},
+		}}},
+	}
+
+	farms := db.Collection("farms")
+	result, err := farms.UpdateMany(ctx, filter, update)
+	if err != nil {
+		l.Error("Failed to backfill land areas", err)
+		return err
+	}
+	l.Info("Backfilled land areas", "modified", result.ModifiedCount)
+
+	unknown, err := farms.CountDocuments(ctx, bson.M{"landAreaHectares": bson.M{"$not": bson.M{"$type": "decimal"}}})
+	if err != nil {
+		return err
+	}
+	if unknown > 0 {
+		l.Warn("Farms left without land area in hectares, their unit is unknown", "count", unknown)
+	}
+
+	return nil
+}
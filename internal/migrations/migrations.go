@@ -0,0 +1,51 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/mateusfdl/go-api/adapters/logger"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrMigrationNotFound = errors.New("migration not found")
+
+// A one-off change to existing documents. Migrations must be idempotent, as
+// nothing records whether they already ran.
+type Migration struct {
+	Name        string
+	Description string
+	Run         func(ctx context.Context, db *mongo.Database, l *logger.Logger) error
+}
+
+var registry = make(map[string]Migration)
+
+// Makes the migration available to the migrate command, usually from init
+func Register(m Migration) {
+	if _, ok := registry[m.Name]; ok {
+		panic("migration registered twice: " + m.Name)
+	}
+
+	registry[m.Name] = m
+}
+
+func Get(name string) (Migration, error) {
+	m, ok := registry[name]
+	if !ok {
+		return Migration{}, ErrMigrationNotFound
+	}
+
+	return m, nil
+}
+
+// Every registered migration, sorted by name
+func All() []Migration {
+	all := make([]Migration, 0, len(registry))
+	for _, m := range registry {
+		all = append(all, m)
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return all
+}
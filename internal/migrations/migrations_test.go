@@ -0,0 +1,34 @@
+package migrations_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mateusfdl/go-api/internal/migrations"
+)
+
+func TestGetRegisteredMigration(t *testing.T) {
+	m, err := migrations.Get("timestamps")
+	if err != nil {
+		t.Fatalf("Expect timestamps migration, but got %v", err)
+	}
+	if m.Run == nil {
+		t.Errorf("Expect migration to be runnable")
+	}
+}
+
+func TestGetUnknownMigration(t *testing.T) {
+	_, err := migrations.Get("unknown")
+	if !errors.Is(err, migrations.ErrMigrationNotFound) {
+		t.Errorf("Expect ErrMigrationNotFound, but got %v", err)
+	}
+}
+
+func TestAllIsSortedByName(t *testing.T) {
+	all := migrations.All()
+	for i := 1; i < len(all); i++ {
+		if all[i-1].Name > all[i].Name {
+			t.Errorf("Expect migrations sorted by name, but got %s before %s", all[i-1].Name, all[i].Name)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/mateusfdl/go-api/adapters/logger"
+	"github.com/mateusfdl/go-api/internal/address"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func init() {
+	Register(Migration{
+		Name:        "structured-address",
+		Description: "Parses the address of farms written as a single line into its parts",
+		Run:         backfillStructuredAddress,
+	})
+}
+
+// Updates are sent in batches of this size
+const addressBatchSize = 500
+
+// Parsing is best effort: addresses whose city can not be told are left as
+// lines, and reported, so that they can be fixed by hand
+func backfillStructuredAddress(ctx context.Context, db *mongo.Database, l *logger.Logger) error {
+	farms := db.Collection("farms")
+	filter := bson.M{"structuredAddress": nil, "address": bson.M{"$type": "string"}}
+	cursor, err := farms.Find(ctx, filter, options.Find().SetProjection(bson.M{"address": 1}))
+	if err != nil {
+		l.Error("Failed to read farm addresses", err)
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var models []mongo.WriteModel
+	var modified, unparsed int64
+	flush := func() error {
+		if len(models) == 0 {
+			return nil
+		}
+
+		result, err := farms.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+		if err != nil {
+			l.Error("Failed to backfill structured addresses", err)
+			return err
+		}
+
+		modified += result.ModifiedCount
+		models = models[:0]
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		var farm struct {
+			ID      interface{} `bson:"_id"`
+			Address string      `bson:"address"`
+		}
+		if err := cursor.Decode(&farm); err != nil {
+			return err
+		}
+
+		parts, ok := address.Parse(farm.Address)
+		if !ok {
+			unparsed++
+			continue
+		}
+
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": farm.ID, "structuredAddress": nil}).
+			SetUpdate(bson.M{"$set": bson.M{"structuredAddress": parts}}))
+		if len(models) == addressBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	l.Info("Backfilled structured addresses", "modified", modified)
+	if unparsed > 0 {
+		l.Warn("Farm addresses left as lines, their city could not be told", "count", unparsed)
+	}
+
+	return nil
+}
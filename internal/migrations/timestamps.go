@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/mateusfdl/go-api/adapters/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func init() {
+	Register(Migration{
+		Name:        "timestamps",
+		Description: "Backfills createdAt and updatedAt of farms and crops from their ObjectID",
+		Run:         backfillTimestamps,
+	})
+}
+
+// Documents written before the repositories stamped them only know their
+// creation time through the ObjectID, which is used for both fields
+func backfillTimestamps(ctx context.Context, db *mongo.Database, l *logger.Logger) error {
+	createdAt := bson.M{"$ifNull": bson.A{"$createdAt", bson.M{"$toDate": "$_id"}}}
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"createdAt": nil},
+		bson.M{"updatedAt": nil},
+	}}
+	update := mongo.Pipeline{
+		bson.D{{Key: "$set", Value: bson.M{
+			"createdAt": createdAt,
+			"updatedAt": bson.M{"$ifNull": bson.A{"$updatedAt", createdAt}},
+		}}},
+	}
+
+	for _, collection := range []string{"farms", "crops"} {
+		result, err := db.Collection(collection).UpdateMany(ctx, filter, update)
+		if err != nil {
+			l.Error("Failed to backfill timestamps", "collection", collection, err)
+			return err
+		}
+
+		l.Info("Backfilled timestamps", "collection", collection, "modified", result.ModifiedCount)
+	}
+
+	return nil
+}
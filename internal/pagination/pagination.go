@@ -0,0 +1,247 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mateusfdl/go-api/internal/validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+const (
+	DefaultLimit = 25
+	MaxLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Position right after the last item of a page, holding the value of every
+// sort key of that item in sort order
+type Cursor bson.D
+
+// A field the listing is sorted by. The last key must be unique, usually _id,
+// so that the position of every item is unambiguous.
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// Pagination requested by the client
+type Params struct {
+	After     Cursor
+	Limit     int
+	WithTotal bool
+}
+
+type Page[T any] struct {
+	Items      []T     `json:"items"`
+	NextCursor *string `json:"nextCursor"`
+	TotalCount *int64  `json:"totalCount,omitempty"`
+}
+
+// Opaque token handed to clients, base64 of the BSON document so that the
+// values keep their types
+func (c Cursor) Encode() (string, error) {
+	raw, err := bson.Marshal(bson.D(c))
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// Types sort keys are paginated by. Cursors are not signed, so that values
+// of other types, like documents holding query operators, are rejected before
+// reaching a $match.
+var cursorTypes = []bsontype.Type{
+	bsontype.String, bsontype.Double, bsontype.Int32, bsontype.Int64, bsontype.Decimal128,
+	bsontype.Boolean, bsontype.DateTime, bsontype.ObjectID, bsontype.Null,
+}
+
+func Decode(token string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	values, err := bson.Raw(raw).Values()
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	for _, v := range values {
+		if !slices.Contains(cursorTypes, v.Type) {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	var d bson.D
+	if err := bson.Unmarshal(raw, &d); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return Cursor(d), nil
+}
+
+// Reads after, limit and totalCount from the query string, recording a
+// problem for every malformed one
+func ParseParams(query url.Values, errs *validation.Errors) Params {
+	params := Params{Limit: DefaultLimit}
+
+	if after := query.Get("after"); after != "" {
+		cursor, err := Decode(after)
+		if err != nil {
+			errs.Add("after", validation.CodeInvalid, "after is not a valid cursor")
+		}
+		params.After = cursor
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 || l > MaxLimit {
+			errs.Add("limit", validation.CodeInvalid, fmt.Sprintf("limit must be an integer between 1 and %d", MaxLimit))
+		} else {
+			params.Limit = l
+		}
+	}
+
+	params.WithTotal = validation.QueryBool(query, "totalCount", false, errs)
+
+	return params
+}
+
+// Parses a comma separated sort like name,-landArea, where a leading minus
+// sorts descending. Only the allowed fields are accepted.
+func ParseSort(raw string, allowed []string, errs *validation.Errors) []SortKey {
+	if raw == "" {
+		return nil
+	}
+
+	var keys []SortKey
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(raw, ",") {
+		key := SortKey{Field: strings.TrimSpace(part)}
+		if strings.HasPrefix(key.Field, "-") {
+			key.Field = key.Field[1:]
+			key.Desc = true
+		}
+
+		if !slices.Contains(allowed, key.Field) || seen[key.Field] {
+			errs.Add("sort", validation.CodeInvalid, fmt.Sprintf("sort accepts each of %s once", strings.Join(allowed, ", ")))
+			return nil
+		}
+
+		seen[key.Field] = true
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// Makes the order total by breaking ties on _id, in the direction of the last
+// key
+func WithTiebreaker(keys []SortKey) []SortKey {
+	if len(keys) == 0 {
+		return []SortKey{{Field: "_id"}}
+	}
+
+	total := append([]SortKey{}, keys...)
+	return append(total, SortKey{Field: "_id", Desc: keys[len(keys)-1].Desc})
+}
+
+func SortStage(keys []SortKey) bson.D {
+	sort := bson.D{}
+	for _, k := range keys {
+		order := 1
+		if k.Desc {
+			order = -1
+		}
+		sort = append(sort, bson.E{Key: k.Field, Value: order})
+	}
+
+	return bson.D{{Key: "$sort", Value: sort}}
+}
+
+// Replaces the sort fields documents lack, or hold null in, with the given
+// defaults, so that $gt and $lt can match them. Defaults must be the values
+// cursors are taken with for such documents, usually the zero values they
+// decode to. Answers nil when no key has a default.
+func CoalesceStage(keys []SortKey, defaults map[string]interface{}) bson.D {
+	fields := bson.D{}
+	for _, k := range keys {
+		if value, ok := defaults[k.Field]; ok {
+			fields = append(fields, bson.E{Key: k.Field, Value: bson.M{"$ifNull": bson.A{"$" + k.Field, value}}})
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return bson.D{{Key: "$addFields", Value: fields}}
+}
+
+// Builds the keyset condition matching the items sorted after the cursor:
+// greater on the first key, or equal on it and greater on the next one, and
+// so on. Cursors taken with other sort keys are rejected.
+func AfterFilter(keys []SortKey, cursor Cursor) (bson.M, error) {
+	if len(cursor) != len(keys) {
+		return nil, ErrInvalidCursor
+	}
+
+	for i, k := range keys {
+		if cursor[i].Key != k.Field {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	or := bson.A{}
+	for i, k := range keys {
+		cond := bson.M{}
+		for _, prev := range cursor[:i] {
+			cond[prev.Key] = prev.Value
+		}
+
+		op := "$gt"
+		if k.Desc {
+			op = "$lt"
+		}
+		cond[k.Field] = bson.M{op: cursor[i].Value}
+
+		or = append(or, cond)
+	}
+
+	return bson.M{"$or": or}, nil
+}
+
+// Builds the page out of up to limit+1 items, the extra one only telling that
+// there is a next page
+func NewPage[T any](items []T, limit int, cursorOf func(item T) (Cursor, error)) (*Page[T], error) {
+	page := &Page[T]{Items: items}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+
+	if len(items) <= limit {
+		return page, nil
+	}
+
+	page.Items = items[:limit]
+	cursor, err := cursorOf(page.Items[limit-1])
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := cursor.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	page.NextCursor = &next
+	return page, nil
+}
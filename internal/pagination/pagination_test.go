@@ -0,0 +1,180 @@
+package pagination_test
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mateusfdl/go-api/internal/pagination"
+	"github.com/mateusfdl/go-api/internal/validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var keys = []pagination.SortKey{
+	{Field: "createdAt", Desc: true},
+	{Field: "_id", Desc: true},
+}
+
+func TestCursorRoundTripKeepsTypes(t *testing.T) {
+	createdAt := primitive.NewDateTimeFromTime(time.Date(2024, 11, 22, 10, 0, 0, 0, time.UTC))
+	id := primitive.NewObjectID()
+	cursor := pagination.Cursor{{Key: "createdAt", Value: createdAt}, {Key: "_id", Value: id}}
+
+	token, err := cursor.Encode()
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+
+	decoded, err := pagination.Decode(token)
+	if err != nil {
+		t.Fatalf("Decode() failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(decoded, cursor) {
+		t.Errorf("Expect %v, but got %v", cursor, decoded)
+	}
+}
+
+func TestDecodeMalformedCursor(t *testing.T) {
+	for _, token := range []string{"not base64!", "aGVsbG8"} {
+		if _, err := pagination.Decode(token); !errors.Is(err, pagination.ErrInvalidCursor) {
+			t.Errorf("Decode(%q): expect ErrInvalidCursor, but got %v", token, err)
+		}
+	}
+}
+
+func TestDecodeRejectsNonScalarValues(t *testing.T) {
+	values := []interface{}{
+		bson.M{"$ne": nil},
+		bson.A{1, 2},
+		primitive.JavaScript("sleep(1000)"),
+		primitive.Regex{Pattern: ".*"},
+	}
+	for _, v := range values {
+		token, err := pagination.Cursor{{Key: "name", Value: v}, {Key: "_id", Value: primitive.NewObjectID()}}.Encode()
+		if err != nil {
+			t.Fatalf("Encode() failed: %v", err)
+		}
+
+		if _, err := pagination.Decode(token); !errors.Is(err, pagination.ErrInvalidCursor) {
+			t.Errorf("Decode() of %v: expect ErrInvalidCursor, but got %v", v, err)
+		}
+	}
+}
+
+func TestAfterFilter(t *testing.T) {
+	cursor := pagination.Cursor{{Key: "createdAt", Value: "c"}, {Key: "_id", Value: "i"}}
+
+	filter, err := pagination.AfterFilter(keys, cursor)
+	if err != nil {
+		t.Fatalf("AfterFilter() failed: %v", err)
+	}
+
+	want := bson.M{"$or": bson.A{
+		bson.M{"createdAt": bson.M{"$lt": "c"}},
+		bson.M{"createdAt": "c", "_id": bson.M{"$lt": "i"}},
+	}}
+	if !reflect.DeepEqual(filter, want) {
+		t.Errorf("Expect %v, but got %v", want, filter)
+	}
+}
+
+func TestAfterFilterRejectsCursorOfOtherSort(t *testing.T) {
+	cursor := pagination.Cursor{{Key: "name", Value: "a"}, {Key: "_id", Value: "i"}}
+
+	if _, err := pagination.AfterFilter(keys, cursor); !errors.Is(err, pagination.ErrInvalidCursor) {
+		t.Errorf("Expect ErrInvalidCursor, but got %v", err)
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	cursorOf := func(i int) (pagination.Cursor, error) {
+		return pagination.Cursor{{Key: "_id", Value: int32(i)}}, nil
+	}
+
+	page, err := pagination.NewPage([]int{1, 2, 3}, 2, cursorOf)
+	if err != nil {
+		t.Fatalf("NewPage() failed: %v", err)
+	}
+	if !reflect.DeepEqual(page.Items, []int{1, 2}) {
+		t.Errorf("Expect items [1 2], but got %v", page.Items)
+	}
+	if page.NextCursor == nil {
+		t.Fatalf("Expect next cursor, but got nil")
+	}
+
+	next, _ := pagination.Decode(*page.NextCursor)
+	if next[0].Value != int32(2) {
+		t.Errorf("Expect cursor after the last item, but got %v", next)
+	}
+
+	page, _ = pagination.NewPage([]int{1, 2}, 2, cursorOf)
+	if page.NextCursor != nil {
+		t.Errorf("Expect no next cursor on the last page")
+	}
+
+	page, _ = pagination.NewPage[int](nil, 2, cursorOf)
+	if page.Items == nil {
+		t.Errorf("Expect empty items, but got nil")
+	}
+}
+
+func TestParseParams(t *testing.T) {
+	var errs validation.Errors
+	params := pagination.ParseParams(url.Values{}, &errs)
+	if errs.Err() != nil || params.Limit != pagination.DefaultLimit || params.After != nil {
+		t.Errorf("Expect defaults, but got %+v and %v", params, errs)
+	}
+
+	errs = nil
+	pagination.ParseParams(url.Values{"limit": {"101"}, "after": {"%%"}, "totalCount": {"maybe"}}, &errs)
+	if len(errs) != 3 {
+		t.Errorf("Expect 3 problems, but got %v", errs)
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	allowed := []string{"name", "landArea"}
+
+	var errs validation.Errors
+	keys := pagination.ParseSort("name,-landArea", allowed, &errs)
+	want := []pagination.SortKey{{Field: "name"}, {Field: "landArea", Desc: true}}
+	if errs.Err() != nil || !reflect.DeepEqual(keys, want) {
+		t.Errorf("Expect %v, but got %v and %v", want, keys, errs)
+	}
+
+	for _, raw := range []string{"password", "name,name", "name,"} {
+		errs = nil
+		pagination.ParseSort(raw, allowed, &errs)
+		if len(errs) != 1 || errs[0].Field != "sort" {
+			t.Errorf("ParseSort(%q): expect a sort problem, but got %v", raw, errs)
+		}
+	}
+}
+
+func TestWithTiebreaker(t *testing.T) {
+	keys := pagination.WithTiebreaker([]pagination.SortKey{{Field: "name"}, {Field: "landArea", Desc: true}})
+
+	want := []pagination.SortKey{{Field: "name"}, {Field: "landArea", Desc: true}, {Field: "_id", Desc: true}}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("Expect %v, but got %v", want, keys)
+	}
+}
+
+func TestCoalesceStage(t *testing.T) {
+	stage := pagination.CoalesceStage(keys, map[string]interface{}{"createdAt": "zero", "name": ""})
+
+	want := bson.D{{Key: "$addFields", Value: bson.D{
+		{Key: "createdAt", Value: bson.M{"$ifNull": bson.A{"$createdAt", "zero"}}},
+	}}}
+	if !reflect.DeepEqual(stage, want) {
+		t.Errorf("Expect %v, but got %v", want, stage)
+	}
+
+	if stage := pagination.CoalesceStage(keys, nil); stage != nil {
+		t.Errorf("Expect no stage without defaults, but got %v", stage)
+	}
+}
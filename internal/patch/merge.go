@@ -0,0 +1,60 @@
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+const MergePatchContentType = "application/merge-patch+json"
+
+var ErrInvalidPatch = errors.New("invalid merge patch")
+
+// Applies an RFC 7396 JSON Merge Patch to the target document: members set to
+// null are removed, objects are merged recursively and any other value
+// replaces the target one
+func MergePatch(target, patch []byte) ([]byte, error) {
+	var t interface{}
+	if len(target) > 0 {
+		if err := decode(target, &t); err != nil {
+			return nil, err
+		}
+	}
+
+	var p interface{}
+	if err := decode(patch, &p); err != nil {
+		return nil, errors.Join(ErrInvalidPatch, err)
+	}
+
+	return json.Marshal(merge(t, p))
+}
+
+func merge(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{})
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+
+		t[k] = merge(t[k], v)
+	}
+
+	return t
+}
+
+// Numbers are kept as json.Number so that large integers are not rounded
+func decode(data []byte, v interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	return d.Decode(v)
+}
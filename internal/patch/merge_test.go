@@ -0,0 +1,81 @@
+package patch_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mateusfdl/go-api/internal/patch"
+)
+
+// Cases from the appendix of RFC 7396
+func TestMergePatch(t *testing.T) {
+	cases := []struct {
+		target string
+		patch  string
+		expect string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, c := range cases {
+		got, err := patch.MergePatch([]byte(c.target), []byte(c.patch))
+		if err != nil {
+			t.Fatalf("MergePatch(%s, %s) failed: %v", c.target, c.patch, err)
+		}
+
+		if !jsonEqual(t, got, []byte(c.expect)) {
+			t.Errorf("MergePatch(%s, %s): expect %s, but got %s", c.target, c.patch, c.expect, got)
+		}
+	}
+}
+
+func TestMergePatchKeepsLargeNumbers(t *testing.T) {
+	got, err := patch.MergePatch([]byte(`{"landArea":9007199254740993}`), []byte(`{"name":"Farm"}`))
+	if err != nil {
+		t.Fatalf("MergePatch() failed: %v", err)
+	}
+
+	if !strings.Contains(string(got), "9007199254740993") {
+		t.Errorf("Expect large number to be kept, but got %s", got)
+	}
+}
+
+func TestMergePatchRejectsMalformedPatch(t *testing.T) {
+	_, err := patch.MergePatch([]byte(`{}`), []byte(`{"a":`))
+	if !errors.Is(err, patch.ErrInvalidPatch) {
+		t.Errorf("Expect ErrInvalidPatch, but got %v", err)
+	}
+}
+
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	var va, vb interface{}
+	if err := json.Unmarshal(a, &va); err != nil {
+		t.Fatalf("Invalid JSON %s", a)
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		t.Fatalf("Invalid JSON %s", b)
+	}
+
+	if string(a) == string(b) {
+		return true
+	}
+
+	return reflect.DeepEqual(va, vb)
+}
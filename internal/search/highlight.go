@@ -0,0 +1,110 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+const (
+	HighlightPre  = "<em>"
+	HighlightPost = "</em>"
+)
+
+// Shortest prefix a word and a term must share to match, loosely following
+// the stemming of Mongo text indexes, e.g. fazenda and fazendas
+const minStemLength = 4
+
+// Accented letters folded like the diacritic insensitive text indexes do
+var diacritics = map[rune]rune{
+	'á': 'a', 'à': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u',
+	'ç': 'c', 'ñ': 'n',
+}
+
+// Words searched by a $text query, leaving out negated terms. Phrases are
+// split into their words.
+func Terms(q string) []string {
+	var terms []string
+	for _, field := range strings.Fields(strings.ReplaceAll(q, `"`, " ")) {
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+
+		terms = append(terms, words(field)...)
+	}
+
+	return terms
+}
+
+// Wraps the words of text matching any of the terms in HighlightPre and
+// HighlightPost, reporting whether any did. The text is HTML escaped, so that
+// highlights can be rendered as HTML.
+func Highlight(text string, terms []string) (string, bool) {
+	var b strings.Builder
+	matched := false
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			b.WriteString(html.EscapeString(string(runes[i])))
+			i++
+			continue
+		}
+
+		j := i
+		for j < len(runes) && isWordRune(runes[j]) {
+			j++
+		}
+
+		word := string(runes[i:j])
+		if matches(fold(word), terms) {
+			matched = true
+			b.WriteString(HighlightPre + html.EscapeString(word) + HighlightPost)
+		} else {
+			b.WriteString(html.EscapeString(word))
+		}
+		i = j
+	}
+
+	return b.String(), matched
+}
+
+func matches(word string, terms []string) bool {
+	for _, term := range terms {
+		if word == term {
+			return true
+		}
+
+		short, long := word, term
+		if len(short) > len(long) {
+			short, long = long, short
+		}
+		if len(short) >= minStemLength && strings.HasPrefix(long, short) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func words(s string) []string {
+	return strings.FieldsFunc(fold(s), func(r rune) bool { return !isWordRune(r) })
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func fold(s string) string {
+	return strings.Map(func(r rune) rune {
+		r = unicode.ToLower(r)
+		if folded, ok := diacritics[r]; ok {
+			return folded
+		}
+		return r
+	}, s)
+}
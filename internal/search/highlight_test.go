@@ -0,0 +1,42 @@
+package search_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mateusfdl/go-api/internal/search"
+)
+
+func TestTerms(t *testing.T) {
+	terms := search.Terms(`Fazenda "São Jorge" -milho`)
+
+	want := []string{"fazenda", "sao", "jorge"}
+	if !reflect.DeepEqual(terms, want) {
+		t.Errorf("Expect %v, but got %v", want, terms)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	cases := []struct {
+		text    string
+		q       string
+		want    string
+		matched bool
+	}{
+		{"Fazenda Boa Vista", "vista", "Fazenda Boa <em>Vista</em>", true},
+		{"Fazendas Reunidas", "fazenda", "<em>Fazendas</em> Reunidas", true},
+		{"Sítio São João", "sao joao", "Sítio <em>São</em> <em>João</em>", true},
+		{"Rua 1, 123, Porto Alegre - RS", "porto", "Rua 1, 123, <em>Porto</em> Alegre - RS", true},
+		{"Boa Vista", "boas", "Boa Vista", false},
+		{"Fazenda Boa Vista", "milho", "Fazenda Boa Vista", false},
+		{"<img src=x onerror=alert(1)> Vista", "vista", "&lt;img src=x onerror=alert(1)&gt; <em>Vista</em>", true},
+		{"Pereira & Filhos", "lt amp", "Pereira &amp; Filhos", false},
+	}
+
+	for _, c := range cases {
+		got, matched := search.Highlight(c.text, search.Terms(c.q))
+		if got != c.want || matched != c.matched {
+			t.Errorf("Highlight(%q, %q) = %q, %v, want %q, %v", c.text, c.q, got, matched, c.want, c.matched)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package units
+
+import "github.com/mateusfdl/go-api/internal/decimal"
+
+// A mass unit, converted through its weight in kilograms
+type MassUnit struct {
+	Symbol string
+	// Other names the unit is given, matched regardless of case
+	Aliases   []string
+	Kilograms float64
+}
+
+var (
+	Kilogram = MassUnit{Symbol: "kg", Aliases: []string{"kilogram", "kilograms"}, Kilograms: 1}
+	Ton      = MassUnit{Symbol: "t", Aliases: []string{"ton", "tons", "tonne", "tonnes"}, Kilograms: 1000}
+	// The 60 kg bag grains are traded in
+	Sack   = MassUnit{Symbol: "sc", Aliases: []string{"sack", "sacks", "saca", "sacas"}, Kilograms: 60}
+	Arroba = MassUnit{Symbol: "@", Aliases: []string{"arroba", "arrobas"}, Kilograms: 15}
+)
+
+// Mass units supported by the API
+var DefaultMass = NewRegistry(Kilogram, Ton, Sack, Arroba)
+
+func (u MassUnit) Names() []string {
+	return append([]string{u.Symbol}, u.Aliases...)
+}
+
+func (u MassUnit) KilogramsOf(value decimal.Decimal) decimal.Decimal {
+	return value.Mul(decimal.NewFromFloat(u.Kilograms))
+}
+
+func (u MassUnit) FromKilograms(kilograms float64) float64 {
+	return kilograms / u.Kilograms
+}
@@ -0,0 +1,114 @@
+package units
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/mateusfdl/go-api/internal/decimal"
+)
+
+var ErrUnknownUnit = errors.New("unknown unit of measurement")
+
+// An area unit, converted through its size in hectares
+type Unit struct {
+	Symbol string
+	// Other names the unit is given, matched regardless of case
+	Aliases  []string
+	Hectares float64
+}
+
+var (
+	Hectare         = Unit{Symbol: "ha", Aliases: []string{"hectare", "hectares"}, Hectares: 1}
+	Acre            = Unit{Symbol: "ac", Aliases: []string{"acre", "acres"}, Hectares: 0.40468564224}
+	Alqueire        = Unit{Symbol: "alqueire", Aliases: []string{"alqueires", "alqueire paulista"}, Hectares: 2.42}
+	SquareMeter     = Unit{Symbol: "m2", Aliases: []string{"m²", "square meter", "square meters"}, Hectares: 0.0001}
+	SquareKilometer = Unit{Symbol: "km2", Aliases: []string{"km²", "square kilometer", "square kilometers"}, Hectares: 100}
+)
+
+// Area units supported by the API
+var Default = NewRegistry(Hectare, Acre, Alqueire, SquareMeter, SquareKilometer)
+
+// Names every unit is known by
+func (u Unit) Names() []string {
+	return append([]string{u.Symbol}, u.Aliases...)
+}
+
+// Exact conversion of a decimal value, as every factor is a decimal itself
+func (u Unit) HectaresOf(value decimal.Decimal) decimal.Decimal {
+	return value.Mul(decimal.NewFromFloat(u.Hectares))
+}
+
+func (u Unit) FromHectares(hectares float64) float64 {
+	return hectares / u.Hectares
+}
+
+// An area expressed in a given unit
+type Area struct {
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
+}
+
+// Units a registry can hold, whose first name is their symbol
+type named interface {
+	Names() []string
+}
+
+// Units of a same dimension, like areas or masses, found by any of their names
+type Registry[U named] struct {
+	units  []U
+	byName map[string]U
+}
+
+// Panics when two units share a name, as the registry is built at startup
+func NewRegistry[U named](units ...U) *Registry[U] {
+	r := &Registry[U]{byName: make(map[string]U)}
+	for _, u := range units {
+		for _, name := range u.Names() {
+			key := normalize(name)
+			if _, ok := r.byName[key]; ok {
+				panic("unit name registered twice: " + name)
+			}
+			r.byName[key] = u
+		}
+		r.units = append(r.units, u)
+	}
+
+	return r
+}
+
+// Finds the unit by its symbol or any alias
+func (r *Registry[U]) Lookup(name string) (U, error) {
+	u, ok := r.byName[normalize(name)]
+	if !ok {
+		var zero U
+		return zero, fmt.Errorf("%w: %s", ErrUnknownUnit, name)
+	}
+
+	return u, nil
+}
+
+func (r *Registry[U]) Units() []U {
+	return append([]U{}, r.units...)
+}
+
+// Symbols of every unit, to be listed in validation messages
+func (r *Registry[U]) Symbols() []string {
+	symbols := make([]string, len(r.units))
+	for i, u := range r.units {
+		symbols[i] = u.Names()[0]
+	}
+
+	return symbols
+}
+
+// Rounds an area to the given number of decimal places
+func Round(value float64, places int) float64 {
+	p := math.Pow10(places)
+	return math.Round(value*p) / p
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,81 @@
+package units_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/mateusfdl/go-api/internal/decimal"
+	"github.com/mateusfdl/go-api/internal/units"
+)
+
+func TestLookupByAnyName(t *testing.T) {
+	for _, name := range []string{"ha", "Hectares", " hectare ", "HA"} {
+		u, err := units.Default.Lookup(name)
+		if err != nil || u.Symbol != "ha" {
+			t.Errorf("Lookup(%q) = %v, %v, want ha", name, u.Symbol, err)
+		}
+	}
+
+	if _, err := units.Default.Lookup("furlongs"); !errors.Is(err, units.ErrUnknownUnit) {
+		t.Errorf("Expect ErrUnknownUnit, but got %v", err)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	cases := []struct {
+		value    string
+		from, to string
+		want     float64
+	}{
+		{"1", "ha", "m2", 10000},
+		{"1", "km2", "ha", 100},
+		{"1", "alqueire", "ha", 2.42},
+		{"100", "acres", "ha", 40.468564224},
+		{"40.468564224", "ha", "acres", 100},
+	}
+
+	for _, c := range cases {
+		from, _ := units.Default.Lookup(c.from)
+		to, _ := units.Default.Lookup(c.to)
+
+		got := to.FromHectares(from.HectaresOf(decimal.MustParse(c.value)).Float64())
+		if math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("Converting %s %s to %s = %v, want %v", c.value, c.from, c.to, got, c.want)
+		}
+	}
+}
+
+func TestRegistryRejectsDuplicateNames(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expect panic on duplicate unit name")
+		}
+	}()
+
+	units.NewRegistry(units.Hectare, units.Unit{Symbol: "hectares", Hectares: 1})
+}
+
+func TestRound(t *testing.T) {
+	if got := units.Round(214.98145, 2); got != 214.98 {
+		t.Errorf("Expect 214.98, but got %v", got)
+	}
+}
+
+func TestMassUnits(t *testing.T) {
+	sack, err := units.DefaultMass.Lookup("Sacas")
+	if err != nil || sack.Symbol != "sc" {
+		t.Fatalf("Lookup(Sacas) = %v, %v, want sc", sack.Symbol, err)
+	}
+
+	if got := sack.KilogramsOf(decimal.MustParse("2.5")); got.String() != "150" {
+		t.Errorf("Expect 2.5 sacks to weigh 150 kg, but got %s", got)
+	}
+	if got := units.Ton.FromKilograms(1500); got != 1.5 {
+		t.Errorf("Expect 1500 kg to be 1.5 t, but got %v", got)
+	}
+
+	if _, err := units.DefaultMass.Lookup("hectares"); !errors.Is(err, units.ErrUnknownUnit) {
+		t.Errorf("Expect ErrUnknownUnit, but got %v", err)
+	}
+}
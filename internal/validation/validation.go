@@ -0,0 +1,84 @@
+package validation
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	CodeRequired = "required"
+	CodeInvalid  = "invalid"
+	CodePositive = "must_be_positive"
+	// The value was valid once but is not accepted anymore
+	CodeDeprecated = "deprecated"
+)
+
+// A single problem found on a field of the input
+type Problem struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Collects every problem found while validating an input
+type Errors []Problem
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, p := range e {
+		messages[i] = p.Message
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Exposes the problems to the HTTP layer, which lists them in error responses
+func (e Errors) InvalidParams() interface{} {
+	return e
+}
+
+func (e *Errors) Add(field, code, message string) {
+	*e = append(*e, Problem{Field: field, Code: code, Message: message})
+}
+
+// Records a required problem when value is blank
+func (e *Errors) Required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		e.Add(field, CodeRequired, field+" is required")
+	}
+}
+
+// Returns the collected problems as an error, or nil when there are none
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+// Joins a field name to the path of its parent, e.g. crops[0] and type
+func Field(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// Parses an optional boolean query parameter, recording a problem when malformed
+func QueryBool(query url.Values, name string, def bool, errs *Errors) bool {
+	value := query.Get(name)
+	if value == "" {
+		return def
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		errs.Add(name, CodeInvalid, name+" must be a boolean")
+		return def
+	}
+
+	return b
+}
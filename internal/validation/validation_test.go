@@ -0,0 +1,65 @@
+package validation_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/mateusfdl/go-api/internal/validation"
+)
+
+func TestErrorsCollectsProblems(t *testing.T) {
+	var errs validation.Errors
+	errs.Required("name", "")
+	errs.Required("address", "  ")
+	errs.Required("unitOfMeasurement", "hectares")
+	errs.Add(validation.Field("crops[0]", "type"), validation.CodeInvalid, "invalid crop type")
+
+	if len(errs) != 3 {
+		t.Fatalf("Expect 3 problems, but got %d", len(errs))
+	}
+
+	if errs[2].Field != "crops[0].type" {
+		t.Errorf("Expect field to be 'crops[0].type', but got '%s'", errs[2].Field)
+	}
+
+	if errs.Error() != "name is required; address is required; invalid crop type" {
+		t.Errorf("Unexpected error message '%s'", errs.Error())
+	}
+}
+
+func TestErrReturnsNilWithoutProblems(t *testing.T) {
+	var errs validation.Errors
+	errs.Required("name", "Farm 1")
+
+	if errs.Err() != nil {
+		t.Errorf("Expect nil error, but got %v", errs.Err())
+	}
+}
+
+func TestErrorsCanBeUnwrapped(t *testing.T) {
+	var errs validation.Errors
+	errs.Required("name", "")
+	err := errors.Join(errors.New("invalid farm fields"), errs.Err())
+
+	var problems validation.Errors
+	if !errors.As(err, &problems) {
+		t.Fatalf("Expect errors.As to find the problems")
+	}
+
+	if problems[0].Code != validation.CodeRequired {
+		t.Errorf("Expect code to be '%s', but got '%s'", validation.CodeRequired, problems[0].Code)
+	}
+}
+
+func TestQueryBool(t *testing.T) {
+	query := url.Values{"soft": {"true"}, "dryRun": {"maybe"}}
+
+	var errs validation.Errors
+	if !validation.QueryBool(query, "soft", false, &errs) || !validation.QueryBool(query, "missing", true, &errs) {
+		t.Errorf("Expect given and default values to be kept")
+	}
+	if validation.QueryBool(query, "dryRun", false, &errs) || len(errs) != 1 || errs[0].Field != "dryRun" {
+		t.Errorf("Expect a problem on dryRun, but got %+v", errs)
+	}
+}
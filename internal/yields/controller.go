@@ -0,0 +1,127 @@
+package yields
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	http_adapter "github.com/mateusfdl/go-api/adapters/http"
+	"github.com/mateusfdl/go-api/adapters/logger"
+	"github.com/mateusfdl/go-api/internal/ids"
+	"github.com/mateusfdl/go-api/internal/units"
+	"github.com/mateusfdl/go-api/internal/validation"
+)
+
+type Controller struct {
+	s *Service
+	l *logger.Logger
+	h *http_adapter.HTTP
+}
+
+func NewController(h *http_adapter.HTTP, s *Service, l *logger.Logger) *Controller {
+	return &Controller{s: s, l: l, h: h}
+}
+
+// Register all yield routes
+func (c *Controller) RegisterRoutes() {
+	c.l.Info("Registering yield routes")
+	c.registerErrors()
+	c.h.ValidateParam("yieldId", ids.ValidateParam)
+	c.h.Router.HandleFunc("/farms/{id}/crops/{cropId}/yields", c.h.Handle(c.CreateYield)).Methods("POST").Name("CreateYield")
+	c.h.Router.HandleFunc("/farms/{id}/crops/{cropId}/yields", c.h.Handle(c.ListYields)).Methods("GET").Name("ListYields")
+	c.h.Router.HandleFunc("/farms/{id}/crops/{cropId}/yields/summary", c.h.Handle(c.GetSummary)).Methods("GET").Name("GetYieldSummary")
+	c.h.Router.HandleFunc("/farms/{id}/crops/{cropId}/yields/{yieldId}", c.h.Handle(c.GetYieldByID)).Methods("GET").Name("GetYieldByID")
+	c.h.Router.HandleFunc("/farms/{id}/crops/{cropId}/yields/{yieldId}", c.h.Handle(c.UpdateYield)).Methods("PUT").Name("UpdateYield")
+	c.h.Router.HandleFunc("/farms/{id}/crops/{cropId}/yields/{yieldId}", c.h.Handle(c.DeleteYield)).Methods("DELETE").Name("DeleteYield")
+}
+
+// Register the HTTP status of every yield error
+func (c *Controller) registerErrors() {
+	c.h.RegisterError(ErrYieldNotFound, http.StatusNotFound, "YIELD_NOT_FOUND")
+	c.h.RegisterError(ErrInvalidYieldFields, http.StatusBadRequest, "INVALID_YIELD_FIELDS")
+	c.h.RegisterError(ErrCropNotPlanted, http.StatusConflict, "CROP_NOT_PLANTED")
+}
+
+func (c *Controller) CreateYield(w http.ResponseWriter, r *http.Request) error {
+	var dto YieldDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		return fmt.Errorf("%w: %v", http_adapter.ErrMalformedBody, err)
+	}
+
+	vars := mux.Vars(r)
+	id, err := c.s.CreateYield(r.Context(), vars["id"], vars["cropId"], &dto)
+	if err != nil {
+		return err
+	}
+
+	return http_adapter.WriteJSON(w, http.StatusCreated, map[string]string{"id": id})
+}
+
+func (c *Controller) ListYields(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	yields, err := c.s.ListYields(r.Context(), vars["id"], vars["cropId"])
+	if err != nil {
+		return err
+	}
+
+	return http_adapter.WriteJSON(w, http.StatusOK, yields)
+}
+
+func (c *Controller) GetYieldByID(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	yield, err := c.s.GetByID(r.Context(), vars["id"], vars["cropId"], vars["yieldId"])
+	if err != nil {
+		return err
+	}
+
+	return http_adapter.WriteJSON(w, http.StatusOK, yield)
+}
+
+func (c *Controller) UpdateYield(w http.ResponseWriter, r *http.Request) error {
+	var dto YieldDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		return fmt.Errorf("%w: %v", http_adapter.ErrMalformedBody, err)
+	}
+
+	vars := mux.Vars(r)
+	if err := c.s.UpdateYield(r.Context(), vars["id"], vars["cropId"], vars["yieldId"], &dto); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (c *Controller) DeleteYield(w http.ResponseWriter, r *http.Request) error {
+	vars := mux.Vars(r)
+	if err := c.s.DeleteYield(r.Context(), vars["id"], vars["cropId"], vars["yieldId"]); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// Quantities are in kilograms unless another unit is given
+func (c *Controller) GetSummary(w http.ResponseWriter, r *http.Request) error {
+	unit := units.Kilogram
+	if name := r.URL.Query().Get("unit"); name != "" {
+		u, err := units.DefaultMass.Lookup(name)
+		if err != nil {
+			var errs validation.Errors
+			errs.Add("unit", validation.CodeInvalid, "unit must be one of "+strings.Join(units.DefaultMass.Symbols(), ", "))
+			return fmt.Errorf("%w: %w", http_adapter.ErrInvalidQuery, errs)
+		}
+		unit = u
+	}
+
+	vars := mux.Vars(r)
+	summary, err := c.s.GetSummary(r.Context(), vars["id"], vars["cropId"], unit)
+	if err != nil {
+		return err
+	}
+
+	return http_adapter.WriteJSON(w, http.StatusOK, summary)
+}
@@ -0,0 +1,64 @@
+package yields
+
+import (
+	"github.com/mateusfdl/go-api/internal/calendar"
+	"github.com/mateusfdl/go-api/internal/decimal"
+	"github.com/mateusfdl/go-api/internal/units"
+)
+
+// Fields of a yield, taken whole on creation and replacement
+type YieldDTO struct {
+	Quantity *decimal.Decimal `json:"quantity"`
+	Unit     string           `json:"unit"`
+	// Harvest date of the crop when empty, today otherwise
+	HarvestDate *calendar.Date   `json:"harvestDate"`
+	Moisture    *decimal.Decimal `json:"moisture"`
+}
+
+func (d *YieldDTO) ToMap() map[string]interface{} {
+	m := map[string]interface{}{
+		"quantity":    *d.Quantity,
+		"unit":        d.Unit,
+		"kilograms":   d.Kilograms(),
+		"harvestDate": *d.HarvestDate,
+		"moisture":    nil,
+	}
+
+	if d.Moisture != nil {
+		m["moisture"] = *d.Moisture
+	}
+
+	return m
+}
+
+// Quantity normalized to kilograms, zero in an unknown unit
+func (d *YieldDTO) Kilograms() decimal.Decimal {
+	u, err := units.DefaultMass.Lookup(d.Unit)
+	if err != nil || d.Quantity == nil {
+		return decimal.Decimal{}
+	}
+
+	return u.KilogramsOf(*d.Quantity)
+}
+
+// Production of a crop per hectare. Quantities are in Unit.
+type Summary struct {
+	FarmID   string  `json:"farmId"`
+	CropID   string  `json:"cropId"`
+	Unit     string  `json:"unit"`
+	Yields   int     `json:"yields"`
+	Quantity float64 `json:"quantity"`
+	// Area the quantity is spread over, the planted area of the crop when
+	// known and the land area of the farm otherwise, as told by AreaBasis
+	AreaHectares float64 `json:"areaHectares"`
+	AreaBasis    string  `json:"areaBasis"`
+	// Null when the area is unknown
+	YieldPerHectare *float64 `json:"yieldPerHectare"`
+	// Average of the moistures weighted by quantity, null when none was given
+	AverageMoisture *float64 `json:"averageMoisture"`
+}
+
+const (
+	AreaBasisPlantedArea = "plantedArea"
+	AreaBasisLandArea    = "landArea"
+)
@@ -0,0 +1,35 @@
+package yields
+
+import (
+	"time"
+
+	"github.com/mateusfdl/go-api/internal/calendar"
+	"github.com/mateusfdl/go-api/internal/decimal"
+)
+
+// What a crop produced on a harvest
+type Yield struct {
+	ID     string `bson:"_id"`
+	FarmID string `bson:"farmId"`
+	CropID string `bson:"cropId"`
+	// Amount produced, in Unit
+	Quantity decimal.Decimal `bson:"quantity"`
+	Unit     string          `bson:"unit"`
+	// Quantity normalized to kilograms, summed by summaries
+	Kilograms   decimal.Decimal `bson:"kilograms"`
+	HarvestDate calendar.Date   `bson:"harvestDate"`
+	// Moisture content of the harvest, in percent
+	Moisture  *decimal.Decimal `bson:"moisture,omitempty"`
+	CreatedAt time.Time        `bson:"createdAt"`
+	UpdatedAt time.Time        `bson:"updatedAt"`
+}
+
+// Sums of the yields of a crop
+type Totals struct {
+	Yields    int             `bson:"yields"`
+	Kilograms decimal.Decimal `bson:"kilograms"`
+	// Kilograms of the yields with a moisture, and the sum of their
+	// moistures weighted by kilograms
+	MoistureKilograms decimal.Decimal `bson:"moistureKilograms"`
+	WeightedMoisture  decimal.Decimal `bson:"weightedMoisture"`
+}
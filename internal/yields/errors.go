@@ -0,0 +1,10 @@
+package yields
+
+import "errors"
+
+var (
+	ErrYieldNotFound      = errors.New("Yield not found")
+	ErrInvalidYieldFields = errors.New("invalid yield fields")
+	ErrCropNotPlanted     = errors.New("crop was not planted yet")
+	ErrOnConvertObjectID  = errors.New("failed to convert to ObjectID")
+)
@@ -0,0 +1,32 @@
+package yields
+
+import (
+	"github.com/mateusfdl/go-api/adapters/http"
+	"github.com/mateusfdl/go-api/adapters/logger"
+	"github.com/mateusfdl/go-api/internal/audit"
+	"github.com/mateusfdl/go-api/internal/crops"
+	"github.com/mateusfdl/go-api/internal/farms"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type YieldsModule struct {
+	Repository Repository
+	Service    *Service
+	Controller *Controller
+}
+
+func New(
+	l *logger.Logger,
+	cropRepo *crops.Repository,
+	cropService *crops.Service,
+	farmService *farms.Service,
+	h *http.HTTP,
+	db *mongo.Database,
+) *YieldsModule {
+	r := NewMongoRepository(db, l, audit.SystemClock)
+	s := NewService(l, r, *cropRepo, audit.SystemClock)
+	farmService.RegisterDependent("yields", r)
+	cropService.RegisterDependent(r)
+	c := NewController(h, s, l)
+	return &YieldsModule{Repository: r, Service: s, Controller: c}
+}
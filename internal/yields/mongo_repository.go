@@ -0,0 +1,323 @@
+package yields
+
+import (
+	"context"
+	"time"
+
+	"github.com/mateusfdl/go-api/adapters/logger"
+	"github.com/mateusfdl/go-api/internal/audit"
+	"github.com/mateusfdl/go-api/internal/ids"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoRepository struct {
+	db         *mongo.Database
+	l          *logger.Logger
+	timestamps *audit.Timestamps
+}
+
+func NewMongoRepository(db *mongo.Database, l *logger.Logger, clock audit.Clock) *MongoRepository {
+	return &MongoRepository{db: db, l: l, timestamps: audit.NewTimestamps(clock)}
+}
+
+func (r *MongoRepository) Create(
+	ctx context.Context,
+	farmId string,
+	cropId string,
+	dto *YieldDTO,
+) (string, error) {
+	farmOid, err := ids.ToObjectID(farmId)
+	if err != nil {
+		return "", err
+	}
+
+	cropOid, err := ids.ToObjectID(cropId)
+	if err != nil {
+		return "", err
+	}
+
+	fields := dto.ToMap()
+	fields["farmId"] = farmOid
+	fields["cropId"] = cropOid
+	r.timestamps.OnCreate(fields)
+
+	doc, err := r.db.Collection("yields").InsertOne(ctx, fields)
+	if err != nil {
+		r.l.Error("error on create yield", err)
+		return "", err
+	}
+
+	oid, ok := doc.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return "", ErrOnConvertObjectID
+	}
+
+	return oid.Hex(), nil
+}
+
+// Yields of the crop, latest harvests first
+func (r *MongoRepository) List(
+	ctx context.Context,
+	farmId string,
+	cropId string,
+) ([]Yield, error) {
+	filter, err := cropYieldsFilter(farmId, cropId)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "harvestDate", Value: -1}, {Key: "_id", Value: -1}})
+	cursor, err := r.db.Collection("yields").Find(ctx, filter, opts)
+	if err != nil {
+		r.l.Error("error on list yields", err)
+		return nil, err
+	}
+
+	yields := []Yield{}
+	if err := cursor.All(ctx, &yields); err != nil {
+		return nil, err
+	}
+
+	return yields, nil
+}
+
+func (r *MongoRepository) GetByID(
+	ctx context.Context,
+	farmId string,
+	cropId string,
+	yieldId string,
+) (*Yield, error) {
+	filter, err := yieldFilter(farmId, cropId, yieldId)
+	if err != nil {
+		return nil, err
+	}
+
+	var yield Yield
+	err = r.db.Collection("yields").FindOne(ctx, filter).Decode(&yield)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrYieldNotFound
+		}
+
+		r.l.Error("error on get yield by id", err)
+		return nil, err
+	}
+
+	return &yield, nil
+}
+
+func (r *MongoRepository) Update(
+	ctx context.Context,
+	farmId string,
+	cropId string,
+	yieldId string,
+	dto *YieldDTO,
+) error {
+	filter, err := yieldFilter(farmId, cropId, yieldId)
+	if err != nil {
+		return err
+	}
+
+	fields := dto.ToMap()
+	r.timestamps.OnUpdate(fields)
+
+	result, err := r.db.Collection("yields").UpdateOne(ctx, filter, bson.M{"$set": fields})
+	if err != nil {
+		r.l.Error("error on update yield", err)
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrYieldNotFound
+	}
+
+	return nil
+}
+
+func (r *MongoRepository) Delete(
+	ctx context.Context,
+	farmId string,
+	cropId string,
+	yieldId string,
+) error {
+	filter, err := yieldFilter(farmId, cropId, yieldId)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Collection("yields").DeleteOne(ctx, filter)
+	if err != nil {
+		r.l.Error("error on delete yield", err)
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return ErrYieldNotFound
+	}
+
+	return nil
+}
+
+// Sums the yields of the crop in kilograms, along with the moistures
+// weighted by kilograms of the ones that have it
+func (r *MongoRepository) Totals(
+	ctx context.Context,
+	farmId string,
+	cropId string,
+) (*Totals, error) {
+	filter, err := cropYieldsFilter(farmId, cropId)
+	if err != nil {
+		return nil, err
+	}
+
+	hasMoisture := bson.M{"$ne": bson.A{bson.M{"$ifNull": bson.A{"$moisture", nil}}, nil}}
+	cursor, err := r.db.Collection("yields").Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{
+			"_id":       nil,
+			"yields":    bson.M{"$sum": 1},
+			"kilograms": bson.M{"$sum": "$kilograms"},
+			"moistureKilograms": bson.M{"$sum": bson.M{
+				"$cond": bson.A{hasMoisture, "$kilograms", 0},
+			}},
+			"weightedMoisture": bson.M{"$sum": bson.M{
+				"$cond": bson.A{hasMoisture, bson.M{"$multiply": bson.A{"$kilograms", "$moisture"}}, 0},
+			}},
+		}}},
+	})
+	if err != nil {
+		r.l.Error("error on sum yields", err)
+		return nil, err
+	}
+
+	var totals []Totals
+	if err := cursor.All(ctx, &totals); err != nil {
+		return nil, err
+	}
+	if len(totals) == 0 {
+		return &Totals{}, nil
+	}
+
+	return &totals[0], nil
+}
+
+// Removes every yield of the given crop
+func (r *MongoRepository) DeleteByCrop(
+	ctx context.Context,
+	farmId string,
+	cropId string,
+) (int64, error) {
+	filter, err := cropYieldsFilter(farmId, cropId)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := r.db.Collection("yields").DeleteMany(ctx, filter)
+	if err != nil {
+		r.l.Error("error on delete yields by crop", err)
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// Removes every yield of the crops of the given farm
+func (r *MongoRepository) DeleteByFarm(
+	ctx context.Context,
+	farmId string,
+) (int64, error) {
+	oid, err := ids.ToObjectID(farmId)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := r.db.Collection("yields").DeleteMany(ctx, bson.M{"farmId": oid})
+	if err != nil {
+		r.l.Error("error on delete yields by farm", err)
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// Flags every yield of the given farm as deleted at the given time
+func (r *MongoRepository) SoftDeleteByFarm(
+	ctx context.Context,
+	farmId string,
+	at time.Time,
+) (int64, error) {
+	oid, err := ids.ToObjectID(farmId)
+	if err != nil {
+		return 0, err
+	}
+
+	set := bson.M{"deletedAt": at}
+	r.timestamps.OnUpdate(set)
+
+	filter := bson.M{"farmId": oid, "deletedAt": nil}
+	result, err := r.db.Collection("yields").UpdateMany(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		r.l.Error("error on soft delete yields by farm", err)
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// Brings back the yields of the given farm that were soft deleted at the given time
+func (r *MongoRepository) RestoreByFarm(
+	ctx context.Context,
+	farmId string,
+	at time.Time,
+) (int64, error) {
+	oid, err := ids.ToObjectID(farmId)
+	if err != nil {
+		return 0, err
+	}
+
+	set := bson.M{}
+	r.timestamps.OnUpdate(set)
+
+	filter := bson.M{"farmId": oid, "deletedAt": at}
+	update := bson.M{"$set": set, "$unset": bson.M{"deletedAt": ""}}
+
+	result, err := r.db.Collection("yields").UpdateMany(ctx, filter, update)
+	if err != nil {
+		r.l.Error("error on restore yields by farm", err)
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// Builds the filter that scopes yields to their crop, leaving deleted ones out
+func cropYieldsFilter(farmId string, cropId string) (bson.M, error) {
+	farmOid, err := ids.ToObjectID(farmId)
+	if err != nil {
+		return nil, err
+	}
+
+	cropOid, err := ids.ToObjectID(cropId)
+	if err != nil {
+		return nil, err
+	}
+
+	return bson.M{"farmId": farmOid, "cropId": cropOid, "deletedAt": nil}, nil
+}
+
+func yieldFilter(farmId string, cropId string, yieldId string) (bson.M, error) {
+	filter, err := cropYieldsFilter(farmId, cropId)
+	if err != nil {
+		return nil, err
+	}
+
+	filter["_id"], err = ids.ToObjectID(yieldId)
+	if err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,19 @@
+package yields
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	Create(ctx context.Context, farmId string, cropId string, dto *YieldDTO) (string, error)
+	List(ctx context.Context, farmId string, cropId string) ([]Yield, error)
+	GetByID(ctx context.Context, farmId string, cropId string, yieldId string) (*Yield, error)
+	Update(ctx context.Context, farmId string, cropId string, yieldId string, dto *YieldDTO) error
+	Delete(ctx context.Context, farmId string, cropId string, yieldId string) error
+	Totals(ctx context.Context, farmId string, cropId string) (*Totals, error)
+	DeleteByCrop(ctx context.Context, farmId string, cropId string) (int64, error)
+	DeleteByFarm(ctx context.Context, farmId string) (int64, error)
+	SoftDeleteByFarm(ctx context.Context, farmId string, at time.Time) (int64, error)
+	RestoreByFarm(ctx context.Context, farmId string, at time.Time) (int64, error)
+}
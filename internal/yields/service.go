@@ -0,0 +1,197 @@
+package yields
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mateusfdl/go-api/adapters/logger"
+	"github.com/mateusfdl/go-api/internal/audit"
+	"github.com/mateusfdl/go-api/internal/calendar"
+	"github.com/mateusfdl/go-api/internal/crops"
+	"github.com/mateusfdl/go-api/internal/decimal"
+	"github.com/mateusfdl/go-api/internal/units"
+	"github.com/mateusfdl/go-api/internal/validation"
+)
+
+var maxMoisture = decimal.NewFromInt(100)
+
+type Service struct {
+	l              *logger.Logger
+	yieldRepo      Repository
+	cropRepository crops.Repository
+	clock          audit.Clock
+}
+
+func NewService(l *logger.Logger, yieldRepo Repository, cropRepo crops.Repository, clock audit.Clock) *Service {
+	return &Service{l: l, yieldRepo: yieldRepo, cropRepository: cropRepo, clock: clock}
+}
+
+func (s *Service) CreateYield(ctx context.Context, farmId string, cropId string, dto *YieldDTO) (string, error) {
+	crop, err := s.getCrop(ctx, farmId, cropId)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.validate(crop, dto); err != nil {
+		return "", err
+	}
+
+	return s.yieldRepo.Create(ctx, farmId, cropId, dto)
+}
+
+func (s *Service) ListYields(ctx context.Context, farmId string, cropId string) ([]Yield, error) {
+	if _, err := s.getCrop(ctx, farmId, cropId); err != nil {
+		return nil, err
+	}
+
+	return s.yieldRepo.List(ctx, farmId, cropId)
+}
+
+func (s *Service) GetByID(ctx context.Context, farmId string, cropId string, yieldId string) (*Yield, error) {
+	if _, err := s.getCrop(ctx, farmId, cropId); err != nil {
+		return nil, err
+	}
+
+	return s.yieldRepo.GetByID(ctx, farmId, cropId, yieldId)
+}
+
+// Replaces every field of the yield
+func (s *Service) UpdateYield(ctx context.Context, farmId string, cropId string, yieldId string, dto *YieldDTO) error {
+	crop, err := s.getCrop(ctx, farmId, cropId)
+	if err != nil {
+		return err
+	}
+
+	if err := s.validate(crop, dto); err != nil {
+		return err
+	}
+
+	return s.yieldRepo.Update(ctx, farmId, cropId, yieldId, dto)
+}
+
+func (s *Service) DeleteYield(ctx context.Context, farmId string, cropId string, yieldId string) error {
+	if _, err := s.getCrop(ctx, farmId, cropId); err != nil {
+		return err
+	}
+
+	return s.yieldRepo.Delete(ctx, farmId, cropId, yieldId)
+}
+
+// Sums what the crop produced, in unit, and spreads it over the planted area
+// of the crop, or over the land area of the farm when the crop has none
+func (s *Service) GetSummary(ctx context.Context, farmId string, cropId string, unit units.MassUnit) (*Summary, error) {
+	crop, err := s.getCrop(ctx, farmId, cropId)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.yieldRepo.Totals(ctx, farmId, cropId)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &Summary{
+		FarmID:   farmId,
+		CropID:   cropId,
+		Unit:     unit.Symbol,
+		Yields:   totals.Yields,
+		Quantity: units.Round(unit.FromKilograms(totals.Kilograms.Float64()), 2),
+	}
+
+	area, basis, err := s.cropArea(ctx, farmId, crop)
+	if err != nil {
+		return nil, err
+	}
+	if !area.IsZero() {
+		perHectare := units.Round(unit.FromKilograms(totals.Kilograms.Float64())/area.Float64(), 2)
+		summary.AreaHectares = area.Float64()
+		summary.AreaBasis = basis
+		summary.YieldPerHectare = &perHectare
+	}
+
+	if !totals.MoistureKilograms.IsZero() {
+		moisture := units.Round(totals.WeightedMoisture.Float64()/totals.MoistureKilograms.Float64(), 2)
+		summary.AverageMoisture = &moisture
+	}
+
+	return summary, nil
+}
+
+// Area of the crop in hectares, telling where it was taken from
+func (s *Service) cropArea(ctx context.Context, farmId string, crop *crops.Crop) (decimal.Decimal, string, error) {
+	if crop.PlantedAreaHectares != nil && !crop.PlantedAreaHectares.IsZero() {
+		return *crop.PlantedAreaHectares, AreaBasisPlantedArea, nil
+	}
+
+	farm, err := s.cropRepository.GetFarmArea(ctx, farmId)
+	if err != nil {
+		return decimal.Decimal{}, "", err
+	}
+
+	return farm.LandAreaHectares, AreaBasisLandArea, nil
+}
+
+func (s *Service) getCrop(ctx context.Context, farmId string, cropId string) (*crops.Crop, error) {
+	exists, err := s.cropRepository.FarmExists(ctx, farmId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, crops.ErrFarmNotFound
+	}
+
+	return s.cropRepository.GetByID(ctx, farmId, cropId)
+}
+
+// Yields are only recorded on crops that were planted, never before their
+// planting nor in the future. The harvest date defaults to the one of the crop.
+func (s *Service) validate(crop *crops.Crop, dto *YieldDTO) error {
+	if crop.CurrentStatus() == crops.StatusPlanned {
+		return ErrCropNotPlanted
+	}
+
+	if dto.HarvestDate == nil {
+		harvest := calendar.Of(s.clock.Now())
+		if crop.HarvestDate != nil {
+			harvest = *crop.HarvestDate
+		}
+		dto.HarvestDate = &harvest
+	}
+
+	errs := validateFields(dto)
+	if dto.HarvestDate.After(calendar.Of(s.clock.Now())) {
+		errs.Add("harvestDate", validation.CodeInvalid, "harvestDate can not be in the future")
+	}
+	if crop.PlantingDate != nil && dto.HarvestDate.Before(*crop.PlantingDate) {
+		errs.Add("harvestDate", validation.CodeInvalid, "harvestDate can not be before the planting date "+crop.PlantingDate.String())
+	}
+
+	if err := errs.Err(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidYieldFields, err)
+	}
+
+	return nil
+}
+
+func validateFields(dto *YieldDTO) validation.Errors {
+	var errs validation.Errors
+	if dto.Quantity == nil {
+		errs.Add("quantity", validation.CodeRequired, "quantity is required")
+	} else if dto.Quantity.Sign() <= 0 {
+		errs.Add("quantity", validation.CodePositive, "quantity must be positive")
+	}
+
+	if dto.Unit == "" {
+		errs.Add("unit", validation.CodeRequired, "unit is required")
+	} else if _, err := units.DefaultMass.Lookup(dto.Unit); err != nil {
+		errs.Add("unit", validation.CodeInvalid, "unit must be one of "+strings.Join(units.DefaultMass.Symbols(), ", "))
+	}
+
+	if dto.Moisture != nil && (dto.Moisture.Sign() < 0 || dto.Moisture.Cmp(maxMoisture) >= 0) {
+		errs.Add("moisture", validation.CodeInvalid, "moisture must be a percentage, from 0 up to 100")
+	}
+
+	return errs
+}
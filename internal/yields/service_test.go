@@ -0,0 +1,185 @@
+package yields_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mateusfdl/go-api/adapters/logger"
+	"github.com/mateusfdl/go-api/internal/audit"
+	"github.com/mateusfdl/go-api/internal/calendar"
+	"github.com/mateusfdl/go-api/internal/crops"
+	"github.com/mateusfdl/go-api/internal/decimal"
+	"github.com/mateusfdl/go-api/internal/units"
+	"github.com/mateusfdl/go-api/internal/validation"
+	"github.com/mateusfdl/go-api/internal/yields"
+)
+
+const (
+	farmId = "6740c2d1e4b0a1a2b3c4d5e6"
+	cropId = "6740c2d1e4b0a1a2b3c4d5e7"
+)
+
+type fakeCropRepository struct {
+	crops.Repository
+	crop     crops.Crop
+	landArea decimal.Decimal
+}
+
+func (r *fakeCropRepository) FarmExists(ctx context.Context, farmId string) (bool, error) {
+	return true, nil
+}
+
+func (r *fakeCropRepository) GetByID(ctx context.Context, farmId string, cropId string) (*crops.Crop, error) {
+	return &r.crop, nil
+}
+
+func (r *fakeCropRepository) GetFarmArea(ctx context.Context, farmId string) (*crops.FarmArea, error) {
+	return &crops.FarmArea{LandAreaHectares: r.landArea, UnitOfMeasurement: "ha"}, nil
+}
+
+type fakeYieldRepository struct {
+	yields.Repository
+	totals  yields.Totals
+	created []*yields.YieldDTO
+}
+
+func (r *fakeYieldRepository) Create(ctx context.Context, farmId string, cropId string, dto *yields.YieldDTO) (string, error) {
+	r.created = append(r.created, dto)
+	return "6740c2d1e4b0a1a2b3c4d5e8", nil
+}
+
+func (r *fakeYieldRepository) Totals(ctx context.Context, farmId string, cropId string) (*yields.Totals, error) {
+	return &r.totals, nil
+}
+
+func newService(crop crops.Crop, landArea string, totals yields.Totals) (*yields.Service, *fakeYieldRepository) {
+	l := logger.New(logger.Config{Level: "error"})
+	yieldRepo := &fakeYieldRepository{totals: totals}
+	cropRepo := &fakeCropRepository{crop: crop, landArea: decimal.MustParse(landArea)}
+	clock := audit.ClockFunc(func() time.Time { return time.Date(2026, time.April, 1, 12, 0, 0, 0, time.UTC) })
+	return yields.NewService(l, yieldRepo, cropRepo, clock), yieldRepo
+}
+
+func date(s string) *calendar.Date {
+	d, err := calendar.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return &d
+}
+
+func quantity(s string) *decimal.Decimal {
+	d := decimal.MustParse(s)
+	return &d
+}
+
+func TestSummarySpreadsOverPlantedArea(t *testing.T) {
+	planted := decimal.MustParse("40")
+	crop := crops.Crop{Status: crops.StatusHarvested, PlantedAreaHectares: &planted}
+	s, _ := newService(crop, "100", yields.Totals{
+		Yields:            2,
+		Kilograms:         decimal.MustParse("144000"),
+		MoistureKilograms: decimal.MustParse("120000"),
+		WeightedMoisture:  decimal.MustParse("1680000"),
+	})
+
+	summary, err := s.GetSummary(context.Background(), farmId, cropId, units.Sack)
+	if err != nil {
+		t.Fatalf("GetSummary failed: %v", err)
+	}
+
+	if summary.Quantity != 2400 {
+		t.Errorf("Expect 2400 sacks, but got %v", summary.Quantity)
+	}
+	if summary.AreaBasis != yields.AreaBasisPlantedArea || summary.AreaHectares != 40 {
+		t.Errorf("Expect 40 ha of planted area, but got %v ha of %s", summary.AreaHectares, summary.AreaBasis)
+	}
+	if summary.YieldPerHectare == nil || *summary.YieldPerHectare != 60 {
+		t.Errorf("Expect 60 sacks per hectare, but got %v", summary.YieldPerHectare)
+	}
+	if summary.AverageMoisture == nil || *summary.AverageMoisture != 14 {
+		t.Errorf("Expect 14%% moisture, but got %v", summary.AverageMoisture)
+	}
+}
+
+func TestSummaryFallsBackToLandArea(t *testing.T) {
+	crop := crops.Crop{Status: crops.StatusHarvested}
+	s, _ := newService(crop, "80", yields.Totals{Yields: 1, Kilograms: decimal.MustParse("200000")})
+
+	summary, err := s.GetSummary(context.Background(), farmId, cropId, units.Ton)
+	if err != nil {
+		t.Fatalf("GetSummary failed: %v", err)
+	}
+
+	if summary.AreaBasis != yields.AreaBasisLandArea {
+		t.Errorf("Expect land area basis, but got %s", summary.AreaBasis)
+	}
+	if summary.YieldPerHectare == nil || *summary.YieldPerHectare != 2.5 {
+		t.Errorf("Expect 2.5 t per hectare, but got %v", summary.YieldPerHectare)
+	}
+	if summary.AverageMoisture != nil {
+		t.Errorf("Expect no moisture, but got %v", *summary.AverageMoisture)
+	}
+}
+
+func TestCreateYieldRejectsPlannedCrops(t *testing.T) {
+	s, repo := newService(crops.Crop{Status: crops.StatusPlanned}, "80", yields.Totals{})
+
+	dto := &yields.YieldDTO{Quantity: quantity("10"), Unit: "t"}
+	if _, err := s.CreateYield(context.Background(), farmId, cropId, dto); !errors.Is(err, yields.ErrCropNotPlanted) {
+		t.Errorf("Expect ErrCropNotPlanted, but got %v", err)
+	}
+	if len(repo.created) != 0 {
+		t.Errorf("Expect no yield created, but got %d", len(repo.created))
+	}
+}
+
+func TestCreateYieldDefaultsToHarvestDateOfCrop(t *testing.T) {
+	crop := crops.Crop{Status: crops.StatusHarvested, PlantingDate: date("2025-10-01"), HarvestDate: date("2026-03-10")}
+	s, repo := newService(crop, "80", yields.Totals{})
+
+	dto := &yields.YieldDTO{Quantity: quantity("10"), Unit: "sacas"}
+	if _, err := s.CreateYield(context.Background(), farmId, cropId, dto); err != nil {
+		t.Fatalf("CreateYield failed: %v", err)
+	}
+
+	if got := repo.created[0].HarvestDate.String(); got != "2026-03-10" {
+		t.Errorf("Expect harvest date of the crop, but got %s", got)
+	}
+	if got := repo.created[0].Kilograms().String(); got != "600" {
+		t.Errorf("Expect 600 kg, but got %s", got)
+	}
+}
+
+func TestCreateYieldValidatesFields(t *testing.T) {
+	crop := crops.Crop{Status: crops.StatusGrowing, PlantingDate: date("2025-10-01")}
+	s, _ := newService(crop, "80", yields.Totals{})
+
+	dto := &yields.YieldDTO{
+		Quantity:    quantity("-1"),
+		Unit:        "bushels",
+		HarvestDate: date("2025-09-01"),
+		Moisture:    quantity("100"),
+	}
+	_, err := s.CreateYield(context.Background(), farmId, cropId, dto)
+	if !errors.Is(err, yields.ErrInvalidYieldFields) {
+		t.Fatalf("Expect ErrInvalidYieldFields, but got %v", err)
+	}
+
+	var problems validation.Errors
+	if !errors.As(err, &problems) {
+		t.Fatalf("Expect validation errors, but got %v", err)
+	}
+
+	fields := map[string]bool{}
+	for _, p := range problems {
+		fields[p.Field] = true
+	}
+	for _, field := range []string{"quantity", "unit", "harvestDate", "moisture"} {
+		if !fields[field] {
+			t.Errorf("Expect problem on %s, but got %+v", field, problems)
+		}
+	}
+}
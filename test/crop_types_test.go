@@ -0,0 +1,153 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type CropTypeResponse struct {
+	Code       string            `json:"code"`
+	Name       string            `json:"name"`
+	Labels     map[string]string `json:"labels"`
+	Deprecated bool              `json:"deprecated"`
+	Label      string            `json:"label"`
+}
+
+var adminHeaders = map[string]string{"Authorization": "Bearer test-admin-token"}
+
+func TestCropTypes(t *testing.T) {
+	t.Run("Seeded Crop Types", CropTypesSeeded)
+	t.Run("Manage Crop Types", CropTypesManage)
+	t.Run("Crop Type Authorization", CropTypesAuthorization)
+}
+
+// Removes the crop types created by previous runs
+func wipeCropTypes(t *testing.T, codes ...string) {
+	_, err := driver.Mongo.DB.Collection("cropTypes").DeleteMany(context.Background(), bson.M{"_id": bson.M{"$in": codes}})
+	if err != nil {
+		t.Fatalf("Failed to wipe crop types: %v", err)
+	}
+}
+
+func CropTypesSeeded(t *testing.T) {
+	var types []CropTypeResponse
+	w := driver.PerformRequest("GET", "/crop-types", nil)
+	AssertStatusCode(t, w, http.StatusOK)
+	ParseResponse(t, w.Body.Bytes(), &types)
+
+	codes := map[string]bool{}
+	for _, ct := range types {
+		codes[ct.Code] = true
+	}
+	for _, code := range []string{"RICE", "BEANS", "CORN", "COFFEE", "SOYBEANS"} {
+		if !codes[code] {
+			t.Errorf("Expect %s to be seeded, but got %+v", code, types)
+		}
+	}
+}
+
+func CropTypesManage(t *testing.T) {
+	wipeCropTypes(t, "SUGARCANE", "COTTON")
+	farmId := createFarmForCrops(t)
+
+	t.Run("Creates crop type with labels", func(t *testing.T) {
+		var ct CropTypeResponse
+		body := strings.NewReader(`{ "code": "SUGARCANE", "name": "Sugarcane", "labels": { "pt-BR": "Cana-de-açúcar" } }`)
+		w := driver.PerformRequestWithHeaders("POST", "/crop-types", body, adminHeaders)
+		AssertStatusCode(t, w, http.StatusCreated)
+		ParseResponse(t, w.Body.Bytes(), &ct)
+		AssertEqual(t, ct.Code, "SUGARCANE", "Crop type code")
+		AssertEqual(t, ct.Label, "Sugarcane", "Crop type label")
+	})
+
+	t.Run("Localizes the label", func(t *testing.T) {
+		var ct CropTypeResponse
+		w := driver.PerformRequestWithHeaders("GET", "/crop-types/SUGARCANE", nil, map[string]string{"Accept-Language": "pt-BR"})
+		AssertStatusCode(t, w, http.StatusOK)
+		ParseResponse(t, w.Body.Bytes(), &ct)
+		AssertEqual(t, ct.Label, "Cana-de-açúcar", "Crop type label")
+	})
+
+	t.Run("Accepts crops of the new type", func(t *testing.T) {
+		createCrop(t, farmId, `{ "type": "SUGARCANE", "isIrrigated": false, "isInsured": false }`)
+	})
+
+	t.Run("Rejects duplicate crop type", func(t *testing.T) {
+		body := strings.NewReader(`{ "code": "SUGARCANE", "name": "Sugarcane" }`)
+		w := driver.PerformRequestWithHeaders("POST", "/crop-types", body, adminHeaders)
+		AssertStatusCode(t, w, http.StatusConflict)
+		AssertProblemCode(t, w, "CROP_TYPE_ALREADY_EXISTS")
+	})
+
+	t.Run("Rejects invalid code", func(t *testing.T) {
+		body := strings.NewReader(`{ "code": "cotton plant", "name": "Cotton" }`)
+		w := driver.PerformRequestWithHeaders("POST", "/crop-types", body, adminHeaders)
+		AssertStatusCode(t, w, http.StatusBadRequest)
+		AssertProblemField(t, w, "code")
+	})
+
+	t.Run("Deprecated crop type is rejected for new crops", func(t *testing.T) {
+		body := strings.NewReader(`{ "code": "COTTON", "name": "Cotton" }`)
+		w := driver.PerformRequestWithHeaders("POST", "/crop-types", body, adminHeaders)
+		AssertStatusCode(t, w, http.StatusCreated)
+
+		var ct CropTypeResponse
+		w = driver.PerformRequestWithHeaders("DELETE", "/crop-types/COTTON", nil, adminHeaders)
+		AssertStatusCode(t, w, http.StatusOK)
+		ParseResponse(t, w.Body.Bytes(), &ct)
+		AssertEqual(t, ct.Deprecated, true, "Crop type deprecated")
+
+		body = strings.NewReader(`{ "type": "COTTON", "isIrrigated": false, "isInsured": false }`)
+		w = driver.PerformRequest("POST", "/farms/"+farmId+"/crops", body)
+		AssertStatusCode(t, w, http.StatusBadRequest)
+		AssertProblemField(t, w, "type")
+	})
+
+	t.Run("Hides deprecated crop types by default", func(t *testing.T) {
+		var types []CropTypeResponse
+		w := driver.PerformRequest("GET", "/crop-types", nil)
+		ParseResponse(t, w.Body.Bytes(), &types)
+		for _, ct := range types {
+			if ct.Code == "COTTON" {
+				t.Errorf("Expect COTTON to be hidden, but got %+v", ct)
+			}
+		}
+
+		w = driver.PerformRequest("GET", "/crop-types?includeDeprecated=true", nil)
+		ParseResponse(t, w.Body.Bytes(), &types)
+		found := false
+		for _, ct := range types {
+			found = found || ct.Code == "COTTON"
+		}
+		AssertEqual(t, found, true, "Deprecated crop type listed")
+
+		w = driver.PerformRequest("GET", "/crop-types?includeDeprecated=maybe", nil)
+		AssertStatusCode(t, w, http.StatusBadRequest)
+		AssertProblemField(t, w, "includeDeprecated")
+	})
+
+	t.Run("Unknown crop type", func(t *testing.T) {
+		w := driver.PerformRequestWithHeaders("DELETE", "/crop-types/UNKNOWN", nil, adminHeaders)
+		AssertStatusCode(t, w, http.StatusNotFound)
+		AssertProblemCode(t, w, "CROP_TYPE_NOT_FOUND")
+	})
+}
+
+func CropTypesAuthorization(t *testing.T) {
+	t.Run("Rejects writes without the admin token", func(t *testing.T) {
+		body := strings.NewReader(`{ "code": "SUGARCANE", "name": "Sugarcane" }`)
+		w := driver.PerformRequest("POST", "/crop-types", body)
+		AssertStatusCode(t, w, http.StatusUnauthorized)
+		AssertProblemCode(t, w, "UNAUTHORIZED")
+	})
+
+	t.Run("Rejects a wrong admin token", func(t *testing.T) {
+		headers := map[string]string{"Authorization": "Bearer nope"}
+		w := driver.PerformRequestWithHeaders("DELETE", "/crop-types/CORN", nil, headers)
+		AssertStatusCode(t, w, http.StatusUnauthorized)
+	})
+}
@@ -0,0 +1,333 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type CropResponse struct {
+	ID          string    `json:"id"`
+	FarmID      string    `json:"farmId"`
+	Type        string    `json:"type"`
+	IsIrrigated bool      `json:"isIrrigated"`
+	IsInsured   bool      `json:"isInsured"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	PlantedArea *float64  `json:"plantedArea"`
+	// Unit of the planted area
+	UnitOfMeasurement   string `json:"unitOfMeasurement"`
+	Season              string `json:"season"`
+	Status              string `json:"status"`
+	PlantingDate        string `json:"plantingDate"`
+	ExpectedHarvestDate string `json:"expectedHarvestDate"`
+	HarvestDate         string `json:"harvestDate"`
+	StatusHistory       []struct {
+		Status string `json:"status"`
+		Date   string `json:"date"`
+	} `json:"statusHistory"`
+}
+
+type UtilizationResponse struct {
+	Unit              string  `json:"unit"`
+	LandArea          float64 `json:"landArea"`
+	PlantedArea       float64 `json:"plantedArea"`
+	AvailableArea     float64 `json:"availableArea"`
+	PlantedPercentage float64 `json:"plantedPercentage"`
+	CropTypes         []struct {
+		Type        string  `json:"type"`
+		Crops       int     `json:"crops"`
+		PlantedArea float64 `json:"plantedArea"`
+	} `json:"cropTypes"`
+}
+
+func TestCrops(t *testing.T) {
+	t.Run("Create Crop", CropCreate)
+	t.Run("List Crops", CropList)
+	t.Run("Get Crop", CropGet)
+	t.Run("Update Crop", CropUpdate)
+	t.Run("Delete Crop", CropDelete)
+	t.Run("Crop Area Allocation", CropAreaAllocation)
+	t.Run("Crop Lifecycle", CropLifecycle)
+}
+
+func createFarmForCrops(t *testing.T) string {
+	var farmResponse FarmResponse
+	body := strings.NewReader(`{
+    "name": "Farm 1",
+    "landArea": 87,
+    "unitOfMeasurement": "hectares",
+    "address": "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil",
+    "crops": []
+  }`)
+
+	w := driver.PerformRequest("POST", "/farms", body)
+	AssertStatusCode(t, w, http.StatusCreated)
+	ParseResponse(t, w.Body.Bytes(), &farmResponse)
+
+	return farmResponse.ID
+}
+
+func createCrop(t *testing.T, farmId string, body string) string {
+	var cropResponse CropResponse
+	w := driver.PerformRequest("POST", fmt.Sprintf("/farms/%v/crops", farmId), strings.NewReader(body))
+	AssertStatusCode(t, w, http.StatusCreated)
+	ParseResponse(t, w.Body.Bytes(), &cropResponse)
+
+	return cropResponse.ID
+}
+
+func CropCreate(t *testing.T) {
+	farmId := createFarmForCrops(t)
+
+	t.Run("Creates crop for farm", func(t *testing.T) {
+		id := createCrop(t, farmId, `{ "type": "CORN", "isIrrigated": true, "isInsured": false }`)
+		if id == "" {
+			t.Errorf("Expect id, but got empty")
+		}
+	})
+
+	t.Run("Rejects invalid crop type", func(t *testing.T) {
+		w := driver.PerformRequest("POST", fmt.Sprintf("/farms/%v/crops", farmId), strings.NewReader(`{ "type": "INVALID" }`))
+		AssertStatusCode(t, w, http.StatusBadRequest)
+		AssertProblemField(t, w, "type")
+	})
+
+	t.Run("Rejects unknown farm", func(t *testing.T) {
+		w := driver.PerformRequest("POST", "/farms/000000000000000000000000/crops", strings.NewReader(`{ "type": "CORN" }`))
+		AssertStatusCode(t, w, http.StatusNotFound)
+	})
+}
+
+func CropList(t *testing.T) {
+	farmId := createFarmForCrops(t)
+	createCrop(t, farmId, `{ "type": "CORN", "isIrrigated": true, "isInsured": true }`)
+	createCrop(t, farmId, `{ "type": "RICE", "isIrrigated": false, "isInsured": true }`)
+
+	var cropsResponse []CropResponse
+	w := driver.PerformRequest("GET", fmt.Sprintf("/farms/%v/crops", farmId), nil)
+	AssertStatusCode(t, w, http.StatusOK)
+	ParseResponse(t, w.Body.Bytes(), &cropsResponse)
+
+	AssertEqual(t, len(cropsResponse), 2, "Number of crops")
+	for _, crop := range cropsResponse {
+		AssertEqual(t, crop.FarmID, farmId, "Crop farm id")
+	}
+}
+
+func CropGet(t *testing.T) {
+	farmId := createFarmForCrops(t)
+	cropId := createCrop(t, farmId, `{ "type": "COFFEE", "isIrrigated": true, "isInsured": false }`)
+
+	var cropResponse CropResponse
+	w := driver.PerformRequest("GET", fmt.Sprintf("/farms/%v/crops/%v", farmId, cropId), nil)
+	AssertStatusCode(t, w, http.StatusOK)
+	ParseResponse(t, w.Body.Bytes(), &cropResponse)
+
+	AssertEqual(t, cropResponse.Type, "COFFEE", "Crop type")
+	AssertEqual(t, cropResponse.IsIrrigated, true, "Crop isIrrigated")
+	AssertEqual(t, cropResponse.IsInsured, false, "Crop isInsured")
+
+	otherFarmId := createFarmForCrops(t)
+	w = driver.PerformRequest("GET", fmt.Sprintf("/farms/%v/crops/%v", otherFarmId, cropId), nil)
+	AssertStatusCode(t, w, http.StatusNotFound)
+
+	w = driver.PerformRequest("GET", fmt.Sprintf("/farms/%v/crops/not-an-id", farmId), nil)
+	AssertStatusCode(t, w, http.StatusBadRequest)
+	AssertProblemField(t, w, "cropId")
+}
+
+func CropUpdate(t *testing.T) {
+	farmId := createFarmForCrops(t)
+	cropId := createCrop(t, farmId, `{ "type": "BEANS", "isIrrigated": false, "isInsured": false }`)
+
+	body := strings.NewReader(`{ "isInsured": true }`)
+	w := driver.PerformRequest("PUT", fmt.Sprintf("/farms/%v/crops/%v", farmId, cropId), body)
+	AssertStatusCode(t, w, http.StatusOK)
+
+	var cropResponse CropResponse
+	ParseResponse(t, w.Body.Bytes(), &cropResponse)
+	AssertEqual(t, cropResponse.IsInsured, true, "Updated crop isInsured")
+
+	w = driver.PerformRequest("GET", fmt.Sprintf("/farms/%v/crops/%v", farmId, cropId), nil)
+	ParseResponse(t, w.Body.Bytes(), &cropResponse)
+
+	AssertEqual(t, cropResponse.IsInsured, true, "Crop isInsured")
+
+	// Keep untouched fields
+	AssertEqual(t, cropResponse.Type, "BEANS", "Crop type")
+	AssertEqual(t, cropResponse.IsIrrigated, false, "Crop isIrrigated")
+
+	if cropResponse.CreatedAt.IsZero() {
+		t.Errorf("Expect createdAt, but got zero")
+	}
+	if cropResponse.UpdatedAt.Before(cropResponse.CreatedAt) {
+		t.Errorf("Expect updatedAt %v not to be before createdAt %v", cropResponse.UpdatedAt, cropResponse.CreatedAt)
+	}
+}
+
+func CropDelete(t *testing.T) {
+	farmId := createFarmForCrops(t)
+	cropId := createCrop(t, farmId, `{ "type": "SOYBEANS", "isIrrigated": false, "isInsured": false }`)
+
+	w := driver.PerformRequest("DELETE", fmt.Sprintf("/farms/%v/crops/%v", farmId, cropId), nil)
+	AssertStatusCode(t, w, http.StatusNoContent)
+
+	w = driver.PerformRequest("GET", fmt.Sprintf("/farms/%v/crops/%v", farmId, cropId), nil)
+	AssertStatusCode(t, w, http.StatusNotFound)
+
+	w = driver.PerformRequest("DELETE", fmt.Sprintf("/farms/%v/crops/%v", farmId, cropId), nil)
+	AssertStatusCode(t, w, http.StatusNotFound)
+}
+
+func CropAreaAllocation(t *testing.T) {
+	farmId := createFarmForCrops(t)
+	cropsPath := fmt.Sprintf("/farms/%v/crops", farmId)
+	cornId := createCrop(t, farmId, `{ "type": "CORN", "plantedArea": 50 }`)
+
+	t.Run("Inherits the unit of the farm", func(t *testing.T) {
+		var crop CropResponse
+		w := driver.PerformRequest("GET", cropsPath+"/"+cornId, nil)
+		ParseResponse(t, w.Body.Bytes(), &crop)
+		AssertEqual(t, crop.UnitOfMeasurement, "hectares", "Crop unit of measurement")
+	})
+
+	t.Run("Rejects crops exceeding the land area", func(t *testing.T) {
+		w := driver.PerformRequest("POST", cropsPath, strings.NewReader(`{ "type": "SOYBEANS", "plantedArea": 40 }`))
+		AssertStatusCode(t, w, http.StatusConflict)
+		AssertProblemCode(t, w, "PLANTED_AREA_EXCEEDS_LAND_AREA")
+	})
+
+	t.Run("Validates the planted area and its unit", func(t *testing.T) {
+		w := driver.PerformRequest("POST", cropsPath, strings.NewReader(`{ "type": "SOYBEANS", "unitOfMeasurement": "acres" }`))
+		AssertStatusCode(t, w, http.StatusBadRequest)
+		AssertProblemField(t, w, "unitOfMeasurement")
+
+		w = driver.PerformRequest("PUT", cropsPath+"/"+cornId, strings.NewReader(`{ "plantedArea": 1.234 }`))
+		AssertStatusCode(t, w, http.StatusBadRequest)
+		AssertProblemField(t, w, "plantedArea")
+	})
+
+	// 50 acres are about 20.23 hectares
+	createCrop(t, farmId, `{ "type": "SOYBEANS", "plantedArea": 50, "unitOfMeasurement": "acres" }`)
+
+	t.Run("Leaves the updated crop out of the sum", func(t *testing.T) {
+		w := driver.PerformRequest("PUT", cropsPath+"/"+cornId, strings.NewReader(`{ "plantedArea": 66 }`))
+		AssertStatusCode(t, w, http.StatusOK)
+
+		w = driver.PerformRequest("PUT", cropsPath+"/"+cornId, strings.NewReader(`{ "plantedArea": 67 }`))
+		AssertStatusCode(t, w, http.StatusConflict)
+	})
+
+	t.Run("Rejects shrinking the farm below its crops", func(t *testing.T) {
+		headers := map[string]string{"Content-Type": "application/merge-patch+json"}
+		w := driver.PerformRequestWithHeaders("PATCH", "/farms/"+farmId, strings.NewReader(`{ "landArea": 80 }`), headers)
+		AssertStatusCode(t, w, http.StatusConflict)
+		AssertProblemCode(t, w, "PLANTED_AREA_EXCEEDS_LAND_AREA")
+	})
+
+	t.Run("Summarizes utilization", func(t *testing.T) {
+		var utilization UtilizationResponse
+		w := driver.PerformRequest("GET", fmt.Sprintf("/farms/%v/utilization", farmId), nil)
+		AssertStatusCode(t, w, http.StatusOK)
+		ParseResponse(t, w.Body.Bytes(), &utilization)
+
+		AssertEqual(t, utilization.Unit, "ha", "Utilization unit")
+		AssertEqual(t, utilization.LandArea, 87.0, "Land area")
+		AssertEqual(t, utilization.PlantedArea, 86.23, "Planted area")
+		AssertEqual(t, utilization.AvailableArea, 0.77, "Available area")
+		AssertEqual(t, utilization.PlantedPercentage, 99.12, "Planted percentage")
+		AssertEqual(t, len(utilization.CropTypes), 2, "Crop types")
+		AssertEqual(t, utilization.CropTypes[0].Type, "CORN", "Crop type")
+		AssertEqual(t, utilization.CropTypes[0].PlantedArea, 66.0, "Planted area of the crop type")
+	})
+
+	t.Run("Rejects over allocated farms", func(t *testing.T) {
+		w := driver.PerformRequest("POST", "/farms", strings.NewReader(`{
+      "name": "Farm 2", "landArea": 10, "unitOfMeasurement": "ha", "address": "Rua 2",
+      "crops": [{ "type": "CORN", "plantedArea": 6 }, { "type": "RICE", "plantedArea": 5 }]
+    }`))
+		AssertStatusCode(t, w, http.StatusConflict)
+	})
+}
+
+func transitionCrop(farmId, cropId, body string) *httptest.ResponseRecorder {
+	path := fmt.Sprintf("/farms/%v/crops/%v/transitions", farmId, cropId)
+	return driver.PerformRequest("POST", path, strings.NewReader(body))
+}
+
+func CropLifecycle(t *testing.T) {
+	farmId := createFarmForCrops(t)
+	cropId := createCrop(t, farmId, `{ "type": "SOYBEANS", "season": "2025/26", "expectedHarvestDate": "2026-03-15" }`)
+
+	t.Run("Crops start planned", func(t *testing.T) {
+		var crop CropResponse
+		w := driver.PerformRequest("GET", fmt.Sprintf("/farms/%v/crops/%v", farmId, cropId), nil)
+		ParseResponse(t, w.Body.Bytes(), &crop)
+		AssertEqual(t, crop.Status, "planned", "Crop status")
+		AssertEqual(t, crop.Season, "2025/26", "Crop season")
+		AssertEqual(t, crop.ExpectedHarvestDate, "2026-03-15", "Crop expected harvest date")
+	})
+
+	t.Run("Rejects skipping steps", func(t *testing.T) {
+		w := transitionCrop(farmId, cropId, `{ "status": "harvested", "date": "2026-03-10" }`)
+		AssertStatusCode(t, w, http.StatusConflict)
+		AssertProblemCode(t, w, "INVALID_STATUS_TRANSITION")
+	})
+
+	t.Run("Plants, grows and harvests", func(t *testing.T) {
+		var crop CropResponse
+		w := transitionCrop(farmId, cropId, `{ "status": "planted", "date": "2025-10-01" }`)
+		AssertStatusCode(t, w, http.StatusOK)
+		ParseResponse(t, w.Body.Bytes(), &crop)
+		AssertEqual(t, crop.PlantingDate, "2025-10-01", "Crop planting date")
+
+		w = transitionCrop(farmId, cropId, `{ "status": "growing", "date": "2025-10-20" }`)
+		AssertStatusCode(t, w, http.StatusOK)
+
+		w = transitionCrop(farmId, cropId, `{ "status": "harvested", "date": "2025-09-01" }`)
+		AssertStatusCode(t, w, http.StatusBadRequest)
+		AssertProblemField(t, w, "date")
+
+		w = transitionCrop(farmId, cropId, `{ "status": "harvested", "date": "2026-03-10" }`)
+		AssertStatusCode(t, w, http.StatusOK)
+		ParseResponse(t, w.Body.Bytes(), &crop)
+		AssertEqual(t, crop.Status, "harvested", "Crop status")
+		AssertEqual(t, crop.HarvestDate, "2026-03-10", "Crop harvest date")
+		AssertEqual(t, len(crop.StatusHistory), 3, "Status changes")
+	})
+
+	t.Run("Harvested crops are done with", func(t *testing.T) {
+		w := transitionCrop(farmId, cropId, `{ "status": "failed" }`)
+		AssertStatusCode(t, w, http.StatusConflict)
+	})
+
+	t.Run("Rejects future dates", func(t *testing.T) {
+		otherId := createCrop(t, farmId, `{ "type": "CORN" }`)
+		w := transitionCrop(farmId, otherId, `{ "status": "planted", "date": "2999-01-01" }`)
+		AssertStatusCode(t, w, http.StatusBadRequest)
+		AssertProblemField(t, w, "date")
+	})
+
+	t.Run("Validates lifecycle fields", func(t *testing.T) {
+		cropsPath := fmt.Sprintf("/farms/%v/crops", farmId)
+		w := driver.PerformRequest("POST", cropsPath, strings.NewReader(`{ "type": "CORN", "season": "2025/27" }`))
+		AssertStatusCode(t, w, http.StatusBadRequest)
+		AssertProblemField(t, w, "season")
+
+		w = driver.PerformRequest("POST", cropsPath, strings.NewReader(`{ "type": "CORN", "status": "growing" }`))
+		AssertStatusCode(t, w, http.StatusBadRequest)
+		AssertProblemField(t, w, "plantingDate")
+
+		w = driver.PerformRequest("PUT", cropsPath+"/"+cropId, strings.NewReader(`{ "status": "failed" }`))
+		AssertStatusCode(t, w, http.StatusBadRequest)
+		AssertProblemField(t, w, "status")
+
+		w = driver.PerformRequest("PUT", cropsPath+"/"+cropId, strings.NewReader(`{ "expectedHarvestDate": "2025-09-01" }`))
+		AssertStatusCode(t, w, http.StatusBadRequest)
+		AssertProblemField(t, w, "expectedHarvestDate")
+	})
+}
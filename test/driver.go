@@ -12,7 +12,9 @@ import (
 	"github.com/mateusfdl/go-api/adapters/mongo"
 	"github.com/mateusfdl/go-api/config"
 	"github.com/mateusfdl/go-api/internal/crops"
+	"github.com/mateusfdl/go-api/internal/croptypes"
 	"github.com/mateusfdl/go-api/internal/farms"
+	"github.com/mateusfdl/go-api/internal/yields"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
@@ -20,6 +22,7 @@ type Driver struct {
 	Server *http_adapter.HTTP
 	Mongo  *mongo.Mongo
 	Logger *logger.Logger
+	Config config.AppConfig
 	ctx    context.Context
 }
 
@@ -37,16 +40,26 @@ func NewDriver() *Driver {
 	db := mongo.New(ctx, l, c.Mongo)
 	h := http_adapter.New(l, c.HTTP)
 
-	return &Driver{Server: h, Mongo: db, Logger: l, ctx: ctx}
+	return &Driver{Server: h, Mongo: db, Logger: l, Config: c, ctx: ctx}
 }
 
 func (s *Driver) Start() {
-	cropsModule := crops.New(s.Mongo.DB)
-	farmsModule := farms.New(s.Logger, &cropsModule.Repository, s.Server, s.Mongo.DB)
+	cropTypesModule := croptypes.New(s.Logger, s.Server, s.Mongo.DB, s.Config.CropTypes)
+	cropsModule := crops.New(s.Logger, s.Server, s.Mongo.DB, cropTypesModule.Catalog, s.Config.Crops)
+	farmsModule := farms.New(s.Logger, &cropsModule.Repository, cropTypesModule.Catalog, s.Server, s.Mongo.DB, s.Config.Farms)
+	yieldsModule := yields.New(s.Logger, &cropsModule.Repository, cropsModule.Service, farmsModule.Service, s.Server, s.Mongo.DB)
 
 	mongo.HookOnStart(s.ctx, s.Mongo, s.Logger)
+	if err := cropTypesModule.Service.Bootstrap(s.ctx); err != nil {
+		panic(err)
+	}
 
-	http_adapter.RegisterRoutes(farmsModule.Controller)
+	http_adapter.RegisterRoutes(
+		farmsModule.Controller,
+		cropsModule.Controller,
+		cropTypesModule.Controller,
+		yieldsModule.Controller,
+	)
 	go s.Server.Listen()
 }
 
@@ -56,7 +69,14 @@ func (s *Driver) Close() {
 }
 
 func (s *Driver) PerformRequest(method, path string, body io.Reader) *httptest.ResponseRecorder {
+	return s.PerformRequestWithHeaders(method, path, body, nil)
+}
+
+func (s *Driver) PerformRequestWithHeaders(method, path string, body io.Reader, headers map[string]string) *httptest.ResponseRecorder {
 	req := httptest.NewRequest(method, path, body)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
 	w := httptest.NewRecorder()
 	s.Server.Router.ServeHTTP(w, req)
 	return w
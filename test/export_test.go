@@ -0,0 +1,91 @@
+package test
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestFarmExport(t *testing.T) {
+	t.Run("Export CSV", FarmExportCSV)
+	t.Run("Export NDJSON", FarmExportNDJSON)
+	t.Run("Export GeoJSON", FarmExportGeoJSON)
+	t.Run("Invalid Format", FarmExportInvalidFormat)
+}
+
+func seedExportFarms(t *testing.T) {
+	driver.WipeCollections(t, "farms", "crops")
+	report := importFarms(t, "/farms/import", "application/x-ndjson",
+		`{"name": "Farm 1", "landArea": 10, "unitOfMeasurement": "ha", "address": "Rua 1, Sorriso - MT", "location": {"type": "Point", "coordinates": [-55.71, -12.54]}, "crops": [{"type": "CORN", "isIrrigated": true}, {"type": "SOYBEANS"}]}`+"\n"+
+			`{"name": "Farm 2", "landArea": 5, "unitOfMeasurement": "ha", "address": "Rua 2, Chapecó - SC"}`+"\n",
+	)
+	AssertEqual(t, report.Created, 2, "Created farms")
+}
+
+func FarmExportCSV(t *testing.T) {
+	seedExportFarms(t)
+	w := driver.PerformRequest("GET", "/farms/export?format=csv&sort=name", nil)
+	AssertStatusCode(t, w, http.StatusOK)
+	AssertEqual(t, w.Header().Get("Content-Type"), "text/csv; charset=utf-8", "Content type")
+	AssertEqual(t, w.Header().Get("Content-Disposition"), `attachment; filename="farms.csv"`, "Content disposition")
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read the export: %v", err)
+	}
+	AssertEqual(t, len(records), 4, "Header and rows")
+	AssertEqual(t, records[1][1], "Farm 1", "Name of the first row")
+	AssertEqual(t, records[2][1], "Farm 1", "Name of the second row")
+	AssertEqual(t, records[3][1], "Farm 2", "Name of the farm without crops")
+
+	w = driver.PerformRequest("GET", "/farms/export?state=SC", nil)
+	AssertStatusCode(t, w, http.StatusOK)
+	AssertEqual(t, strings.Count(w.Body.String(), "\n"), 2, "Lines of the filtered export")
+}
+
+func FarmExportNDJSON(t *testing.T) {
+	seedExportFarms(t)
+	w := driver.PerformRequest("GET", "/farms/export?format=ndjson&cropType=CORN&unit=ac", nil)
+	AssertStatusCode(t, w, http.StatusOK)
+	AssertEqual(t, w.Header().Get("Content-Type"), "application/x-ndjson", "Content type")
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	AssertEqual(t, len(lines), 1, "Exported farms")
+
+	var farm FarmResponse
+	ParseResponse(t, []byte(lines[0]), &farm)
+	AssertEqual(t, farm.Name, "Farm 1", "Exported farm name")
+	AssertEqual(t, len(farm.Crops), 2, "Exported farm crops")
+	AssertEqual(t, farm.ConvertedLandArea.Unit, "ac", "Converted land area unit")
+}
+
+func FarmExportGeoJSON(t *testing.T) {
+	seedExportFarms(t)
+	w := driver.PerformRequest("GET", "/farms/export?format=geojson&sort=name", nil)
+	AssertStatusCode(t, w, http.StatusOK)
+	AssertEqual(t, w.Header().Get("Content-Type"), "application/geo+json", "Content type")
+
+	var collection struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Geometry *struct {
+				Type string `json:"type"`
+			} `json:"geometry"`
+			Properties FarmResponse `json:"properties"`
+		} `json:"features"`
+	}
+	ParseResponse(t, w.Body.Bytes(), &collection)
+	AssertEqual(t, collection.Type, "FeatureCollection", "GeoJSON type")
+	AssertEqual(t, len(collection.Features), 2, "Exported features")
+	AssertEqual(t, collection.Features[0].Geometry.Type, "Point", "Geometry of the located farm")
+	AssertEqual(t, collection.Features[0].Properties.Name, "Farm 1", "Properties of the located farm")
+	AssertEqual(t, collection.Features[1].Geometry == nil, true, "Geometry of the farm without location")
+}
+
+func FarmExportInvalidFormat(t *testing.T) {
+	w := driver.PerformRequest("GET", "/farms/export?format=xlsx", nil)
+	AssertStatusCode(t, w, http.StatusBadRequest)
+	AssertProblemField(t, w, "format")
+	AssertEqual(t, w.Header().Get("Content-Disposition"), "", "Content disposition of errors")
+}
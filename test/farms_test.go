@@ -1,39 +1,102 @@
 package test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
 	"testing"
-)
+	"time"
 
-var (
-	driver *Driver = NewDriver()
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 type FarmResponse struct {
 	ID                string `json:"id"`
 	Name              string `json:"name"`
 	Address           string `json:"address"`
-	LandArea          int    `json:"landArea"`
-	UnitOfMeasurement string `json:"unitOfMeasurement"`
-	Crops             []struct {
+	StructuredAddress *struct {
+		Street string `json:"street"`
+		Number string `json:"number"`
+		City   string `json:"city"`
+		State  string `json:"state"`
+	} `json:"structuredAddress"`
+	LandArea          float64 `json:"landArea"`
+	UnitOfMeasurement string  `json:"unitOfMeasurement"`
+	LandAreaHectares  float64 `json:"landAreaHectares"`
+	ConvertedLandArea *struct {
+		Value float64 `json:"value"`
+		Unit  string  `json:"unit"`
+	} `json:"convertedLandArea"`
+	Location *struct {
+		Type        string    `json:"type"`
+		Coordinates []float64 `json:"coordinates"`
+	} `json:"location"`
+	BoundaryAreaHectares *float64  `json:"boundaryAreaHectares"`
+	CreatedAt            time.Time `json:"createdAt"`
+	UpdatedAt            time.Time `json:"updatedAt"`
+	Crops                []struct {
 		Type        string `json:"type"`
 		IsIrrigated bool   `json:"isIrrigated"`
 		IsInsured   bool   `json:"isInsured"`
 	} `json:"crops"`
 }
 
-func TestFarm(t *testing.T) {
-	driver.Start()
-	defer driver.Close()
+type FarmListResponse struct {
+	Items      []FarmResponse `json:"items"`
+	NextCursor *string        `json:"nextCursor"`
+	TotalCount *int64         `json:"totalCount"`
+}
 
+func TestFarm(t *testing.T) {
 	t.Run("Create Farm", CreateFarm)
 	t.Run("List Farms", ListFarms)
+	t.Run("Filter And Sort Farms", FilterAndSortFarms)
+	t.Run("Paginate Farms Lacking Sort Fields", PaginateFarmsLackingSortFields)
+	t.Run("Search Farms", SearchFarms)
+	t.Run("Farm Land Area Units", FarmLandAreaUnits)
+	t.Run("Farm Geolocation", FarmGeolocation)
+	t.Run("Farm Address", FarmAddress)
 	t.Run("Get Farm", FarmGet)
 	t.Run("Update Farm", FarmUpdate)
+	t.Run("Patch Farm", FarmPatch)
+	t.Run("Farm Concurrency Control", FarmConcurrencyControl)
 	t.Run("Delete Farm", FarmDelete)
+	t.Run("Malformed Farm ID", FarmMalformedID)
+}
+
+// Farms written before timestamps and normalized land areas lack the fields
+// they are sorted by, which must not make them drop out of later pages
+func PaginateFarmsLackingSortFields(t *testing.T) {
+	driver.WipeCollections(t, "farms", "crops")
+	_, err := driver.Mongo.DB.Collection("farms").InsertMany(context.Background(), []interface{}{
+		bson.M{"name": "Legacy 1", "address": "Rua 1", "landArea": 10, "unitOfMeasurement": "ha"},
+		bson.M{"name": "Legacy 2", "address": "Rua 2", "landArea": 20, "unitOfMeasurement": "ha", "landAreaHectares": nil, "createdAt": nil},
+		bson.M{"name": "Legacy 3", "address": "Rua 3", "landArea": 30, "unitOfMeasurement": "ha"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to insert legacy farms: %v", err)
+	}
+
+	for _, sort := range []string{"", "&sort=landArea", "&sort=-createdAt"} {
+		seen := 0
+		path := "/farms?limit=2" + sort
+		for path != "" {
+			var response FarmListResponse
+			w := driver.PerformRequest("GET", path, nil)
+			AssertStatusCode(t, w, http.StatusOK)
+			ParseResponse(t, w.Body.Bytes(), &response)
+
+			seen += len(response.Items)
+			path = ""
+			if response.NextCursor != nil {
+				path = "/farms?limit=2" + sort + "&after=" + *response.NextCursor
+			}
+		}
+
+		AssertEqual(t, seen, 3, "Farms listed across pages with sort "+sort)
+	}
 }
 
 func CreateFarm(t *testing.T) {
@@ -111,39 +174,51 @@ func CreateFarmWithCrops(t *testing.T) {
 
 func CreateFarmComplianceFields(t *testing.T) {
 	tests := []struct {
-		name   string
-		body   string
-		expect int
+		name  string
+		body  string
+		field string
 	}{
 		{
-			name: "Missing Name",
-			body: `{ "landArea": 29, "unitOfMeasurement": "hectares", "address": "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil", "crops": [] }`,
+			name:  "Missing Name",
+			body:  `{ "landArea": 29, "unitOfMeasurement": "hectares", "address": "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil", "crops": [] }`,
+			field: "name",
 		},
 		{
-			name: "Missing Land Area",
-			body: `{ "name": "Farm 1", "unitOfMeasurement": "hectares", "address": "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil", "crops": [] }`,
+			name:  "Missing Land Area",
+			body:  `{ "name": "Farm 1", "unitOfMeasurement": "hectares", "address": "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil", "crops": [] }`,
+			field: "landArea",
 		},
 		{
-			name: "Missing Unit of Measurement",
-			body: `{ "name": "Farm 1", "landArea": 29, "address": "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil", "crops": [] }`,
+			name:  "Too Precise Land Area",
+			body:  `{ "name": "Farm 1", "landArea": 12.125, "unitOfMeasurement": "hectares", "address": "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil", "crops": [] }`,
+			field: "landArea",
 		},
 		{
-			name: "Missing Address",
-			body: `{ "name": "Farm 1", "landArea": 29, "unitOfMeasurement": "hectares", "crops": [] }`,
+			name:  "Missing Unit of Measurement",
+			body:  `{ "name": "Farm 1", "landArea": 29, "address": "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil", "crops": [] }`,
+			field: "unitOfMeasurement",
 		},
 		{
-			name: "Missing Crop Type",
-			body: `{ "name": "Farm 1", "landArea": 29, "unitOfMeasurement": "hectares", "address": "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil", "crops": [ { "isIrrigated": true, "isInsured": true } ] }`,
+			name:  "Missing Address",
+			body:  `{ "name": "Farm 1", "landArea": 29, "unitOfMeasurement": "hectares", "crops": [] }`,
+			field: "address",
 		},
 		{
-			name: "Invalid Crop Type",
-			body: `{ "name": "Farm 1", "landArea": 29, "unitOfMeasurement": "hectares", "address": "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil", "crops": [ { "type": "INVALID", "isIrrigated": true, "isInsured": true } ] }`,
+			name:  "Missing Crop Type",
+			body:  `{ "name": "Farm 1", "landArea": 29, "unitOfMeasurement": "hectares", "address": "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil", "crops": [ { "isIrrigated": true, "isInsured": true } ] }`,
+			field: "crops[0].type",
+		},
+		{
+			name:  "Invalid Crop Type",
+			body:  `{ "name": "Farm 1", "landArea": 29, "unitOfMeasurement": "hectares", "address": "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil", "crops": [ { "type": "INVALID", "isIrrigated": true, "isInsured": true } ] }`,
+			field: "crops[0].type",
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			w := driver.PerformRequest("POST", "/farms", strings.NewReader(tt.body))
 			AssertStatusCode(t, w, http.StatusBadRequest)
+			AssertProblemField(t, w, tt.field)
 		})
 	}
 }
@@ -152,7 +227,7 @@ func ListFarms(t *testing.T) {
 	driver.WipeCollections(t, "farms", "crops")
 	firstFarm := map[string]interface{}{
 		"name":              "Farm 1",
-		"landArea":          29,
+		"landArea":          29.0,
 		"unitOfMeasurement": "hectares",
 		"address":           "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil",
 		"crops":             []interface{}{map[string]interface{}{"type": "CORN", "isIrrigated": true, "isInsured": true}},
@@ -160,7 +235,7 @@ func ListFarms(t *testing.T) {
 
 	secondFarm := map[string]interface{}{
 		"name":              "Farm 2",
-		"landArea":          39,
+		"landArea":          39.0,
 		"unitOfMeasurement": "hectares",
 		"address":           "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil",
 		"crops":             []interface{}{map[string]interface{}{"type": "COFFEE", "isIrrigated": true, "isInsured": true}},
@@ -180,15 +255,15 @@ func ListFarms(t *testing.T) {
 		farmsMap[farmResponse.ID] = farm
 	}
 
-	var farmsResponse []FarmResponse
+	var farmsResponse FarmListResponse
 
 	t.Run("List farms", func(t *testing.T) {
-		w := driver.PerformRequest("GET", "/farms?skip=0&limit=10", nil)
+		w := driver.PerformRequest("GET", "/farms", nil)
 		AssertStatusCode(t, w, http.StatusOK)
 		ParseResponse(t, w.Body.Bytes(), &farmsResponse)
-		AssertEqual(t, len(farmsResponse), 2, "Number of farms")
+		AssertEqual(t, len(farmsResponse.Items), 2, "Number of farms")
 
-		for _, farm := range farmsResponse {
+		for _, farm := range farmsResponse.Items {
 			expectFarm := farmsMap[farm.ID].(map[string]interface{})
 
 			AssertEqual(t, farm.Name, expectFarm["name"], "Farm name")
@@ -207,43 +282,426 @@ func ListFarms(t *testing.T) {
 	})
 
 	t.Run("Filter farms by land area", func(t *testing.T) {
-		w := driver.PerformRequest("GET", "/farms?skip=0&limit=25&landArea=39", nil)
+		w := driver.PerformRequest("GET", "/farms?landArea=39", nil)
 		ParseResponse(t, w.Body.Bytes(), &farmsResponse)
-		expectFarm := farmsMap[farmsResponse[0].ID].(map[string]interface{})
+		expectFarm := farmsMap[farmsResponse.Items[0].ID].(map[string]interface{})
 
-		AssertEqual(t, len(farmsResponse), 1, "Number of farms")
-		AssertEqual(t, farmsResponse[0].LandArea, expectFarm["landArea"], "Farm land area")
+		AssertEqual(t, len(farmsResponse.Items), 1, "Number of farms")
+		AssertEqual(t, farmsResponse.Items[0].LandArea, expectFarm["landArea"], "Farm land area")
 	})
 
 	t.Run("Filter farms by crop type", func(t *testing.T) {
-		w := driver.PerformRequest("GET", "/farms?skip=0&limit=25&cropType=CORN", nil)
+		w := driver.PerformRequest("GET", "/farms?cropType=CORN", nil)
 		ParseResponse(t, w.Body.Bytes(), &farmsResponse)
 
-		AssertEqual(t, len(farmsResponse), 1, "Number of farms")
-		AssertEqual(t, len(farmsResponse[0].Crops), 1, "Number of crops")
-		AssertEqual(t, farmsResponse[0].Crops[0].Type, "CORN", "Crop type")
+		AssertEqual(t, len(farmsResponse.Items), 1, "Number of farms")
+		AssertEqual(t, len(farmsResponse.Items[0].Crops), 1, "Number of crops")
+		AssertEqual(t, farmsResponse.Items[0].Crops[0].Type, "CORN", "Crop type")
 	})
 
 	t.Run("Returns paginated results", func(t *testing.T) {
-		w := driver.PerformRequest("GET", "/farms?skip=0&limit=1", nil)
+		w := driver.PerformRequest("GET", "/farms?limit=1&totalCount=true", nil)
 		ParseResponse(t, w.Body.Bytes(), &farmsResponse)
 
-		AssertEqual(t, len(farmsResponse), 1, "Number of farms")
-		AssertEqual(t, farmsResponse[0].Name, "Farm 1", "Farm name")
+		// Newest first
+		AssertEqual(t, len(farmsResponse.Items), 1, "Number of farms")
+		AssertEqual(t, farmsResponse.Items[0].Name, "Farm 2", "Farm name")
+		if farmsResponse.TotalCount == nil || *farmsResponse.TotalCount != 2 {
+			t.Errorf("Expect total count 2, but got %v", farmsResponse.TotalCount)
+		}
+		if farmsResponse.NextCursor == nil {
+			t.Fatalf("Expect next cursor, but got nil")
+		}
 
-		w = driver.PerformRequest("GET", "/farms?skip=1&limit=1", nil)
+		w = driver.PerformRequest("GET", "/farms?limit=1&after="+*farmsResponse.NextCursor, nil)
+		farmsResponse = FarmListResponse{}
 		ParseResponse(t, w.Body.Bytes(), &farmsResponse)
 
-		AssertEqual(t, len(farmsResponse), 1, "Number of farms")
-		AssertEqual(t, farmsResponse[0].Name, "Farm 2", "Farm name")
+		AssertEqual(t, len(farmsResponse.Items), 1, "Number of farms")
+		AssertEqual(t, farmsResponse.Items[0].Name, "Farm 1", "Farm name")
+		if farmsResponse.NextCursor != nil {
+			t.Errorf("Expect no next cursor on the last page")
+		}
+		if farmsResponse.TotalCount != nil {
+			t.Errorf("Expect total count only when requested")
+		}
+	})
+
+	t.Run("Rejects malformed pagination", func(t *testing.T) {
+		w := driver.PerformRequest("GET", "/farms?limit=101", nil)
+		AssertStatusCode(t, w, http.StatusBadRequest)
+		AssertProblemField(t, w, "limit")
+
+		w = driver.PerformRequest("GET", "/farms?after=not-a-cursor", nil)
+		AssertStatusCode(t, w, http.StatusBadRequest)
+		AssertProblemField(t, w, "after")
 	})
 
 	driver.WipeCollections(t, "farms", "crops")
 	t.Run("Returns empty results", func(t *testing.T) {
-		w := driver.PerformRequest("GET", "/farms?skip=0&limit=25", nil)
+		w := driver.PerformRequest("GET", "/farms", nil)
+		AssertStatusCode(t, w, http.StatusOK)
 		ParseResponse(t, w.Body.Bytes(), &farmsResponse)
 
-		AssertEqual(t, len(farmsResponse), 0, "Number of farms")
+		AssertEqual(t, len(farmsResponse.Items), 0, "Number of farms")
+	})
+}
+
+func FilterAndSortFarms(t *testing.T) {
+	driver.WipeCollections(t, "farms", "crops")
+	for _, body := range []string{
+		`{"name": "Bravo", "landArea": 29, "unitOfMeasurement": "hectares", "address": "Rua 1",
+		  "crops": [{"type": "CORN", "isIrrigated": true, "isInsured": false}]}`,
+		`{"name": "Alpha", "landArea": 39, "unitOfMeasurement": "hectares", "address": "Rua 2",
+		  "crops": [{"type": "COFFEE", "isIrrigated": false, "isInsured": true}]}`,
+		`{"name": "Charlie", "landArea": 50, "unitOfMeasurement": "acres", "address": "Rua 3",
+		  "crops": [{"type": "RICE", "isIrrigated": true, "isInsured": true}]}`,
+	} {
+		w := driver.PerformRequest("POST", "/farms", strings.NewReader(body))
+		AssertStatusCode(t, w, http.StatusCreated)
+	}
+
+	names := func(t *testing.T, path string) []string {
+		w := driver.PerformRequest("GET", path, nil)
+		AssertStatusCode(t, w, http.StatusOK)
+
+		var response FarmListResponse
+		ParseResponse(t, w.Body.Bytes(), &response)
+
+		names := []string{}
+		for _, farm := range response.Items {
+			names = append(names, farm.Name)
+		}
+		return names
+	}
+
+	cases := []struct {
+		name   string
+		path   string
+		expect []string
+	}{
+		{"Land area range", "/farms?landAreaMin=30&landAreaMax=45", []string{"Alpha"}},
+		{"Legacy land area", "/farms?landArea=29&sort=name", []string{"Alpha", "Bravo"}},
+		{"Land area range in acres", "/farms?landAreaMin=40&landAreaMax=80&unit=acres", []string{"Charlie"}},
+		{"Multiple crop types", "/farms?cropType=CORN,RICE&sort=name", []string{"Bravo", "Charlie"}},
+		{"Repeated crop types", "/farms?cropType=CORN&cropType=COFFEE&sort=name", []string{"Alpha", "Bravo"}},
+		{"Irrigated crops", "/farms?isIrrigated=true&sort=name", []string{"Bravo", "Charlie"}},
+		{"Same crop matches every crop filter", "/farms?cropType=CORN&isInsured=true", []string{}},
+		{"Unit of measurement", "/farms?unitOfMeasurement=acres", []string{"Charlie"}},
+		{"Unit of measurement alias", "/farms?unitOfMeasurement=AC", []string{"Charlie"}},
+		{"Created range", "/farms?createdFrom=2000-01-01&createdTo=2000-12-31", []string{}},
+		{"Sort by name", "/farms?sort=name", []string{"Alpha", "Bravo", "Charlie"}},
+		{"Sort by land area descending", "/farms?sort=-landArea", []string{"Alpha", "Bravo", "Charlie"}},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			AssertEqual(t, fmt.Sprint(names(t, tt.path)), fmt.Sprint(tt.expect), "Farm names")
+		})
+	}
+
+	t.Run("Paginates sorted listing", func(t *testing.T) {
+		var response FarmListResponse
+		w := driver.PerformRequest("GET", "/farms?sort=name&limit=2", nil)
+		ParseResponse(t, w.Body.Bytes(), &response)
+		if response.NextCursor == nil {
+			t.Fatalf("Expect next cursor, but got nil")
+		}
+
+		AssertEqual(t, fmt.Sprint(names(t, "/farms?sort=name&limit=2&after="+*response.NextCursor)), "[Charlie]", "Farm names")
+
+		// Cursors only resume the sort they were taken with
+		w = driver.PerformRequest("GET", "/farms?sort=-landArea&after="+*response.NextCursor, nil)
+		AssertStatusCode(t, w, http.StatusBadRequest)
+		AssertProblemCode(t, w, "INVALID_CURSOR")
+	})
+
+	t.Run("Rejects invalid filters", func(t *testing.T) {
+		for path, field := range map[string]string{
+			"/farms?sort=address":                  "sort",
+			"/farms?cropType=WHEAT":                "cropType",
+			"/farms?isIrrigated=maybe":             "isIrrigated",
+			"/farms?createdFrom=yesterday":         "createdFrom",
+			"/farms?landAreaMin=50&landAreaMax=10": "landAreaMin",
+			"/farms?landAreaMax=many":              "landAreaMax",
+			"/farms?unit=furlongs":                 "unit",
+			"/farms?unitOfMeasurement=furlongs":    "unitOfMeasurement",
+		} {
+			w := driver.PerformRequest("GET", path, nil)
+			AssertStatusCode(t, w, http.StatusBadRequest)
+			AssertProblemField(t, w, field)
+		}
+	})
+}
+
+func FarmLandAreaUnits(t *testing.T) {
+	driver.WipeCollections(t, "farms", "crops")
+	w := driver.PerformRequest("POST", "/farms", strings.NewReader(
+		`{"name": "Farm 1", "landArea": 50, "unitOfMeasurement": "acres", "address": "Rua 1"}`,
+	))
+	AssertStatusCode(t, w, http.StatusCreated)
+
+	var created FarmResponse
+	ParseResponse(t, w.Body.Bytes(), &created)
+	AssertEqual(t, fmt.Sprintf("%.4f", created.LandAreaHectares), "20.2343", "Farm land area in hectares")
+	AssertEqual(t, created.LandArea, 50.0, "Farm land area")
+	AssertEqual(t, created.UnitOfMeasurement, "acres", "Farm unit of measurement")
+
+	t.Run("Converts responses", func(t *testing.T) {
+		w := driver.PerformRequest("GET", "/farms/"+created.ID+"?unit=m2", nil)
+		AssertStatusCode(t, w, http.StatusOK)
+
+		var farm FarmResponse
+		ParseResponse(t, w.Body.Bytes(), &farm)
+		if farm.ConvertedLandArea == nil {
+			t.Fatalf("Expect converted land area, but got nil")
+		}
+		AssertEqual(t, farm.ConvertedLandArea.Value, 202342.82, "Converted land area")
+		AssertEqual(t, farm.ConvertedLandArea.Unit, "m2", "Converted unit")
+
+		w = driver.PerformRequest("GET", "/farms?unit=ha", nil)
+		AssertStatusCode(t, w, http.StatusOK)
+
+		var list FarmListResponse
+		ParseResponse(t, w.Body.Bytes(), &list)
+		AssertEqual(t, list.Items[0].ConvertedLandArea.Value, 20.23, "Converted land area")
+	})
+
+	t.Run("Keeps fractional land areas", func(t *testing.T) {
+		w := driver.PerformRequest("POST", "/farms", strings.NewReader(
+			`{"name": "Farm 3", "landArea": 12.5, "unitOfMeasurement": "ha", "address": "Rua 3"}`,
+		))
+		AssertStatusCode(t, w, http.StatusCreated)
+
+		var farm FarmResponse
+		ParseResponse(t, w.Body.Bytes(), &farm)
+		AssertEqual(t, farm.LandArea, 12.5, "Farm land area")
+		AssertEqual(t, farm.LandAreaHectares, 12.5, "Farm land area in hectares")
+
+		// Bounds are inclusive and compared exactly
+		w = driver.PerformRequest("GET", "/farms?landAreaMin=12.5&landAreaMax=12.5", nil)
+		var list FarmListResponse
+		ParseResponse(t, w.Body.Bytes(), &list)
+		AssertEqual(t, len(list.Items), 1, "Farms in range")
+	})
+
+	t.Run("Rejects unknown units", func(t *testing.T) {
+		w := driver.PerformRequest("POST", "/farms", strings.NewReader(
+			`{"name": "Farm 2", "landArea": 5, "unitOfMeasurement": "furlongs", "address": "Rua 2"}`,
+		))
+		AssertStatusCode(t, w, http.StatusBadRequest)
+		AssertProblemField(t, w, "unitOfMeasurement")
+	})
+}
+
+func FarmGeolocation(t *testing.T) {
+	driver.WipeCollections(t, "farms", "crops")
+
+	// About 100 hectares in Porto Alegre, the other farm lying in Caxias do Sul
+	boundary := `{"type": "Polygon", "coordinates": [[[-51.2300, -30.0300], [-51.2196, -30.0300], [-51.2196, -30.0210], [-51.2300, -30.0210], [-51.2300, -30.0300]]]}`
+	w := driver.PerformRequest("POST", "/farms", strings.NewReader(
+		`{"name": "Farm 1", "landArea": 100, "unitOfMeasurement": "ha", "address": "Rua 1",
+		  "location": {"type": "Point", "coordinates": [-51.225, -30.025]}, "boundary": `+boundary+`}`,
+	))
+	AssertStatusCode(t, w, http.StatusCreated)
+
+	var created FarmResponse
+	ParseResponse(t, w.Body.Bytes(), &created)
+
+	w = driver.PerformRequest("POST", "/farms", strings.NewReader(
+		`{"name": "Farm 2", "landArea": 50, "unitOfMeasurement": "ha", "address": "Rua 2",
+		  "location": {"type": "Point", "coordinates": [-51.1794, -29.1678]}}`,
+	))
+	AssertStatusCode(t, w, http.StatusCreated)
+
+	w = driver.PerformRequest("POST", "/farms", strings.NewReader(
+		`{"name": "Farm 3", "landArea": 10, "unitOfMeasurement": "ha", "address": "Rua 3"}`,
+	))
+	AssertStatusCode(t, w, http.StatusCreated)
+
+	t.Run("Stores the boundary area", func(t *testing.T) {
+		w := driver.PerformRequest("GET", "/farms/"+created.ID, nil)
+		AssertStatusCode(t, w, http.StatusOK)
+
+		var farm FarmResponse
+		ParseResponse(t, w.Body.Bytes(), &farm)
+		AssertEqual(t, farm.Location.Coordinates[0], -51.225, "Farm longitude")
+		if farm.BoundaryAreaHectares == nil || *farm.BoundaryAreaHectares < 95 || *farm.BoundaryAreaHectares > 105 {
+			t.Errorf("Expect a boundary of about 100 ha, but got %v", farm.BoundaryAreaHectares)
+		}
+	})
+
+	t.Run("Filters farms near a point", func(t *testing.T) {
+		var page FarmListResponse
+		w := driver.PerformRequest("GET", "/farms?near=-30.03,-51.23&radiusKm=10", nil)
+		AssertStatusCode(t, w, http.StatusOK)
+		ParseResponse(t, w.Body.Bytes(), &page)
+		AssertEqual(t, len(page.Items), 1, "Number of farms within 10 km")
+		AssertEqual(t, page.Items[0].ID, created.ID, "Farm within 10 km")
+
+		w = driver.PerformRequest("GET", "/farms?near=-30.03,-51.23&radiusKm=150", nil)
+		ParseResponse(t, w.Body.Bytes(), &page)
+		AssertEqual(t, len(page.Items), 2, "Number of farms within 150 km")
+	})
+
+	t.Run("Filters farms within a polygon", func(t *testing.T) {
+		var page FarmListResponse
+		w := driver.PerformRequest("GET", "/farms?within=-30.1,-51.3;-30.1,-51.1;-29.9,-51.1;-29.9,-51.3", nil)
+		AssertStatusCode(t, w, http.StatusOK)
+		ParseResponse(t, w.Body.Bytes(), &page)
+		AssertEqual(t, len(page.Items), 1, "Number of farms within the polygon")
+		AssertEqual(t, page.Items[0].ID, created.ID, "Farm within the polygon")
+	})
+
+	t.Run("Rejects a boundary far off the land area", func(t *testing.T) {
+		w := driver.PerformRequest("POST", "/farms", strings.NewReader(
+			`{"name": "Farm 4", "landArea": 29, "unitOfMeasurement": "ha", "address": "Rua 4", "boundary": `+boundary+`}`,
+		))
+		AssertStatusCode(t, w, http.StatusBadRequest)
+		AssertProblemField(t, w, "boundary")
+	})
+
+	t.Run("Rejects malformed geo filters", func(t *testing.T) {
+		for _, query := range []string{"near=-30.03", "near=-30.03,-51.23", "radiusKm=10", "within=1,2;3,4"} {
+			w := driver.PerformRequest("GET", "/farms?"+query, nil)
+			AssertStatusCode(t, w, http.StatusBadRequest)
+		}
+	})
+}
+
+func FarmAddress(t *testing.T) {
+	driver.WipeCollections(t, "farms", "crops")
+
+	w := driver.PerformRequest("POST", "/farms", strings.NewReader(
+		`{"name": "Farm 1", "landArea": 10, "unitOfMeasurement": "ha",
+		  "address": {"street": "Estrada Velha", "number": "s/n", "city": "Chapecó", "state": "SC", "country": "Brasil"}}`,
+	))
+	AssertStatusCode(t, w, http.StatusCreated)
+
+	var structured FarmResponse
+	ParseResponse(t, w.Body.Bytes(), &structured)
+
+	w = driver.PerformRequest("POST", "/farms", strings.NewReader(
+		`{"name": "Farm 2", "landArea": 10, "unitOfMeasurement": "ha", "address": "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil"}`,
+	))
+	AssertStatusCode(t, w, http.StatusCreated)
+
+	var line FarmResponse
+	ParseResponse(t, w.Body.Bytes(), &line)
+
+	t.Run("Writes structured addresses as a line", func(t *testing.T) {
+		w := driver.PerformRequest("GET", "/farms/"+structured.ID, nil)
+
+		var farm FarmResponse
+		ParseResponse(t, w.Body.Bytes(), &farm)
+		AssertEqual(t, farm.Address, "Estrada Velha, s/n, Chapecó - SC, Brasil", "Farm address")
+		AssertEqual(t, farm.StructuredAddress.City, "Chapecó", "Farm city")
+	})
+
+	t.Run("Parses address lines", func(t *testing.T) {
+		w := driver.PerformRequest("GET", "/farms/"+line.ID, nil)
+
+		var farm FarmResponse
+		ParseResponse(t, w.Body.Bytes(), &farm)
+		AssertEqual(t, farm.Address, "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil", "Farm address")
+		AssertEqual(t, farm.StructuredAddress.Street, "Rua 1", "Farm street")
+		AssertEqual(t, farm.StructuredAddress.Number, "123", "Farm number")
+		AssertEqual(t, farm.StructuredAddress.State, "RS", "Farm state")
+	})
+
+	t.Run("Filters farms by city and state", func(t *testing.T) {
+		var page FarmListResponse
+		w := driver.PerformRequest("GET", "/farms?state=sc", nil)
+		AssertStatusCode(t, w, http.StatusOK)
+		ParseResponse(t, w.Body.Bytes(), &page)
+		AssertEqual(t, len(page.Items), 1, "Number of farms in SC")
+		AssertEqual(t, page.Items[0].ID, structured.ID, "Farm in SC")
+
+		w = driver.PerformRequest("GET", "/farms?city=porto%20alegre&state=RS", nil)
+		ParseResponse(t, w.Body.Bytes(), &page)
+		AssertEqual(t, len(page.Items), 1, "Number of farms in Porto Alegre")
+		AssertEqual(t, page.Items[0].ID, line.ID, "Farm in Porto Alegre")
+	})
+
+	t.Run("Patches a part of the address", func(t *testing.T) {
+		w := driver.PerformRequestWithHeaders("PATCH", "/farms/"+structured.ID,
+			strings.NewReader(`{"address": {"number": "200"}}`),
+			map[string]string{"Content-Type": "application/merge-patch+json"},
+		)
+		AssertStatusCode(t, w, http.StatusOK)
+
+		var farm FarmResponse
+		ParseResponse(t, w.Body.Bytes(), &farm)
+		AssertEqual(t, farm.Address, "Estrada Velha, 200, Chapecó - SC, Brasil", "Farm address")
+	})
+
+	t.Run("Requires the city and state of structured addresses", func(t *testing.T) {
+		w := driver.PerformRequest("POST", "/farms", strings.NewReader(
+			`{"name": "Farm 3", "landArea": 10, "unitOfMeasurement": "ha", "address": {"street": "Rua 1", "state": "SC"}}`,
+		))
+		AssertStatusCode(t, w, http.StatusBadRequest)
+		AssertProblemField(t, w, "address.city")
+	})
+}
+
+type FarmSearchResponse struct {
+	Items []struct {
+		FarmResponse
+		Score      float64           `json:"score"`
+		Highlights map[string]string `json:"highlights"`
+	} `json:"items"`
+	NextCursor *string `json:"nextCursor"`
+}
+
+func SearchFarms(t *testing.T) {
+	driver.WipeCollections(t, "farms", "crops")
+	for _, body := range []string{
+		`{"name": "Fazenda Boa Vista", "landArea": 29, "unitOfMeasurement": "hectares", "address": "Estrada Velha, Porto Alegre - RS"}`,
+		`{"name": "Sítio Primavera", "landArea": 12, "unitOfMeasurement": "hectares", "address": "Rua Boa Vista, 10, Campinas - SP"}`,
+		`{"name": "Fazenda Santa Clara", "landArea": 80, "unitOfMeasurement": "hectares", "address": "Rodovia 1, Uberaba - MG"}`,
+	} {
+		w := driver.PerformRequest("POST", "/farms", strings.NewReader(body))
+		AssertStatusCode(t, w, http.StatusCreated)
+	}
+
+	t.Run("Ranks name matches first", func(t *testing.T) {
+		var response FarmSearchResponse
+		w := driver.PerformRequest("GET", "/farms/search?q=boa+vista", nil)
+		AssertStatusCode(t, w, http.StatusOK)
+		ParseResponse(t, w.Body.Bytes(), &response)
+
+		AssertEqual(t, len(response.Items), 2, "Number of farms")
+		AssertEqual(t, response.Items[0].Name, "Fazenda Boa Vista", "Best match")
+		AssertEqual(t, response.Items[0].Highlights["name"], "Fazenda <em>Boa</em> <em>Vista</em>", "Name highlight")
+		AssertEqual(t, response.Items[1].Highlights["address"], "Rua <em>Boa</em> <em>Vista</em>, 10, Campinas - SP", "Address highlight")
+		if response.Items[0].Score <= response.Items[1].Score {
+			t.Errorf("Expect scores to decrease, but got %v", response.Items)
+		}
+	})
+
+	t.Run("Paginates results", func(t *testing.T) {
+		var response FarmSearchResponse
+		w := driver.PerformRequest("GET", "/farms/search?q=fazenda&limit=1", nil)
+		ParseResponse(t, w.Body.Bytes(), &response)
+		AssertEqual(t, len(response.Items), 1, "Number of farms")
+		if response.NextCursor == nil {
+			t.Fatalf("Expect next cursor, but got nil")
+		}
+		first, next := response.Items[0].ID, *response.NextCursor
+
+		response = FarmSearchResponse{}
+		w = driver.PerformRequest("GET", "/farms/search?q=fazenda&limit=1&after="+next, nil)
+		ParseResponse(t, w.Body.Bytes(), &response)
+		AssertEqual(t, len(response.Items), 1, "Number of farms")
+		if response.Items[0].ID == first {
+			t.Errorf("Expect the next page to hold another farm")
+		}
+	})
+
+	t.Run("Requires a query", func(t *testing.T) {
+		w := driver.PerformRequest("GET", "/farms/search?q=+", nil)
+		AssertStatusCode(t, w, http.StatusBadRequest)
+		AssertProblemField(t, w, "q")
 	})
 }
 
@@ -267,7 +725,7 @@ func FarmGet(t *testing.T) {
 
 	AssertEqual(t, farmResponse.Name, "Farm 1", "Farm name")
 	AssertEqual(t, farmResponse.Address, "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil", "Farm address")
-	AssertEqual(t, farmResponse.LandArea, 87, "Farm land area")
+	AssertEqual(t, farmResponse.LandArea, 87.0, "Farm land area")
 	AssertEqual(t, farmResponse.UnitOfMeasurement, "hectares", "Farm unit of measurement")
 	AssertEqual(t, len(farmResponse.Crops), 0, "Farm crops length")
 }
@@ -286,23 +744,198 @@ func FarmUpdate(t *testing.T) {
 	AssertStatusCode(t, w, http.StatusCreated)
 	ParseResponse(t, w.Body.Bytes(), &farmResponse)
 
-	body = strings.NewReader(`{ "name": "Farm 1 Updated" }`)
+	body = strings.NewReader(`{
+    "name": "Farm 1 Updated",
+    "landArea": 90,
+    "unitOfMeasurement": "hectares",
+    "address": "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil"
+  }`)
 
 	w = driver.PerformRequest("PUT", fmt.Sprintf("/farms/%v", farmResponse.ID), body)
 
 	AssertStatusCode(t, w, http.StatusOK)
+	ParseResponse(t, w.Body.Bytes(), &farmResponse)
+	AssertEqual(t, farmResponse.Name, "Farm 1 Updated", "Updated farm name")
 
 	w = driver.PerformRequest("GET", fmt.Sprintf("/farms/%v", farmResponse.ID), nil)
 	ParseResponse(t, w.Body.Bytes(), &farmResponse)
 	AssertEqual(t, farmResponse.Name, "Farm 1 Updated", "Farm name")
+	AssertEqual(t, farmResponse.LandArea, 90.0, "Farm land area")
+
+	if farmResponse.CreatedAt.IsZero() {
+		t.Errorf("Expect createdAt, but got zero")
+	}
+	if farmResponse.UpdatedAt.Before(farmResponse.CreatedAt) {
+		t.Errorf("Expect updatedAt %v not to be before createdAt %v", farmResponse.UpdatedAt, farmResponse.CreatedAt)
+	}
+
+	// Replacement requires every field
+	body = strings.NewReader(`{ "name": "Farm 1 Updated" }`)
+	w = driver.PerformRequest("PUT", fmt.Sprintf("/farms/%v", farmResponse.ID), body)
+	AssertStatusCode(t, w, http.StatusBadRequest)
+	AssertProblemField(t, w, "landArea")
+	AssertProblemField(t, w, "address")
+
+	body = strings.NewReader(`{
+    "name": "Farm 1 Updated",
+    "landArea": -10,
+    "unitOfMeasurement": "hectares",
+    "address": "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil"
+  }`)
+	w = driver.PerformRequest("PUT", fmt.Sprintf("/farms/%v", farmResponse.ID), body)
+	AssertStatusCode(t, w, http.StatusBadRequest)
+	AssertProblemField(t, w, "landArea")
+
+	body = strings.NewReader(`{
+    "name": "Farm 1 Updated",
+    "landArea": 90,
+    "unitOfMeasurement": "hectares",
+    "address": "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil"
+  }`)
+	w = driver.PerformRequest("PUT", "/farms/000000000000000000000000", body)
+	AssertStatusCode(t, w, http.StatusNotFound)
+	AssertProblemCode(t, w, "FARM_NOT_FOUND")
+}
+
+func FarmPatch(t *testing.T) {
+	var farmResponse FarmResponse
+	body := strings.NewReader(`{
+    "name": "Farm 1",
+    "landArea": 87,
+    "unitOfMeasurement": "hectares",
+    "address": "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil",
+    "crops": []
+  }`)
+
+	w := driver.PerformRequest("POST", "/farms", body)
+	AssertStatusCode(t, w, http.StatusCreated)
+	ParseResponse(t, w.Body.Bytes(), &farmResponse)
+
+	path := fmt.Sprintf("/farms/%v", farmResponse.ID)
+	mergePatch := map[string]string{"Content-Type": "application/merge-patch+json"}
+
+	body = strings.NewReader(`{ "name": "Farm 1 Patched" }`)
+	w = driver.PerformRequestWithHeaders("PATCH", path, body, mergePatch)
+	AssertStatusCode(t, w, http.StatusOK)
+	ParseResponse(t, w.Body.Bytes(), &farmResponse)
+	AssertEqual(t, farmResponse.Name, "Farm 1 Patched", "Patched farm name")
 
 	// Keep untouched fields
 	AssertEqual(t, farmResponse.Address, "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil", "Farm address")
-	AssertEqual(t, farmResponse.LandArea, 87, "Farm land area")
+	AssertEqual(t, farmResponse.LandArea, 87.0, "Farm land area")
 	AssertEqual(t, farmResponse.UnitOfMeasurement, "hectares", "Farm unit of measurement")
+
+	// Null removes the field, which is required
+	body = strings.NewReader(`{ "address": null }`)
+	w = driver.PerformRequestWithHeaders("PATCH", path, body, mergePatch)
+	AssertStatusCode(t, w, http.StatusBadRequest)
+	AssertProblemField(t, w, "address")
+
+	body = strings.NewReader(`{ "landArea": 0 }`)
+	w = driver.PerformRequestWithHeaders("PATCH", path, body, mergePatch)
+	AssertStatusCode(t, w, http.StatusBadRequest)
+	AssertProblemField(t, w, "landArea")
+
+	body = strings.NewReader(`{ "unknown": true }`)
+	w = driver.PerformRequestWithHeaders("PATCH", path, body, mergePatch)
+	AssertStatusCode(t, w, http.StatusBadRequest)
+	AssertProblemCode(t, w, "INVALID_FARM_FIELDS")
+
+	body = strings.NewReader(`{ "name": `)
+	w = driver.PerformRequestWithHeaders("PATCH", path, body, mergePatch)
+	AssertStatusCode(t, w, http.StatusBadRequest)
+	AssertProblemCode(t, w, "INVALID_PATCH")
+
+	body = strings.NewReader(`name=Farm`)
+	w = driver.PerformRequestWithHeaders("PATCH", path, body, map[string]string{"Content-Type": "text/plain"})
+	AssertStatusCode(t, w, http.StatusUnsupportedMediaType)
+
+	body = strings.NewReader(`{ "name": "Farm 1 Patched" }`)
+	w = driver.PerformRequestWithHeaders("PATCH", "/farms/000000000000000000000000", body, mergePatch)
+	AssertStatusCode(t, w, http.StatusNotFound)
+	AssertProblemCode(t, w, "FARM_NOT_FOUND")
+}
+
+func FarmConcurrencyControl(t *testing.T) {
+	var farmResponse FarmResponse
+	body := strings.NewReader(`{
+    "name": "Farm 1",
+    "landArea": 87,
+    "unitOfMeasurement": "hectares",
+    "address": "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil"
+  }`)
+
+	w := driver.PerformRequest("POST", "/farms", body)
+	AssertStatusCode(t, w, http.StatusCreated)
+	ParseResponse(t, w.Body.Bytes(), &farmResponse)
+	path := fmt.Sprintf("/farms/%v", farmResponse.ID)
+
+	w = driver.PerformRequest("GET", path, nil)
+	AssertStatusCode(t, w, http.StatusOK)
+	etag := w.Header().Get("ETag")
+	AssertEqual(t, etag, `"1"`, "Farm ETag")
+
+	w = driver.PerformRequestWithHeaders("GET", path, nil, map[string]string{"If-None-Match": etag})
+	AssertStatusCode(t, w, http.StatusNotModified)
+
+	replacement := `{
+    "name": "Farm 1 Updated",
+    "landArea": 90,
+    "unitOfMeasurement": "hectares",
+    "address": "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil"
+  }`
+
+	w = driver.PerformRequestWithHeaders("PUT", path, strings.NewReader(replacement), map[string]string{"If-Match": etag})
+	AssertStatusCode(t, w, http.StatusOK)
+	AssertEqual(t, w.Header().Get("ETag"), `"2"`, "Updated farm ETag")
+
+	// The first version is stale now
+	w = driver.PerformRequestWithHeaders("PUT", path, strings.NewReader(replacement), map[string]string{"If-Match": etag})
+	AssertStatusCode(t, w, http.StatusPreconditionFailed)
+	AssertProblemCode(t, w, "PRECONDITION_FAILED")
+
+	w = driver.PerformRequestWithHeaders("PATCH", path, strings.NewReader(`{ "name": "Farm 1 Patched" }`), map[string]string{
+		"Content-Type": "application/merge-patch+json",
+		"If-Match":     etag,
+	})
+	AssertStatusCode(t, w, http.StatusPreconditionFailed)
+
+	w = driver.PerformRequestWithHeaders("GET", path, nil, map[string]string{"If-None-Match": etag})
+	AssertStatusCode(t, w, http.StatusOK)
+	ParseResponse(t, w.Body.Bytes(), &farmResponse)
+	AssertEqual(t, farmResponse.Name, "Farm 1 Updated", "Farm name")
+
+	w = driver.PerformRequestWithHeaders("DELETE", path, nil, map[string]string{"If-Match": etag})
+	AssertStatusCode(t, w, http.StatusPreconditionFailed)
+
+	w = driver.PerformRequestWithHeaders("DELETE", path, nil, map[string]string{"If-Match": `"2"`})
+	AssertStatusCode(t, w, http.StatusOK)
+
+	w = driver.PerformRequest("GET", "/farms", nil)
+	AssertStatusCode(t, w, http.StatusOK)
+	etag = w.Header().Get("ETag")
+	if etag == "" {
+		t.Errorf("Expect listing ETag, but got empty")
+	}
+
+	w = driver.PerformRequestWithHeaders("GET", "/farms", nil, map[string]string{"If-None-Match": etag})
+	AssertStatusCode(t, w, http.StatusNotModified)
+}
+
+type DeleteFarmResponse struct {
+	ID      string           `json:"id"`
+	Soft    bool             `json:"soft"`
+	Deleted map[string]int64 `json:"deleted"`
 }
 
 func FarmDelete(t *testing.T) {
+	t.Run("Delete farm", FarmHardDelete)
+	t.Run("Delete farm cascades to crops", FarmDeleteCascade)
+	t.Run("Soft delete farm", FarmSoftDelete)
+	t.Run("Restore farm", FarmRestore)
+}
+
+func FarmHardDelete(t *testing.T) {
 	var farmResponse FarmResponse
 	body := strings.NewReader(`{
     "name": "Farm 1",
@@ -317,8 +950,145 @@ func FarmDelete(t *testing.T) {
 	ParseResponse(t, w.Body.Bytes(), &farmResponse)
 
 	w = driver.PerformRequest("DELETE", fmt.Sprintf("/farms/%v", farmResponse.ID), nil)
-	AssertStatusCode(t, w, http.StatusNoContent)
+	AssertStatusCode(t, w, http.StatusOK)
+
+	w = driver.PerformRequest("GET", fmt.Sprintf("/farms/%v", farmResponse.ID), nil)
+	AssertStatusCode(t, w, http.StatusNotFound)
+	AssertProblemCode(t, w, "FARM_NOT_FOUND")
+
+	w = driver.PerformRequest("POST", fmt.Sprintf("/farms/%v/restore", farmResponse.ID), nil)
+	AssertStatusCode(t, w, http.StatusNotFound)
+
+	w = driver.PerformRequest("DELETE", fmt.Sprintf("/farms/%v", farmResponse.ID), nil)
+	AssertStatusCode(t, w, http.StatusNotFound)
+	AssertProblemCode(t, w, "FARM_NOT_FOUND")
+}
+
+func FarmDeleteCascade(t *testing.T) {
+	var farmResponse FarmResponse
+	var deleteResponse DeleteFarmResponse
+	body := strings.NewReader(`{
+    "name": "Farm 1",
+    "landArea": 87,
+    "unitOfMeasurement": "hectares",
+    "address": "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil",
+    "crops": [ { "type": "CORN" }, { "type": "RICE" } ]
+  }`)
+
+	w := driver.PerformRequest("POST", "/farms", body)
+	AssertStatusCode(t, w, http.StatusCreated)
+	ParseResponse(t, w.Body.Bytes(), &farmResponse)
+
+	w = driver.PerformRequest("DELETE", fmt.Sprintf("/farms/%v", farmResponse.ID), nil)
+	AssertStatusCode(t, w, http.StatusOK)
+	ParseResponse(t, w.Body.Bytes(), &deleteResponse)
+
+	AssertEqual(t, deleteResponse.Soft, false, "Soft delete")
+	AssertEqual(t, deleteResponse.Deleted["crops"], int64(2), "Deleted crops")
+
+	w = driver.PerformRequest("GET", fmt.Sprintf("/farms/%v/crops", farmResponse.ID), nil)
+	AssertStatusCode(t, w, http.StatusNotFound)
+}
+
+func FarmSoftDelete(t *testing.T) {
+	var farmResponse FarmResponse
+	var deleteResponse DeleteFarmResponse
+	body := strings.NewReader(`{
+    "name": "Farm 1",
+    "landArea": 87,
+    "unitOfMeasurement": "hectares",
+    "address": "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil",
+    "crops": [ { "type": "COFFEE" } ]
+  }`)
+
+	w := driver.PerformRequest("POST", "/farms", body)
+	AssertStatusCode(t, w, http.StatusCreated)
+	ParseResponse(t, w.Body.Bytes(), &farmResponse)
+
+	w = driver.PerformRequest("DELETE", fmt.Sprintf("/farms/%v?soft=true", farmResponse.ID), nil)
+	AssertStatusCode(t, w, http.StatusOK)
+	ParseResponse(t, w.Body.Bytes(), &deleteResponse)
+
+	AssertEqual(t, deleteResponse.Soft, true, "Soft delete")
+	AssertEqual(t, deleteResponse.Deleted["crops"], int64(1), "Deleted crops")
 
 	w = driver.PerformRequest("GET", fmt.Sprintf("/farms/%v", farmResponse.ID), nil)
 	AssertStatusCode(t, w, http.StatusNotFound)
+
+	w = driver.PerformRequest("DELETE", fmt.Sprintf("/farms/%v", farmResponse.ID), nil)
+	AssertStatusCode(t, w, http.StatusNotFound)
+
+	var trashResponse FarmListResponse
+	w = driver.PerformRequest("GET", "/farms/trash", nil)
+	AssertStatusCode(t, w, http.StatusOK)
+	ParseResponse(t, w.Body.Bytes(), &trashResponse)
+
+	found := false
+	for _, farm := range trashResponse.Items {
+		if farm.ID == farmResponse.ID {
+			found = true
+		}
+	}
+	AssertEqual(t, found, true, "Farm in trash")
+}
+
+func FarmRestore(t *testing.T) {
+	var farmResponse FarmResponse
+	var restoreResponse struct {
+		ID       string           `json:"id"`
+		Restored map[string]int64 `json:"restored"`
+	}
+	body := strings.NewReader(`{
+    "name": "Farm 1",
+    "landArea": 87,
+    "unitOfMeasurement": "hectares",
+    "address": "Rua 1, 123, Bairro 2, Porto Alegre - RS, Brasil",
+    "crops": [ { "type": "CORN" }, { "type": "BEANS" } ]
+  }`)
+
+	w := driver.PerformRequest("POST", "/farms", body)
+	AssertStatusCode(t, w, http.StatusCreated)
+	ParseResponse(t, w.Body.Bytes(), &farmResponse)
+
+	w = driver.PerformRequest("DELETE", fmt.Sprintf("/farms/%v?soft=true", farmResponse.ID), nil)
+	AssertStatusCode(t, w, http.StatusOK)
+
+	w = driver.PerformRequest("POST", fmt.Sprintf("/farms/%v/restore", farmResponse.ID), nil)
+	AssertStatusCode(t, w, http.StatusOK)
+	ParseResponse(t, w.Body.Bytes(), &restoreResponse)
+	AssertEqual(t, restoreResponse.Restored["crops"], int64(2), "Restored crops")
+
+	w = driver.PerformRequest("GET", fmt.Sprintf("/farms/%v", farmResponse.ID), nil)
+	AssertStatusCode(t, w, http.StatusOK)
+
+	var cropsResponse []CropResponse
+	w = driver.PerformRequest("GET", fmt.Sprintf("/farms/%v/crops", farmResponse.ID), nil)
+	AssertStatusCode(t, w, http.StatusOK)
+	ParseResponse(t, w.Body.Bytes(), &cropsResponse)
+	AssertEqual(t, len(cropsResponse), 2, "Number of crops")
+
+	w = driver.PerformRequest("POST", fmt.Sprintf("/farms/%v/restore", farmResponse.ID), nil)
+	AssertStatusCode(t, w, http.StatusNotFound)
+}
+
+func FarmMalformedID(t *testing.T) {
+	requests := []struct {
+		method string
+		path   string
+	}{
+		{"GET", "/farms/not-an-id"},
+		{"PUT", "/farms/not-an-id"},
+		{"PATCH", "/farms/not-an-id"},
+		{"DELETE", "/farms/not-an-id"},
+		{"POST", "/farms/not-an-id/restore"},
+	}
+
+	for _, req := range requests {
+		t.Run(req.method+" "+req.path, func(t *testing.T) {
+			w := driver.PerformRequest(req.method, req.path, strings.NewReader(`{ "name": "Farm 1" }`))
+			AssertStatusCode(t, w, http.StatusBadRequest)
+			AssertProblemCode(t, w, "INVALID_ID")
+			AssertProblemField(t, w, "id")
+		})
+	}
 }
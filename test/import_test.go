@@ -0,0 +1,124 @@
+package test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type ImportReportResponse struct {
+	DryRun  bool `json:"dryRun"`
+	Rows    int  `json:"rows"`
+	Created int  `json:"created"`
+	Valid   int  `json:"valid"`
+	Invalid int  `json:"invalid"`
+	Failed  int  `json:"failed"`
+	Results []struct {
+		Line     int    `json:"line"`
+		Status   string `json:"status"`
+		ID       string `json:"id"`
+		Error    string `json:"error"`
+		Problems []struct {
+			Field string `json:"field"`
+		} `json:"problems"`
+	} `json:"results"`
+}
+
+func TestFarmImport(t *testing.T) {
+	t.Run("Import CSV", FarmImportCSV)
+	t.Run("Import NDJSON", FarmImportNDJSON)
+	t.Run("Dry Run", FarmImportDryRun)
+	t.Run("Unindexable Geometry", FarmImportUnindexableGeometry)
+	t.Run("Unsupported Media Type", FarmImportMediaType)
+}
+
+func importFarms(t *testing.T, path string, contentType string, body string) ImportReportResponse {
+	w := driver.PerformRequestWithHeaders("POST", path, strings.NewReader(body), map[string]string{"Content-Type": contentType})
+	AssertStatusCode(t, w, http.StatusOK)
+
+	var report ImportReportResponse
+	ParseResponse(t, w.Body.Bytes(), &report)
+	return report
+}
+
+func FarmImportCSV(t *testing.T) {
+	driver.WipeCollections(t, "farms", "crops")
+	report := importFarms(t, "/farms/import", "text/csv; charset=utf-8",
+		"name,address,landArea,unitOfMeasurement,crops\n"+
+			"Farm 1,\"Rua 1, 123, Porto Alegre - RS\",12.5,ha,CORN;SOYBEANS\n"+
+			"Farm 2,Rua 2,-3,ha,\n"+
+			"Farm 3,Rua 3,7,acres,\n",
+	)
+
+	AssertEqual(t, report.Rows, 3, "Imported rows")
+	AssertEqual(t, report.Created, 2, "Created farms")
+	AssertEqual(t, report.Invalid, 1, "Invalid rows")
+	AssertEqual(t, report.Results[1].Line, 3, "Line of the invalid row")
+	AssertEqual(t, report.Results[1].Problems[0].Field, "landArea", "Invalid field")
+
+	w := driver.PerformRequest("GET", "/farms/"+report.Results[0].ID, nil)
+	AssertStatusCode(t, w, http.StatusOK)
+
+	var farm FarmResponse
+	ParseResponse(t, w.Body.Bytes(), &farm)
+	AssertEqual(t, farm.Name, "Farm 1", "Imported farm name")
+	AssertEqual(t, farm.StructuredAddress.City, "Porto Alegre", "Imported farm city")
+	AssertEqual(t, len(farm.Crops), 2, "Imported farm crops")
+}
+
+func FarmImportNDJSON(t *testing.T) {
+	driver.WipeCollections(t, "farms", "crops")
+	report := importFarms(t, "/farms/import", "application/x-ndjson",
+		`{"name": "Farm 1", "landArea": 10, "unitOfMeasurement": "ha", "address": "Rua 1", "crops": [{"type": "COFFEE", "isIrrigated": true}]}`+"\n"+
+			`{"name": "Farm 2", "landArea": 10, "unitOfMeasurement": "ha", "address": "Rua 2", "crops": [{"type": "CORN", "plantedArea": 20}]}`+"\n"+
+			`not json`+"\n",
+	)
+
+	AssertEqual(t, report.Created, 1, "Created farms")
+	AssertEqual(t, report.Invalid, 2, "Invalid rows")
+	AssertEqual(t, report.Results[1].Status, "invalid", "Over allocated farm status")
+	AssertEqual(t, report.Results[2].Line, 3, "Line of the malformed row")
+	AssertEqual(t, report.Results[2].Error, "malformed row", "Error of the malformed row")
+
+	var page FarmListResponse
+	w := driver.PerformRequest("GET", "/farms", nil)
+	ParseResponse(t, w.Body.Bytes(), &page)
+	AssertEqual(t, len(page.Items), 1, "Number of farms")
+}
+
+func FarmImportDryRun(t *testing.T) {
+	driver.WipeCollections(t, "farms", "crops")
+	report := importFarms(t, "/farms/import?dryRun=true", "application/x-ndjson",
+		`{"name": "Farm 1", "landArea": 10, "unitOfMeasurement": "ha", "address": "Rua 1"}`+"\n",
+	)
+
+	AssertEqual(t, report.DryRun, true, "Dry run")
+	AssertEqual(t, report.Valid, 1, "Valid rows")
+	AssertEqual(t, report.Results[0].Status, "valid", "Row status")
+
+	var page FarmListResponse
+	w := driver.PerformRequest("GET", "/farms", nil)
+	ParseResponse(t, w.Body.Bytes(), &page)
+	AssertEqual(t, len(page.Items), 0, "Number of farms")
+}
+
+// The boundary goes back over one of its vertices, which passes validation
+// but not the 2dsphere index
+func FarmImportUnindexableGeometry(t *testing.T) {
+	driver.WipeCollections(t, "farms", "crops")
+	report := importFarms(t, "/farms/import", "application/x-ndjson",
+		`{"name": "Farm 1", "landArea": 53.66, "unitOfMeasurement": "ha", "address": "Rua 1", "boundary": {"type": "Polygon", "coordinates": [[[-51.0, -30.0], [-50.99, -30.0], [-50.99, -29.99], [-50.99, -30.0], [-51.0, -29.99], [-51.0, -30.0]]]}}`+"\n",
+	)
+
+	AssertEqual(t, report.Failed, 1, "Failed rows")
+	AssertEqual(t, report.Results[0].Status, "failed", "Row status")
+	AssertEqual(t, strings.Contains(report.Results[0].Error, "can not be indexed"), true, "Row error names the geometry")
+}
+
+func FarmImportMediaType(t *testing.T) {
+	w := driver.PerformRequestWithHeaders("POST", "/farms/import", strings.NewReader(`[]`), map[string]string{"Content-Type": "application/json"})
+	AssertStatusCode(t, w, http.StatusUnsupportedMediaType)
+
+	w = driver.PerformRequestWithHeaders("POST", "/farms/import", strings.NewReader("name,size\n"), map[string]string{"Content-Type": "text/csv"})
+	AssertStatusCode(t, w, http.StatusBadRequest)
+}
@@ -0,0 +1,17 @@
+package test
+
+import (
+	"os"
+	"testing"
+)
+
+var (
+	driver *Driver = NewDriver()
+)
+
+func TestMain(m *testing.M) {
+	driver.Start()
+	code := m.Run()
+	driver.Close()
+	os.Exit(code)
+}
@@ -0,0 +1,104 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type StatsResponse struct {
+	Unit                string  `json:"unit"`
+	TotalFarms          int     `json:"totalFarms"`
+	TotalLandArea       float64 `json:"totalLandArea"`
+	AverageLandArea     float64 `json:"averageLandArea"`
+	TotalCrops          int     `json:"totalCrops"`
+	IrrigatedPercentage float64 `json:"irrigatedPercentage"`
+	InsuredPercentage   float64 `json:"insuredPercentage"`
+	CropTypes           []struct {
+		Type       string  `json:"type"`
+		Crops      int     `json:"crops"`
+		Farms      int     `json:"farms"`
+		Percentage float64 `json:"percentage"`
+	} `json:"cropTypes"`
+	Units []struct {
+		Unit              string  `json:"unit"`
+		Farms             int     `json:"farms"`
+		LandArea          float64 `json:"landArea"`
+		ConvertedLandArea float64 `json:"convertedLandArea"`
+		Percentage        float64 `json:"percentage"`
+	} `json:"units"`
+}
+
+func TestStats(t *testing.T) {
+	driver.WipeCollections(t, "farms", "crops")
+	for _, body := range []string{
+		`{"name": "Farm 1", "landArea": 100, "unitOfMeasurement": "ha", "address": "Rua 1",
+		  "crops": [{"type": "CORN", "isIrrigated": true, "isInsured": true}, {"type": "SOYBEANS", "isIrrigated": false, "isInsured": true}]}`,
+		`{"name": "Farm 2", "landArea": 50, "unitOfMeasurement": "hectares", "address": "Rua 2",
+		  "crops": [{"type": "CORN", "isIrrigated": false, "isInsured": false}]}`,
+		`{"name": "Farm 3", "landArea": 100, "unitOfMeasurement": "acres", "address": "Rua 3",
+		  "crops": [{"type": "COFFEE", "isIrrigated": true, "isInsured": false}]}`,
+	} {
+		w := driver.PerformRequest("POST", "/farms", strings.NewReader(body))
+		AssertStatusCode(t, w, http.StatusCreated)
+	}
+
+	t.Run("Aggregates farms and crops", func(t *testing.T) {
+		w := driver.PerformRequest("GET", "/stats", nil)
+		AssertStatusCode(t, w, http.StatusOK)
+
+		var stats StatsResponse
+		ParseResponse(t, w.Body.Bytes(), &stats)
+		AssertEqual(t, stats.Unit, "ha", "Stats unit")
+		AssertEqual(t, stats.TotalFarms, 3, "Total farms")
+		AssertEqual(t, fmt.Sprintf("%.2f", stats.TotalLandArea), "190.47", "Total land area")
+		AssertEqual(t, fmt.Sprintf("%.2f", stats.AverageLandArea), "63.49", "Average land area")
+		AssertEqual(t, stats.TotalCrops, 4, "Total crops")
+		AssertEqual(t, stats.IrrigatedPercentage, 50.0, "Irrigated percentage")
+		AssertEqual(t, stats.InsuredPercentage, 50.0, "Insured percentage")
+
+		AssertEqual(t, len(stats.CropTypes), 3, "Number of crop types")
+		AssertEqual(t, stats.CropTypes[0].Type, "CORN", "Most planted crop type")
+		AssertEqual(t, stats.CropTypes[0].Crops, 2, "Corn crops")
+		AssertEqual(t, stats.CropTypes[0].Farms, 2, "Farms growing corn")
+		AssertEqual(t, stats.CropTypes[0].Percentage, 50.0, "Corn percentage")
+	})
+
+	t.Run("Merges unit names", func(t *testing.T) {
+		w := driver.PerformRequest("GET", "/stats", nil)
+
+		var stats StatsResponse
+		ParseResponse(t, w.Body.Bytes(), &stats)
+		AssertEqual(t, len(stats.Units), 2, "Number of units")
+		AssertEqual(t, stats.Units[0].Unit, "ha", "Most used unit")
+		AssertEqual(t, stats.Units[0].Farms, 2, "Farms in hectares")
+		AssertEqual(t, stats.Units[0].LandArea, 150.0, "Land area in hectares")
+		AssertEqual(t, stats.Units[1].Unit, "ac", "Acres unit")
+		AssertEqual(t, stats.Units[1].LandArea, 100.0, "Land area in acres")
+	})
+
+	t.Run("Converts to the requested unit", func(t *testing.T) {
+		w := driver.PerformRequest("GET", "/stats?unit=acres", nil)
+		AssertStatusCode(t, w, http.StatusOK)
+
+		var stats StatsResponse
+		ParseResponse(t, w.Body.Bytes(), &stats)
+		AssertEqual(t, stats.Unit, "ac", "Stats unit")
+		AssertEqual(t, fmt.Sprintf("%.2f", stats.TotalLandArea), "470.66", "Total land area in acres")
+	})
+
+	t.Run("Applies the listing filters", func(t *testing.T) {
+		w := driver.PerformRequest("GET", "/stats?cropType=COFFEE", nil)
+		AssertStatusCode(t, w, http.StatusOK)
+
+		var stats StatsResponse
+		ParseResponse(t, w.Body.Bytes(), &stats)
+		AssertEqual(t, stats.TotalFarms, 1, "Total farms")
+	})
+
+	t.Run("Rejects an unknown unit", func(t *testing.T) {
+		w := driver.PerformRequest("GET", "/stats?unit=furlongs", nil)
+		AssertStatusCode(t, w, http.StatusBadRequest)
+	})
+}
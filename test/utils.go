@@ -6,6 +6,19 @@ import (
 	"testing"
 )
 
+type ProblemResponse struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail"`
+	Code   string `json:"code"`
+	Errors []struct {
+		Field   string `json:"field"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
 func AssertStatusCode(t *testing.T, response *httptest.ResponseRecorder, expect int) {
 	if response.Code != expect {
 		t.Errorf("Expect status code %d, but got %d", expect, response.Code)
@@ -23,3 +36,29 @@ func ParseResponse(t *testing.T, data []byte, v interface{}) {
 		t.Fatalf("Failed to unmarshal response body: %v", err)
 	}
 }
+
+// Asserts the response is a problem+json body reporting the given field
+func AssertProblemField(t *testing.T, response *httptest.ResponseRecorder, field string) {
+	if ct := response.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("Expect problem content type, but got %s", ct)
+	}
+
+	var problem ProblemResponse
+	ParseResponse(t, response.Body.Bytes(), &problem)
+	for _, e := range problem.Errors {
+		if e.Field == field {
+			return
+		}
+	}
+
+	t.Errorf("Expect problem on field %s, but got %+v", field, problem.Errors)
+}
+
+// Asserts the response is a problem+json body with the given error code
+func AssertProblemCode(t *testing.T, response *httptest.ResponseRecorder, code string) {
+	var problem ProblemResponse
+	ParseResponse(t, response.Body.Bytes(), &problem)
+	if problem.Code != code {
+		t.Errorf("Expect problem code %s, but got %s", code, problem.Code)
+	}
+}
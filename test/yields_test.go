@@ -0,0 +1,143 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type YieldResponse struct {
+	ID          string  `json:"id"`
+	CropID      string  `json:"cropId"`
+	Quantity    float64 `json:"quantity"`
+	Unit        string  `json:"unit"`
+	Kilograms   float64 `json:"kilograms"`
+	HarvestDate string  `json:"harvestDate"`
+}
+
+type YieldSummaryResponse struct {
+	Unit            string   `json:"unit"`
+	Yields          int      `json:"yields"`
+	Quantity        float64  `json:"quantity"`
+	AreaHectares    float64  `json:"areaHectares"`
+	AreaBasis       string   `json:"areaBasis"`
+	YieldPerHectare *float64 `json:"yieldPerHectare"`
+	AverageMoisture *float64 `json:"averageMoisture"`
+}
+
+func TestYields(t *testing.T) {
+	t.Run("Record Yields", YieldRecord)
+	t.Run("Yield Summary", YieldSummary)
+	t.Run("Delete Yields With Crop", YieldDeleteWithCrop)
+}
+
+// Creates a crop on a new farm and takes it through harvest
+func createHarvestedCrop(t *testing.T, body string) (string, string) {
+	farmId := createFarmForCrops(t)
+	cropId := createCrop(t, farmId, body)
+
+	for _, transition := range []string{
+		`{ "status": "planted", "date": "2025-10-01" }`,
+		`{ "status": "growing", "date": "2025-10-20" }`,
+		`{ "status": "harvested", "date": "2026-03-10" }`,
+	} {
+		AssertStatusCode(t, transitionCrop(farmId, cropId, transition), http.StatusOK)
+	}
+
+	return farmId, cropId
+}
+
+func createYield(t *testing.T, path string, body string) string {
+	var response YieldResponse
+	w := driver.PerformRequest("POST", path, strings.NewReader(body))
+	AssertStatusCode(t, w, http.StatusCreated)
+	ParseResponse(t, w.Body.Bytes(), &response)
+
+	return response.ID
+}
+
+func YieldRecord(t *testing.T) {
+	farmId, cropId := createHarvestedCrop(t, `{ "type": "SOYBEANS", "plantedArea": 40 }`)
+	path := fmt.Sprintf("/farms/%v/crops/%v/yields", farmId, cropId)
+
+	t.Run("Records a yield on the harvest date of the crop", func(t *testing.T) {
+		id := createYield(t, path, `{ "quantity": 1200, "unit": "sacas", "moisture": 13.5 }`)
+
+		var yield YieldResponse
+		w := driver.PerformRequest("GET", path+"/"+id, nil)
+		AssertStatusCode(t, w, http.StatusOK)
+		ParseResponse(t, w.Body.Bytes(), &yield)
+		AssertEqual(t, yield.HarvestDate, "2026-03-10", "Yield harvest date")
+		AssertEqual(t, yield.Kilograms, 72000.0, "Yield kilograms")
+	})
+
+	t.Run("Replaces a yield", func(t *testing.T) {
+		id := createYield(t, path, `{ "quantity": 10, "unit": "t", "harvestDate": "2026-03-11" }`)
+		w := driver.PerformRequest("PUT", path+"/"+id, strings.NewReader(`{ "quantity": 12, "unit": "t", "harvestDate": "2026-03-11" }`))
+		AssertStatusCode(t, w, http.StatusOK)
+
+		w = driver.PerformRequest("DELETE", path+"/"+id, nil)
+		AssertStatusCode(t, w, http.StatusNoContent)
+		w = driver.PerformRequest("GET", path+"/"+id, nil)
+		AssertStatusCode(t, w, http.StatusNotFound)
+		AssertProblemCode(t, w, "YIELD_NOT_FOUND")
+	})
+
+	t.Run("Rejects invalid yields", func(t *testing.T) {
+		w := driver.PerformRequest("POST", path, strings.NewReader(`{ "quantity": 0, "unit": "bushels" }`))
+		AssertStatusCode(t, w, http.StatusBadRequest)
+		AssertProblemField(t, w, "quantity")
+		AssertProblemField(t, w, "unit")
+
+		w = driver.PerformRequest("POST", path, strings.NewReader(`{ "quantity": 1, "unit": "t", "harvestDate": "2025-09-01" }`))
+		AssertStatusCode(t, w, http.StatusBadRequest)
+		AssertProblemField(t, w, "harvestDate")
+	})
+
+	t.Run("Rejects yields of planned crops", func(t *testing.T) {
+		plannedId := createCrop(t, farmId, `{ "type": "CORN" }`)
+		w := driver.PerformRequest("POST", fmt.Sprintf("/farms/%v/crops/%v/yields", farmId, plannedId), strings.NewReader(`{ "quantity": 1, "unit": "t" }`))
+		AssertStatusCode(t, w, http.StatusConflict)
+		AssertProblemCode(t, w, "CROP_NOT_PLANTED")
+	})
+}
+
+func YieldSummary(t *testing.T) {
+	farmId, cropId := createHarvestedCrop(t, `{ "type": "SOYBEANS", "plantedArea": 40 }`)
+	path := fmt.Sprintf("/farms/%v/crops/%v/yields", farmId, cropId)
+	createYield(t, path, `{ "quantity": 1200, "unit": "sc", "moisture": 13 }`)
+	createYield(t, path, `{ "quantity": 72, "unit": "t", "moisture": 15 }`)
+
+	var summary YieldSummaryResponse
+	w := driver.PerformRequest("GET", path+"/summary?unit=sacks", nil)
+	AssertStatusCode(t, w, http.StatusOK)
+	ParseResponse(t, w.Body.Bytes(), &summary)
+
+	AssertEqual(t, summary.Unit, "sc", "Summary unit")
+	AssertEqual(t, summary.Yields, 2, "Summary yields")
+	AssertEqual(t, summary.Quantity, 2400.0, "Summary quantity")
+	AssertEqual(t, summary.AreaBasis, "plantedArea", "Summary area basis")
+	if summary.YieldPerHectare == nil || *summary.YieldPerHectare != 60 {
+		t.Errorf("Expect 60 sacks per hectare, but got %v", summary.YieldPerHectare)
+	}
+	if summary.AverageMoisture == nil || *summary.AverageMoisture != 14 {
+		t.Errorf("Expect 14%% moisture, but got %v", summary.AverageMoisture)
+	}
+
+	w = driver.PerformRequest("GET", path+"/summary?unit=bushels", nil)
+	AssertStatusCode(t, w, http.StatusBadRequest)
+	AssertProblemField(t, w, "unit")
+}
+
+func YieldDeleteWithCrop(t *testing.T) {
+	farmId, cropId := createHarvestedCrop(t, `{ "type": "CORN" }`)
+	path := fmt.Sprintf("/farms/%v/crops/%v/yields", farmId, cropId)
+	createYield(t, path, `{ "quantity": 10, "unit": "t" }`)
+
+	var deleteResponse DeleteFarmResponse
+	w := driver.PerformRequest("DELETE", "/farms/"+farmId, nil)
+	AssertStatusCode(t, w, http.StatusOK)
+	ParseResponse(t, w.Body.Bytes(), &deleteResponse)
+	AssertEqual(t, deleteResponse.Deleted["yields"], int64(1), "Deleted yields")
+}